@@ -0,0 +1,37 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package api
+
+// GetCertificatesRequest represents the request to get certificates from the server
+// per the filter parameters. Times are RFC3339 timestamps or durations relative to now, e.g. -30d
+type GetCertificatesRequest struct {
+	ID         string    `skip:"true"`
+	AKI        string    `skip:"true"`
+	Serial     string    `skip:"true"`
+	Revoked    TimeRange `skip:"true"`
+	Expired    TimeRange `skip:"true"`
+	NotExpired bool      `help:"Don't return expired certificates"`
+	NotRevoked bool      `help:"Don't return revoked certificates"`
+	CAName     string    `skip:"true"`
+}
+
+// TimeRange is a range of time with a start and end time
+type TimeRange struct {
+	StartTime string
+	EndTime   string
+}
+
+// GetCertificatesResponse is the response to a get certificates request
+type GetCertificatesResponse struct {
+	CAName string
+	// Certs are the PEM-encoded certificates
+	Certs [][]byte
+}
@@ -123,6 +123,9 @@ func (c *Client) initHTTPClient() error {
 		}
 		tr.TLSClientConfig = tlsConfig
 	}
+	if c.Config.Proxy != nil {
+		tr.Proxy = http.ProxyURL(c.Config.Proxy)
+	}
 	c.httpClient = &http.Client{Transport: tr}
 	return nil
 }
@@ -150,6 +153,33 @@ func (c *Client) net2LocalServerInfo(net *serverInfoResponseNet, local *GetServe
 	return nil
 }
 
+// GetCAInfo returns generic CA information
+func (c *Client) GetCAInfo(req *api.GetCAInfoRequest) (*GetServerInfoResponse, error) {
+	err := c.Init()
+	if err != nil {
+		return nil, err
+	}
+	body, err := util.Marshal(req, "GetCAInfo")
+	if err != nil {
+		return nil, err
+	}
+	cainforeq, err := c.newPost("cainfo", body)
+	if err != nil {
+		return nil, err
+	}
+	netSI := &serverInfoResponseNet{}
+	err = c.SendReq(cainforeq, netSI)
+	if err != nil {
+		return nil, err
+	}
+	localSI := &GetServerInfoResponse{}
+	err = c.net2LocalServerInfo(netSI, localSI)
+	if err != nil {
+		return nil, err
+	}
+	return localSI, nil
+}
+
 // EnrollmentResponse is the response from Client.Enroll and Identity.Reenroll
 type EnrollmentResponse struct {
 	Identity   *Identity
@@ -305,6 +335,45 @@ func (c *Client) newPost(endpoint string, reqBody []byte) (*http.Request, error)
 	return req, nil
 }
 
+// newGet create a new GET request
+func (c *Client) newGet(endpoint string) (*http.Request, error) {
+	curl, err := c.getURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", curl, bytes.NewReader([]byte{}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating GET request for %s", curl)
+	}
+	return req, nil
+}
+
+// newPut create a new PUT request
+func (c *Client) newPut(endpoint string, reqBody []byte) (*http.Request, error) {
+	curl, err := c.getURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("PUT", curl, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating PUT request for %s", curl)
+	}
+	return req, nil
+}
+
+// newDelete create a new DELETE request
+func (c *Client) newDelete(endpoint string) (*http.Request, error) {
+	curl, err := c.getURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("DELETE", curl, bytes.NewReader([]byte{}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating DELETE request for %s", curl)
+	}
+	return req, nil
+}
+
 // SendReq sends a request to the fabric-ca-server and fills in the result
 func (c *Client) SendReq(req *http.Request, result interface{}) (err error) {
 
@@ -21,6 +21,8 @@ Please review third_party pinning scripts and patches for more details.
 package lib
 
 import (
+	"net/url"
+
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/tls"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
@@ -38,4 +40,6 @@ type ClientConfig struct {
 	CAInfo     api.GetCAInfoRequest
 	CAName     string           `help:"Name of CA"`
 	CSP        core.CryptoSuite `mapstructure:"bccsp"`
+	// Proxy is the URL of the proxy of the connections to the server, nil for direct connections
+	Proxy *url.URL
 }
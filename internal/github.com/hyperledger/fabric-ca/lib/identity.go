@@ -21,7 +21,10 @@ Please review third_party pinning scripts and patches for more details.
 package lib
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/pkg/errors"
 
@@ -141,6 +144,286 @@ func (i *Identity) Revoke(req *api.RevocationRequest) (*api.RevocationResponse,
 	return &api.RevocationResponse{RevokedCerts: result.RevokedCerts, CRL: crl}, nil
 }
 
+// GenCRL generates CRL
+func (i *Identity) GenCRL(req *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	log.Debugf("Entering identity.GenCRL %+v", req)
+	reqBody, err := util.Marshal(req, "GenCRLRequest")
+	if err != nil {
+		return nil, err
+	}
+	var result genCRLResponseNet
+	err = i.Post("gencrl", reqBody, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Successfully generated CRL: %+v", req)
+	crl, err := util.B64Decode(result.CRL)
+	if err != nil {
+		return nil, err
+	}
+	return &api.GenCRLResponse{CRL: crl}, nil
+}
+
+// GetIdentity returns information about the requested identity
+func (i *Identity) GetIdentity(id, caname string) (*api.GetIDResponse, error) {
+	log.Debugf("Entering identity.GetIdentity %s", id)
+	if id == "" {
+		return nil, errors.New("Name of the identity to be retrieved is required")
+	}
+	result := &api.GetIDResponse{}
+	err := i.Get(fmt.Sprintf("identities/%s", url.PathEscape(id)), caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved identity: %+v", result)
+	return result, nil
+}
+
+// GetAllIdentities returns all identities that the caller is authorized to see
+func (i *Identity) GetAllIdentities(caname string) (*api.GetAllIDsResponse, error) {
+	log.Debugf("Entering identity.GetAllIdentities")
+	result := &api.GetAllIDsResponse{}
+	err := i.Get("identities", caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved %d identities", len(result.Identities))
+	return result, nil
+}
+
+// AddIdentity adds a new identity to the server
+func (i *Identity) AddIdentity(req *api.AddIdentityRequest) (*api.IdentityResponse, error) {
+	log.Debugf("Entering identity.AddIdentity with request: %+v", req)
+	if req.ID == "" {
+		return nil, errors.New("Adding identity with no 'ID' set")
+	}
+
+	reqBody, err := util.Marshal(&api.AddIdentityRequestNet{AddIdentityRequest: *req}, "addIdentity")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send a post to the "identities" endpoint with req as body
+	result := &api.IdentityResponse{}
+	err = i.Post("identities", reqBody, result, map[string]string{"ca": req.CAName})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully added new identity '%s'", result.ID)
+	return result, nil
+}
+
+// ModifyIdentity modifies an existing identity on the server
+func (i *Identity) ModifyIdentity(req *api.ModifyIdentityRequest) (*api.IdentityResponse, error) {
+	log.Debugf("Entering identity.ModifyIdentity with request: %+v", req)
+	if req.ID == "" {
+		return nil, errors.New("Name of the identity to be modified is required")
+	}
+
+	reqBody, err := util.Marshal(&api.ModifyIdentityRequestNet{ModifyIdentityRequest: *req}, "modifyIdentity")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send a put to the "identities" endpoint with req as body
+	result := &api.IdentityResponse{}
+	err = i.Put(fmt.Sprintf("identities/%s", url.PathEscape(req.ID)), reqBody, map[string]string{"ca": req.CAName}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully modified identity '%s'", result.ID)
+	return result, nil
+}
+
+// RemoveIdentity removes a new identity from the server
+func (i *Identity) RemoveIdentity(req *api.RemoveIdentityRequest) (*api.IdentityResponse, error) {
+	log.Debugf("Entering identity.RemoveIdentity with request: %+v", req)
+	if req.ID == "" {
+		return nil, errors.New("Name of the identity to be removed is required")
+	}
+
+	queryParam := map[string]string{
+		"force": strconv.FormatBool(req.Force),
+		"ca":    req.CAName,
+	}
+
+	// Send a delete to the "identities" endpoint id as a part of the URL
+	result := &api.IdentityResponse{}
+	err := i.Delete(fmt.Sprintf("identities/%s", url.PathEscape(req.ID)), result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully removed identity '%s'", req.ID)
+	return result, nil
+}
+
+// GetAffiliation returns information about the requested affiliation
+func (i *Identity) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.GetAffiliation %+v", affiliation)
+	if affiliation == "" {
+		return nil, errors.New("Name of the affiliation to be retrieved is required")
+	}
+	result := &api.AffiliationResponse{}
+	err := i.Get(fmt.Sprintf("affiliations/%s", url.PathEscape(affiliation)), caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved affiliation: %+v", result)
+	return result, nil
+}
+
+// GetAllAffiliations returns all affiliations that the caller is authorized to see
+func (i *Identity) GetAllAffiliations(caname string) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.GetAllAffiliations")
+	result := &api.AffiliationResponse{}
+	err := i.Get("affiliations", caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("Successfully retrieved affiliations")
+	return result, nil
+}
+
+// AddAffiliation adds a new affiliation to the server
+func (i *Identity) AddAffiliation(req *api.AddAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.AddAffiliation with request: %+v", req)
+	if req.Name == "" {
+		return nil, errors.New("Affiliation to add was not specified")
+	}
+
+	reqBody, err := util.Marshal(&api.AddAffiliationRequestNet{AddAffiliationRequest: *req}, "addAffiliation")
+	if err != nil {
+		return nil, err
+	}
+
+	queryParam := map[string]string{
+		"force": strconv.FormatBool(req.Force),
+		"ca":    req.CAName,
+	}
+
+	// Send a post to the "affiliations" endpoint with req as body
+	result := &api.AffiliationResponse{}
+	err = i.Post("affiliations", reqBody, result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully added new affiliation")
+	return result, nil
+}
+
+// ModifyAffiliation renames an existing affiliation on the server
+func (i *Identity) ModifyAffiliation(req *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.ModifyAffiliation with request: %+v", req)
+	modifyAff := req.NewName
+	if modifyAff == "" {
+		return nil, errors.New("New affiliation not specified")
+	}
+
+	reqBody, err := util.Marshal(&api.ModifyAffiliationRequestNet{ModifyAffiliationRequest: *req}, "modifyAffiliation")
+	if err != nil {
+		return nil, err
+	}
+
+	queryParam := map[string]string{
+		"force": strconv.FormatBool(req.Force),
+		"ca":    req.CAName,
+	}
+
+	// Send a put to the "affiliations" endpoint with req as body
+	result := &api.AffiliationResponse{}
+	err = i.Put(fmt.Sprintf("affiliations/%s", url.PathEscape(req.Name)), reqBody, queryParam, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully modified affiliation")
+	return result, nil
+}
+
+// RemoveAffiliation removes an existing affiliation from the server
+func (i *Identity) RemoveAffiliation(req *api.RemoveAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.RemoveAffiliation with request: %+v", req)
+	removeAff := req.Name
+	if removeAff == "" {
+		return nil, errors.New("Affiliation to remove was not specified")
+	}
+
+	queryParam := map[string]string{
+		"force": strconv.FormatBool(req.Force),
+		"ca":    req.CAName,
+	}
+
+	// Send a delete to the "affiliations" endpoint with the affiliation as a part of the URL
+	result := &api.AffiliationResponse{}
+	err := i.Delete(fmt.Sprintf("affiliations/%s", url.PathEscape(removeAff)), result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully removed affiliation")
+	return result, nil
+}
+
+// Get sends a get request to an endpoint
+func (i *Identity) Get(endpoint, caname string, result interface{}) error {
+	req, err := i.client.newGet(endpoint)
+	if err != nil {
+		return err
+	}
+	if caname != "" {
+		addQueryParm(req, "ca", caname)
+	}
+	err = i.addTokenAuthHdr(req, nil)
+	if err != nil {
+		return err
+	}
+	return i.client.SendReq(req, result)
+}
+
+// Put sends a put request to an endpoint
+func (i *Identity) Put(endpoint string, reqBody []byte, queryParam map[string]string, result interface{}) error {
+	req, err := i.client.newPut(endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if queryParam != nil {
+		for key, value := range queryParam {
+			addQueryParm(req, key, value)
+		}
+	}
+	err = i.addTokenAuthHdr(req, reqBody)
+	if err != nil {
+		return err
+	}
+	return i.client.SendReq(req, result)
+}
+
+// Delete sends a delete request to an endpoint
+func (i *Identity) Delete(endpoint string, result interface{}, queryParam map[string]string) error {
+	req, err := i.client.newDelete(endpoint)
+	if err != nil {
+		return err
+	}
+	if queryParam != nil {
+		for key, value := range queryParam {
+			addQueryParm(req, key, value)
+		}
+	}
+	err = i.addTokenAuthHdr(req, nil)
+	if err != nil {
+		return err
+	}
+	return i.client.SendReq(req, result)
+}
+
 // Post sends arbitrary request body (reqBody) to an endpoint.
 // This adds an authorization header which contains the signature
 // of this identity over the body and non-signature part of the authorization header.
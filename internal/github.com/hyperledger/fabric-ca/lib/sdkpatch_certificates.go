@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
+	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
+)
+
+type genCRLResponseNet struct {
+	// Base64 encoding of PEM-encoded CRL
+	CRL string
+}
+
+type certificatesResponseNet struct {
+	// CAName is the name of the CA that issued the certificates
+	CAName string
+	// Certs are the PEM-encoded certificates
+	Certs []certPEMNet
+}
+
+type certPEMNet struct {
+	PEM string
+}
+
+// GetCertificates returns the certificates that match the request and that the caller is authorized to see
+func (i *Identity) GetCertificates(req *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	log.Debugf("Entering identity.GetCertificates %+v", req)
+	queryParam := map[string]string{
+		"id":            req.ID,
+		"serial":        req.Serial,
+		"aki":           req.AKI,
+		"revoked_start": req.Revoked.StartTime,
+		"revoked_end":   req.Revoked.EndTime,
+		"expired_start": req.Expired.StartTime,
+		"expired_end":   req.Expired.EndTime,
+		"ca":            req.CAName,
+	}
+	if req.NotRevoked {
+		queryParam["notrevoked"] = "true"
+	}
+	if req.NotExpired {
+		queryParam["notexpired"] = "true"
+	}
+	getReq, err := i.client.newGet("certificates")
+	if err != nil {
+		return nil, err
+	}
+	addQueryParms(getReq, queryParam)
+	err = i.addTokenAuthHdr(getReq, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result certificatesResponseNet
+	err = i.client.SendReq(getReq, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.GetCertificatesResponse{CAName: result.CAName}
+	for _, cert := range result.Certs {
+		resp.Certs = append(resp.Certs, []byte(cert.PEM))
+	}
+	log.Debugf("Successfully retrieved %d certificates", len(resp.Certs))
+	return resp, nil
+}
+
+// addQueryParms adds the non-empty query parameters to the request
+func addQueryParms(req *http.Request, queryParam map[string]string) {
+	for key, value := range queryParam {
+		if value != "" {
+			addQueryParm(req, key, value)
+		}
+	}
+}
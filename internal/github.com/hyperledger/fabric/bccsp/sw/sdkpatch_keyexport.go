@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package sw
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+// ExportKey returns the key material of a software key, so that key stores outside of this package
+// can persist it: *ecdsa.PrivateKey, *ecdsa.PublicKey, *rsa.PrivateKey, *rsa.PublicKey or,
+// for AES keys, []byte
+func ExportKey(k bccsp.Key) (interface{}, error) {
+	switch kk := k.(type) {
+	case *ecdsaPrivateKey:
+		return kk.privKey, nil
+	case *ecdsaPublicKey:
+		return kk.pubKey, nil
+	case *rsaPrivateKey:
+		return kk.privKey, nil
+	case *rsaPublicKey:
+		return kk.pubKey, nil
+	case *aesPrivateKey:
+		return kk.privKey, nil
+	default:
+		return nil, fmt.Errorf("Key type not recognized [%T]", k)
+	}
+}
+
+// ImportKey returns the software key for key material returned by ExportKey
+func ImportKey(raw interface{}) (bccsp.Key, error) {
+	switch kk := raw.(type) {
+	case *ecdsa.PrivateKey:
+		return &ecdsaPrivateKey{kk}, nil
+	case *ecdsa.PublicKey:
+		return &ecdsaPublicKey{kk}, nil
+	case *rsa.PrivateKey:
+		return &rsaPrivateKey{kk}, nil
+	case *rsa.PublicKey:
+		return &rsaPublicKey{kk}, nil
+	case []byte:
+		return &aesPrivateKey{kk, false}, nil
+	default:
+		return nil, fmt.Errorf("Key material type not recognized [%T]", raw)
+	}
+}
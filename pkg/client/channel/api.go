@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -19,9 +20,12 @@ import (
 
 // opts allows the user to specify more advanced options
 type requestOptions struct {
-	Targets []fab.Peer // targets
-	Timeout time.Duration
-	Retry   retry.Opts
+	Targets   []fab.Peer // targets
+	Timeout   time.Duration
+	Retry     retry.Opts
+	Identity  msp.Identity          // overrides the client's signing identity
+	Nonce     []byte                // caller-supplied nonce used to compute the transaction ID
+	TxnHeader fab.TransactionHeader // caller-supplied transaction header
 }
 
 // RequestOption func for each Opts argument
@@ -44,6 +48,37 @@ type Response struct {
 	Responses        []*fab.TransactionProposalResponse
 }
 
+// TxStatus is the outcome of a previously submitted transaction as determined by ReconcileTransaction
+type TxStatus int
+
+const (
+	// TxStatusUnknown indicates that the transaction was found neither in the ledger nor by the event service
+	TxStatusUnknown TxStatus = iota
+	// TxStatusCommitted indicates that the transaction was committed and is valid
+	TxStatusCommitted
+	// TxStatusInvalid indicates that the transaction was committed but was marked invalid
+	TxStatusInvalid
+)
+
+// String returns the name of the status
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusCommitted:
+		return "COMMITTED"
+	case TxStatusInvalid:
+		return "INVALID"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// TxStatusResponse contains the outcome of a transaction reconciliation
+type TxStatusResponse struct {
+	TransactionID    fab.TransactionID
+	Status           TxStatus
+	TxValidationCode pb.TxValidationCode
+}
+
 //WithTimeout encapsulates time.Duration to Option
 func WithTimeout(timeout time.Duration) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -94,3 +129,44 @@ func WithRetry(retryOpt retry.Opts) RequestOption {
 		return nil
 	}
 }
+
+// WithIdentity signs the proposal and transaction of this request with the given
+// identity instead of the identity the client was created with. Connections,
+// discovery and event services of the client are shared across identities.
+func WithIdentity(identity msp.Identity) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if identity == nil {
+			return errors.New("identity is required")
+		}
+		o.Identity = identity
+		return nil
+	}
+}
+
+// WithNonce supplies the nonce from which the transaction ID is computed, instead of
+// a randomly generated one. Together with the signing identity, the nonce determines
+// the transaction ID, so a caller that persists the nonce before submitting can later
+// find out whether the transaction was committed (see ReconcileTransaction).
+// The nonce must be unique for each transaction.
+func WithNonce(nonce []byte) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if len(nonce) == 0 {
+			return errors.New("nonce is required")
+		}
+		o.Nonce = nonce
+		return nil
+	}
+}
+
+// WithTransactionHeader supplies a transaction header built in advance (see
+// Client.CreateTransactionHeader), so that the transaction ID is known before
+// the request is sent. The request fails if the header's creator is not the signing identity.
+func WithTransactionHeader(txh fab.TransactionHeader) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if txh == nil {
+			return errors.New("transaction header is required")
+		}
+		o.TxnHeader = txh
+		return nil
+	}
+}
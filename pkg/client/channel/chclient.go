@@ -8,20 +8,28 @@ SPDX-License-Identifier: Apache-2.0
 package channel
 
 import (
+	"bytes"
 	reqContext "context"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/journal"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery"
-	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/greylist"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
+	channelImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/pkg/errors"
 )
 
@@ -29,6 +37,9 @@ var logger = logging.NewLogger("fabsdk/fab")
 
 const (
 	defaultHandlerTimeout = time.Second * 180
+
+	// txNotFoundMsg is the message of the peers' response to the query of a transaction not in the ledger
+	txNotFoundMsg = "no such transaction ID"
 )
 
 // Client enables access to a channel on a Fabric network.
@@ -40,8 +51,10 @@ type Client struct {
 	context         context.Channel
 	membership      fab.ChannelMembership
 	eventService    fab.EventService
-	greylist        *greylist.Filter
+	circuitBreaker  *circuitbreaker.Breaker
 	discoveryFilter fab.TargetFilter
+	journal         *journal.Journal
+	recoveryHandler RecoveryHandler
 }
 
 type customChannelContext struct {
@@ -64,6 +77,34 @@ func WithTargetFilter(filter fab.TargetFilter) ClientOption {
 	}
 }
 
+// WithJournal option to record the transactions submitted by Execute in the given journal,
+// so that they can be recovered with RecoverJournal after a restart
+func WithJournal(j *journal.Journal) ClientOption {
+	return func(client *Client) error {
+		client.journal = j
+		return nil
+	}
+}
+
+// WithJournalRecovery option to recover the pending transactions of the journal configured with WithJournal
+// in the background when the client is created, reporting their outcome to the handler (see RecoverJournal).
+// Without this option, the pending transactions are recovered only if RecoverJournal is called.
+func WithJournalRecovery(handler RecoveryHandler) ClientOption {
+	return func(client *Client) error {
+		client.recoveryHandler = handler
+		return nil
+	}
+}
+
+// WithCircuitBreaker option to use the given circuit breaker to stop sending proposals to peers
+// that keep failing. By default, each client has a circuit breaker configured by the channel policies
+func WithCircuitBreaker(breaker *circuitbreaker.Breaker) ClientOption {
+	return func(client *Client) error {
+		client.circuitBreaker = breaker
+		return nil
+	}
+}
+
 // New returns a Client instance.
 func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client, error) {
 
@@ -72,8 +113,6 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 		return nil, errors.WithMessage(err, "failed to create channel context")
 	}
 
-	greylistProvider := greylist.New(channelContext.Config().TimeoutOrDefault(core.DiscoveryGreylistExpiry))
-
 	if channelContext.ChannelService() == nil {
 		return nil, errors.New("channel service not initialized")
 	}
@@ -91,44 +130,67 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 	channelClient := Client{
 		membership:   membership,
 		eventService: eventService,
-		greylist:     greylistProvider,
 	}
 
 	for _, param := range opts {
 		param(&channelClient)
 	}
 
+	if channelClient.circuitBreaker == nil {
+		policies, err := channelImpl.ChannelPolicies(channelContext.Config(), channelContext.ChannelID())
+		if err != nil {
+			return nil, err
+		}
+		channelClient.circuitBreaker = circuitbreaker.NewFromPolicy(policies.CircuitBreaker, channelContext.Config().TimeoutOrDefault(core.DiscoveryGreylistExpiry))
+	}
+
 	//target filter
 	discoveryService := discovery.NewDiscoveryFilterService(channelContext.DiscoveryService(), channelClient.discoveryFilter)
 
-	//greylist filter
-	customDiscoveryService := discovery.NewDiscoveryFilterService(discoveryService, greylistProvider)
+	//circuit breaker filter
+	customDiscoveryService := discovery.NewDiscoveryFilterService(discoveryService, channelClient.circuitBreaker)
 
 	//update context
 	channelClient.context = &customChannelContext{Channel: channelContext, discoveryService: customDiscoveryService}
 
+	if channelClient.recoveryHandler != nil {
+		if channelClient.journal == nil {
+			return nil, errors.New("journal is required for journal recovery")
+		}
+		go func() {
+			if err := channelClient.RecoverJournal(channelClient.recoveryHandler); err != nil {
+				logger.Warnf("recovering journal failed: %s", err)
+			}
+		}()
+	}
+
 	return &channelClient, nil
 }
 
 // Query chaincode using request and optional options provided
 func (cc *Client) Query(request Request, options ...RequestOption) (Response, error) {
-	return cc.InvokeHandler(invoke.NewQueryHandler(), request, cc.addDefaultTimeout(cc.context, core.Query, options...)...)
+	return cc.invokeHandler("channel.Query", invoke.NewQueryHandler(), request, cc.addDefaultTimeout(cc.context, core.Query, options...)...)
 }
 
 // Execute prepares and executes transaction using request and optional options provided
 func (cc *Client) Execute(request Request, options ...RequestOption) (Response, error) {
-	return cc.InvokeHandler(invoke.NewExecuteHandler(), request, cc.addDefaultTimeout(cc.context, core.Execute, options...)...)
+	return cc.invokeHandler("channel.Execute", invoke.NewExecuteHandler(), request, cc.addDefaultTimeout(cc.context, core.Execute, options...)...)
 }
 
 //InvokeHandler invokes handler using request and options provided
 func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options ...RequestOption) (Response, error) {
+	return cc.invokeHandler("channel.InvokeHandler", handler, request, options...)
+}
+
+//invokeHandler invokes handler using request and options provided, identifying the operation of the GRPC calls
+func (cc *Client) invokeHandler(operation string, handler invoke.Handler, request Request, options ...RequestOption) (Response, error) {
 	//Read execute tx options
 	txnOpts, err := cc.prepareOptsFromOptions(cc.context, options...)
 	if err != nil {
 		return Response{}, err
 	}
 
-	reqCtx, cancel := cc.createReqContext(&txnOpts)
+	reqCtx, cancel := cc.createReqContext(&txnOpts, operation)
 	defer cancel()
 
 	//Prepare context objects for handler
@@ -165,7 +227,6 @@ func (cc *Client) resolveRetry(ctx *invoke.RequestContext, o requestOptions) boo
 	for _, e := range errs {
 		if ctx.RetryHandler.Required(e) {
 			logger.Infof("Retrying on error %s", e)
-			cc.greylist.Greylist(e)
 
 			// Reset context parameters
 			ctx.Opts.Targets = o.Targets
@@ -178,8 +239,8 @@ func (cc *Client) resolveRetry(ctx *invoke.RequestContext, o requestOptions) boo
 	return false
 }
 
-//createReqContext creates req context for invoke handler
-func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context, reqContext.CancelFunc) {
+//createReqContext creates req context for invoke handler, identifying the operation of the GRPC calls
+func (cc *Client) createReqContext(txnOpts *requestOptions, operation string) (reqContext.Context, reqContext.CancelFunc) {
 
 	//Setting default timeouts when not provided
 	if txnOpts.Timeout == 0 {
@@ -190,7 +251,15 @@ func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context,
 		}
 	}
 
-	return contextImpl.NewRequest(cc.context, contextImpl.WithTimeout(txnOpts.Timeout))
+	return contextImpl.NewRequest(cc.clientContext(txnOpts), contextImpl.WithTimeout(txnOpts.Timeout), contextImpl.WithOperation(operation))
+}
+
+//clientContext returns the client context for the request, using the overriding identity if one was provided
+func (cc *Client) clientContext(txnOpts *requestOptions) context.Client {
+	if txnOpts.Identity == nil {
+		return cc.context
+	}
+	return &contextImpl.Client{Providers: cc.context, Identity: txnOpts.Identity}
 }
 
 //prepareHandlerContexts prepares context objects for handlers
@@ -200,6 +269,12 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 		return nil, nil, errors.New("ChaincodeID and Fcn are required")
 	}
 
+	if o.TxnHeader != nil {
+		if err := checkHeaderCreator(o.TxnHeader, cc.clientContext(&o)); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	chConfig := cc.context.ChannelService().ChannelConfig()
 	transactor, err := cc.context.InfraProvider().CreateChannelTransactor(reqCtx, chConfig)
 	if err != nil {
@@ -207,11 +282,13 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 	}
 
 	clientContext := &invoke.ClientContext{
-		Selection:    cc.context.SelectionService(),
-		Discovery:    cc.context.DiscoveryService(),
-		Membership:   cc.membership,
-		Transactor:   transactor,
-		EventService: cc.eventService,
+		Selection:      cc.context.SelectionService(),
+		Discovery:      cc.context.DiscoveryService(),
+		Membership:     cc.membership,
+		Transactor:     transactor,
+		EventService:   cc.eventService,
+		Journal:        cc.journal,
+		CircuitBreaker: cc.circuitBreaker,
 	}
 
 	requestContext := &invoke.RequestContext{
@@ -224,6 +301,19 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 	return requestContext, clientContext, nil
 }
 
+//checkHeaderCreator returns an error if the creator of the caller-supplied transaction header
+//is not the identity signing the request, which the peers would reject
+func checkHeaderCreator(txh fab.TransactionHeader, identity msp.Identity) error {
+	creator, err := identity.SerializedIdentity()
+	if err != nil {
+		return errors.WithMessage(err, "serializing signing identity failed")
+	}
+	if !bytes.Equal(txh.Creator(), creator) {
+		return errors.Errorf("creator of transaction header [%s] is not the signing identity", txh.TransactionID())
+	}
+	return nil
+}
+
 //prepareOptsFromOptions Reads apitxn.Opts from Option array
 func (cc *Client) prepareOptsFromOptions(ctx context.Client, options ...RequestOption) (requestOptions, error) {
 	txnOpts := requestOptions{}
@@ -249,6 +339,120 @@ func (cc *Client) addDefaultTimeout(ctx context.Client, timeOutType core.Timeout
 	return options
 }
 
+// CreateTransactionHeader creates a transaction header for this channel without sending anything.
+// The transaction ID of the header may be persisted before the header is passed to Execute
+// using WithTransactionHeader. Valid options are WithIdentity and WithNonce.
+func (cc *Client) CreateTransactionHeader(options ...RequestOption) (fab.TransactionHeader, error) {
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	txh, err := txn.NewHeader(cc.clientContext(&txnOpts), cc.context.ChannelID(), txn.WithNonce(txnOpts.Nonce))
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating transaction header failed")
+	}
+	return txh, nil
+}
+
+// ReconcileTransaction determines the outcome of a previously submitted transaction.
+// The ledger of the target peers is queried for the transaction and, if it is not found,
+// the event service is watched for the transaction until the request times out.
+// A transaction that is found in neither is reported as TxStatusUnknown and may be resubmitted
+// with the same transaction header.
+func (cc *Client) ReconcileTransaction(txnID fab.TransactionID, options ...RequestOption) (TxStatusResponse, error) {
+	if txnID == fab.EmptyTransactionID {
+		return TxStatusResponse{}, errors.New("transaction ID is required")
+	}
+
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, cc.addDefaultTimeout(cc.context, core.Query, options...)...)
+	if err != nil {
+		return TxStatusResponse{}, err
+	}
+
+	reqCtx, cancel := cc.createReqContext(&txnOpts, "channel.ReconcileTransaction")
+	defer cancel()
+
+	// Register for the TxStatus event before querying the ledger so that
+	// a commit in between is not missed
+	reg, statusNotifier, err := cc.eventService.RegisterTxStatusEvent(string(txnID))
+	if err != nil {
+		return TxStatusResponse{}, errors.WithMessage(err, "error registering for TxStatus event")
+	}
+	defer cc.eventService.Unregister(reg)
+
+	code, found, err := cc.queryTxValidationCode(reqCtx, txnID, txnOpts)
+	if err != nil {
+		return TxStatusResponse{}, err
+	}
+	if found {
+		return newTxStatusResponse(txnID, code), nil
+	}
+
+	select {
+	case txStatus := <-statusNotifier:
+		return newTxStatusResponse(txnID, txStatus.TxValidationCode), nil
+	case <-reqCtx.Done():
+		return TxStatusResponse{TransactionID: txnID, Status: TxStatusUnknown}, nil
+	}
+}
+
+// queryTxValidationCode queries the ledger of the target peers for the validation code of a transaction
+func (cc *Client) queryTxValidationCode(reqCtx reqContext.Context, txnID fab.TransactionID, txnOpts requestOptions) (pb.TxValidationCode, bool, error) {
+	targets := txnOpts.Targets
+	if len(targets) == 0 {
+		var err error
+		targets, err = cc.context.DiscoveryService().GetPeers()
+		if err != nil {
+			return 0, false, errors.WithMessage(err, "GetPeers failed")
+		}
+	}
+
+	l, err := channelImpl.NewLedger(cc.context.ChannelID())
+	if err != nil {
+		return 0, false, errors.WithMessage(err, "ledger client creation failed")
+	}
+
+	processedTxns, err := l.QueryTransaction(reqCtx, txnID, peer.PeersToTxnProcessors(targets))
+	if len(processedTxns) > 0 {
+		return pb.TxValidationCode(processedTxns[0].ValidationCode), true, nil
+	}
+	if err != nil && !isTxNotFound(err) {
+		return 0, false, errors.WithMessage(err, "QueryTransaction failed")
+	}
+
+	logger.Debugf("transaction [%s] not found in ledger", txnID)
+	return 0, false, nil
+}
+
+// isTxNotFound returns true if all the peers responded that the transaction is not in their ledger,
+// as opposed to failing to process the query
+func isTxNotFound(err error) bool {
+	errs, ok := errors.Cause(err).(multi.Errors)
+	if !ok {
+		errs = multi.Errors{err}
+	}
+	for _, e := range errs {
+		if !strings.Contains(e.Error(), txNotFoundMsg) {
+			return false
+		}
+	}
+	return len(errs) > 0
+}
+
+func newTxStatusResponse(txnID fab.TransactionID, code pb.TxValidationCode) TxStatusResponse {
+	status := TxStatusCommitted
+	if code != pb.TxValidationCode_VALID {
+		status = TxStatusInvalid
+	}
+	return TxStatusResponse{TransactionID: txnID, Status: status, TxValidationCode: code}
+}
+
+// EndpointStates returns the state of the circuits of the peers that have failed, for diagnostics
+func (cc *Client) EndpointStates() []circuitbreaker.EndpointState {
+	return cc.circuitBreaker.Endpoints()
+}
+
 // RegisterChaincodeEvent registers chain code event
 // @param {chan bool} channel which receives event details when the event is complete
 // @returns {object} object handle that should be used to unregister
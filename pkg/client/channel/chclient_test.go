@@ -11,14 +11,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
-	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
@@ -184,6 +185,34 @@ func TestQueryWithOptTarget(t *testing.T) {
 	}
 }
 
+func TestQueryWithIdentity(t *testing.T) {
+	chClient := setupChannelClient(nil, t)
+
+	identity := fcmocks.NewMockUserWithMSPID("override", "Org2MSP")
+
+	opts, err := chClient.prepareOptsFromOptions(chClient.context, WithIdentity(identity))
+	assert.Nil(t, err, "Got error %s", err)
+
+	reqCtx, cancel := chClient.createReqContext(&opts, "channel.Query")
+	defer cancel()
+
+	ctx, ok := contextImpl.RequestClientContext(reqCtx)
+	assert.True(t, ok, "expected client context in request context")
+	assert.Equal(t, "Org2MSP", ctx.MspID(), "expected overriding identity to be used")
+	assert.Equal(t, chClient.context.InfraProvider(), ctx.InfraProvider(), "expected providers to be shared")
+	operation, ok := contextImpl.RequestOperation(reqCtx)
+	assert.True(t, ok, "expected operation in request context")
+	assert.Equal(t, "channel.Query", operation)
+
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}, WithIdentity(identity))
+	if err != nil {
+		t.Fatalf("Failed to invoke test cc with identity: %s", err)
+	}
+
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}, WithIdentity(nil))
+	assert.NotNil(t, err, "expected error for nil identity")
+}
+
 func TestExecuteTx(t *testing.T) {
 	chClient := setupChannelClient(nil, t)
 
@@ -393,6 +422,99 @@ func TestTransactionValidationError(t *testing.T) {
 	assert.EqualValues(t, validationCode, status.ToTransactionValidationCode(statusError.Code))
 }
 
+func TestExecuteTxWithTransactionHeader(t *testing.T) {
+	mockEventService := fcmocks.NewMockEventService()
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+	chClient.eventService = mockEventService
+
+	nonce := []byte("0123456789abcdef0123456789abcdef")
+	txh, err := chClient.CreateTransactionHeader(WithNonce(nonce))
+	assert.Nil(t, err, "Got error %s", err)
+
+	txh2, err := chClient.CreateTransactionHeader(WithNonce(nonce))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, txh.TransactionID(), txh2.TransactionID(), "expected same transaction ID for same nonce")
+
+	registeredTxID := make(chan string, 1)
+	go func() {
+		select {
+		case txStatusReg := <-mockEventService.TxStatusRegCh:
+			registeredTxID <- txStatusReg.TxID
+			txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}
+		case <-time.After(time.Second * 5):
+			registeredTxID <- ""
+		}
+	}()
+
+	response, err := chClient.Execute(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}, WithTransactionHeader(txh))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, txh.TransactionID(), response.TransactionID, "expected supplied transaction header to be used")
+	assert.Equal(t, string(txh.TransactionID()), <-registeredTxID, "expected registration for supplied transaction ID")
+
+	_, err = chClient.Execute(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}, WithTransactionHeader(&otherCreatorHeader{txh}))
+	assert.NotNil(t, err, "expected error for transaction header created by another identity")
+	assert.Contains(t, err.Error(), "is not the signing identity")
+}
+
+// otherCreatorHeader is a transaction header created by another identity than the signing identity
+type otherCreatorHeader struct {
+	fab.TransactionHeader
+}
+
+func (h *otherCreatorHeader) Creator() []byte {
+	return []byte("other")
+}
+
+func TestReconcileTransaction(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	_, err := chClient.ReconcileTransaction(fab.EmptyTransactionID)
+	assert.NotNil(t, err, "expected error for empty transaction ID")
+
+	// Transaction found in ledger
+	processedTxn, err := proto.Marshal(&pb.ProcessedTransaction{ValidationCode: int32(pb.TxValidationCode_MVCC_READ_CONFLICT)})
+	assert.Nil(t, err, "Got error %s", err)
+	testPeer1.Payload = processedTxn
+	chClient.eventService = fcmocks.NewMockEventService()
+
+	resp, err := chClient.ReconcileTransaction("txid", WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, TxStatusInvalid, resp.Status, "expected invalid transaction")
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, resp.TxValidationCode, "expected validation code from ledger")
+
+	// Transaction not in ledger but reported by event service
+	testPeer1.Payload = nil
+	testPeer1.Error = status.New(status.EndorserServerStatus, int32(common.Status_INTERNAL_SERVER_ERROR),
+		"Failed to get transaction with id txid, error no such transaction ID [txid] in index", nil)
+	mockEventService := fcmocks.NewMockEventService()
+	chClient.eventService = mockEventService
+	go func() {
+		txStatusReg := <-mockEventService.TxStatusRegCh
+		txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}
+	}()
+
+	resp, err = chClient.ReconcileTransaction("txid", WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, TxStatusCommitted, resp.Status, "expected committed transaction")
+
+	// Transaction found in neither
+	chClient.eventService = fcmocks.NewMockEventService()
+	resp, err = chClient.ReconcileTransaction("txid", WithTargets(testPeer1), WithTimeout(100*time.Millisecond))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, TxStatusUnknown, resp.Status, "expected unknown transaction")
+	assert.Equal(t, fab.TransactionID("txid"), resp.TransactionID, "unexpected transaction ID")
+
+	// Query failure other than transaction not found
+	testPeer1.Error = status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil)
+	chClient.eventService = fcmocks.NewMockEventService()
+	_, err = chClient.ReconcileTransaction("txid", WithTargets(testPeer1), WithTimeout(100*time.Millisecond))
+	assert.Error(t, err, "expected error if the peers fail to process the query")
+}
+
 func TestExecuteTxWithRetries(t *testing.T) {
 	testStatus := status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "test", nil)
 	testResp := []byte("test")
@@ -443,7 +565,7 @@ func TestMultiErrorPropogation(t *testing.T) {
 	assert.Equal(t, "Multiple errors occurred: \nTest Error\nTest Error", statusError.Message, "Expected multi error message")
 }
 
-func TestDiscoveryGreylist(t *testing.T) {
+func TestDiscoveryCircuitBreaker(t *testing.T) {
 
 	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
 	testPeer1.Error = status.New(status.EndorserClientStatus,
@@ -459,12 +581,12 @@ func TestDiscoveryGreylist(t *testing.T) {
 	fabCtx := setupCustomTestContext(t, selectionService, discoveryService, nil)
 	ctx := createChannelContext(fabCtx, channelID)
 
-	chClient, err := New(ctx)
+	openInterval := 200 * time.Millisecond
+	chClient, err := New(ctx, WithCircuitBreaker(circuitbreaker.New(2, openInterval)))
 	assert.Nil(t, err, "Got error %s", err)
 
-	attempts := 3
 	retryOpts := retry.Opts{
-		Attempts:       attempts,
+		Attempts:       3,
 		BackoffFactor:  1,
 		InitialBackoff: time.Millisecond * 1,
 		MaxBackoff:     time.Second * 1,
@@ -475,21 +597,35 @@ func TestDiscoveryGreylist(t *testing.T) {
 	assert.NotNil(t, err, "expected error")
 	s, ok := status.FromError(err)
 	assert.True(t, ok, "expected status error")
-	assert.EqualValues(t, status.NoPeersFound.ToInt32(), s.Code, "expected No Peers Found status on greylist")
-	assert.Equal(t, 1, testPeer1.ProcessProposalCalls, "expected peer 1 to be greylisted")
-	// Wait for greylist expiry
-	time.Sleep(chClient.context.Config().TimeoutOrDefault(core.DiscoveryGreylistExpiry))
+	assert.EqualValues(t, status.NoPeersFound.ToInt32(), s.Code, "expected No Peers Found status on open circuit")
+	assert.Equal(t, 2, testPeer1.ProcessProposalCalls, "expected circuit of peer 1 to open after 2 failures")
+
+	states := chClient.EndpointStates()
+	if assert.Len(t, states, 1, "expected state of failed peer") {
+		assert.Equal(t, testPeer1.URL(), states[0].Address)
+		assert.Equal(t, circuitbreaker.Open, states[0].State)
+		assert.Equal(t, 2, states[0].Failures)
+	}
+
+	// Wait for the open interval; a single failed probe opens the circuit again
+	time.Sleep(openInterval)
 	testPeer1.ProcessProposalCalls = 0
 	testPeer1.Error = status.New(status.EndorserServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "test", nil)
-	// Try again
 	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}},
 		WithRetry(retryOpts))
 	assert.NotNil(t, err, "expected error")
 	s, ok = status.FromError(err)
 	assert.True(t, ok, "expected status error")
-	assert.EqualValues(t, int32(common.Status_SERVICE_UNAVAILABLE), s.Code, "expected configured mock error")
-	assert.Equal(t, attempts+1, testPeer1.ProcessProposalCalls, "expected peer 1 not to be greylisted")
+	assert.EqualValues(t, status.NoPeersFound.ToInt32(), s.Code, "expected No Peers Found status on open circuit")
+	assert.Equal(t, 1, testPeer1.ProcessProposalCalls, "expected a single probe of peer 1")
 
+	// A successful probe closes the circuit
+	time.Sleep(openInterval)
+	testPeer1.Error = nil
+	testPeer1.Payload = []byte("test")
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Empty(t, chClient.EndpointStates(), "expected circuit of peer 1 to be closed")
 }
 
 func setupTestChannelService(ctx context.Client, orderers []fab.Orderer) (fab.ChannelService, error) {
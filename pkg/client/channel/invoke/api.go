@@ -10,17 +10,23 @@ package invoke
 import (
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/journal"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
 // Opts allows the user to specify more advanced options
 type Opts struct {
-	Targets []fab.Peer // targets
-	Timeout time.Duration
-	Retry   retry.Opts
+	Targets   []fab.Peer // targets
+	Timeout   time.Duration
+	Retry     retry.Opts
+	Identity  msp.Identity          // overrides the client's signing identity
+	Nonce     []byte                // caller-supplied nonce used to compute the transaction ID
+	TxnHeader fab.TransactionHeader // caller-supplied transaction header
 }
 
 // Request contains the parameters to execute transaction
@@ -47,12 +53,14 @@ type Handler interface {
 
 //ClientContext contains context parameters for handler execution
 type ClientContext struct {
-	CryptoSuite  core.CryptoSuite
-	Discovery    fab.DiscoveryService
-	Selection    fab.SelectionService
-	Membership   fab.ChannelMembership
-	Transactor   fab.Transactor
-	EventService fab.EventService
+	CryptoSuite    core.CryptoSuite
+	Discovery      fab.DiscoveryService
+	Selection      fab.SelectionService
+	Membership     fab.ChannelMembership
+	Transactor     fab.Transactor
+	EventService   fab.EventService
+	Journal        *journal.Journal        // optional; records submitted transactions for recovery
+	CircuitBreaker *circuitbreaker.Breaker // optional; records the outcome of the proposals sent to each peer
 }
 
 //RequestContext contains request, opts, response parameters for handler execution
@@ -10,9 +10,12 @@ import (
 	"bytes"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
 	"github.com/pkg/errors"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/journal"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
@@ -37,7 +40,7 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	}
 
 	// Endorse Tx
-	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(clientContext.Transactor, &requestContext.Request, peer.PeersToTxnProcessors(requestContext.Opts.Targets))
+	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(clientContext.Transactor, &requestContext.Request, &requestContext.Opts, proposalProcessors(requestContext.Opts.Targets, clientContext.CircuitBreaker))
 
 	requestContext.Response.Proposal = proposal
 	requestContext.Response.TransactionID = proposal.TxnID // TODO: still needed?
@@ -149,7 +152,11 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	}
 	defer clientContext.EventService.Unregister(reg)
 
-	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	if clientContext.Journal != nil {
+		err = createAndSendJournaledTransaction(clientContext, requestContext.Response.Proposal, requestContext.Response.Responses)
+	} else {
+		_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	}
 	if err != nil {
 		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
 		return
@@ -158,6 +165,7 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	select {
 	case txStatus := <-statusNotifier:
 		requestContext.Response.TxValidationCode = txStatus.TxValidationCode
+		journalTxStatus(clientContext.Journal, txnID, txStatus.TxValidationCode)
 
 		if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
 			requestContext.Error = status.New(status.EventServerStatus, int32(txStatus.TxValidationCode), "received invalid transaction", nil)
@@ -244,7 +252,100 @@ func createAndSendTransaction(sender fab.Sender, proposal *fab.TransactionPropos
 	return transactionResponse, nil
 }
 
-func createAndSendTransactionProposal(transactor fab.Transactor, chrequest *Request, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, *fab.TransactionProposal, error) {
+//proposalProcessors returns the proposal processors of the targets, recording the outcome
+//of the proposals in the circuit breaker if there is one
+func proposalProcessors(targets []fab.Peer, breaker *circuitbreaker.Breaker) []fab.ProposalProcessor {
+	if breaker == nil {
+		return peer.PeersToTxnProcessors(targets)
+	}
+
+	processors := make([]fab.ProposalProcessor, len(targets))
+	for i, target := range targets {
+		processors[i] = breaker.ProposalProcessor(target.URL(), target)
+	}
+	return processors
+}
+
+//createAndSendJournaledTransaction persists the signed transaction envelope in the journal before it is broadcast,
+//so that the transaction can be recovered if the process stops before the outcome is known
+func createAndSendJournaledTransaction(clientContext *ClientContext, proposal *fab.TransactionProposal, resps []*fab.TransactionProposalResponse) error {
+	sender, ok := clientContext.Transactor.(fab.EnvelopeSender)
+	if !ok {
+		return errors.New("transactor does not support sending envelopes")
+	}
+
+	tx, err := clientContext.Transactor.CreateTransaction(fab.TransactionRequest{Proposal: proposal, ProposalResponses: resps})
+	if err != nil {
+		return errors.WithMessage(err, "CreateTransaction failed")
+	}
+
+	envelope, err := sender.CreateTransactionEnvelope(tx)
+	if err != nil {
+		return errors.WithMessage(err, "CreateTransactionEnvelope failed")
+	}
+
+	channelID, err := proposalChannelID(proposal)
+	if err != nil {
+		return err
+	}
+
+	entry := &journal.Entry{
+		TxnID:     proposal.TxnID,
+		ChannelID: channelID,
+		State:     journal.Endorsed,
+		Envelope:  envelope,
+	}
+	if err := clientContext.Journal.Put(entry); err != nil {
+		return errors.WithMessage(err, "journaling transaction failed")
+	}
+
+	if _, err := sender.SendEnvelope(envelope); err != nil {
+		// an orderer may have accepted the envelope before a timeout or connection failure,
+		// the entry then remains pending so that recovery checks the ledger
+		if broadcastRejected(err) {
+			if err := clientContext.Journal.UpdateState(proposal.TxnID, journal.Failed); err != nil {
+				logger.Warnf("journaling rejected broadcast of transaction [%s] failed: %s", proposal.TxnID, err)
+			}
+		}
+		return errors.WithMessage(err, "SendEnvelope failed")
+	}
+
+	if err := clientContext.Journal.UpdateState(proposal.TxnID, journal.Broadcast); err != nil {
+		logger.Warnf("journaling broadcast of transaction [%s] failed: %s", proposal.TxnID, err)
+	}
+	return nil
+}
+
+//broadcastRejected returns true if the orderer rejected the envelope, so that the transaction will not be ordered
+func broadcastRejected(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Group == status.OrdererServerStatus && s.Code != int32(common.Status_SERVICE_UNAVAILABLE)
+}
+
+//journalTxStatus records the final state of the transaction in the journal, if any
+func journalTxStatus(j *journal.Journal, txnID fab.TransactionID, code pb.TxValidationCode) {
+	if j == nil {
+		return
+	}
+	if err := j.Complete(txnID, code); err != nil {
+		logger.Warnf("journaling status of transaction [%s] failed: %s", txnID, err)
+	}
+}
+
+//proposalChannelID returns the ID of the channel in the header of the proposal
+func proposalChannelID(proposal *fab.TransactionProposal) (string, error) {
+	hdr := &common.Header{}
+	if err := proto.Unmarshal(proposal.Header, hdr); err != nil {
+		return "", errors.Wrap(err, "unmarshal of proposal header failed")
+	}
+	chdr := &common.ChannelHeader{}
+	if err := proto.Unmarshal(hdr.ChannelHeader, chdr); err != nil {
+		return "", errors.Wrap(err, "unmarshal of channel header failed")
+	}
+	return chdr.ChannelId, nil
+}
+
+func createAndSendTransactionProposal(transactor fab.Transactor, chrequest *Request, opts *Opts, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, *fab.TransactionProposal, error) {
 	request := fab.ChaincodeInvokeRequest{
 		ChaincodeID:  chrequest.ChaincodeID,
 		Fcn:          chrequest.Fcn,
@@ -252,7 +353,7 @@ func createAndSendTransactionProposal(transactor fab.Transactor, chrequest *Requ
 		TransientMap: chrequest.TransientMap,
 	}
 
-	txh, err := transactor.CreateTransactionHeader()
+	txh, err := transactionHeader(transactor, opts)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "creating transaction header failed")
 	}
@@ -265,3 +366,15 @@ func createAndSendTransactionProposal(transactor fab.Transactor, chrequest *Requ
 	transactionProposalResponses, err := transactor.SendTransactionProposal(proposal, targets)
 	return transactionProposalResponses, proposal, err
 }
+
+// transactionHeader returns the caller-supplied transaction header or creates one,
+// using the caller-supplied nonce if any
+func transactionHeader(transactor fab.Transactor, opts *Opts) (fab.TransactionHeader, error) {
+	if opts.TxnHeader != nil {
+		return opts.TxnHeader, nil
+	}
+	if opts.Nonce != nil {
+		return transactor.CreateTransactionHeader(txn.WithNonce(opts.Nonce))
+	}
+	return transactor.CreateTransactionHeader()
+}
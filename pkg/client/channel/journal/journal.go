@@ -0,0 +1,268 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package journal provides a durable record of the transactions submitted by the channel client,
+// so that transactions that were in flight when the process stopped can be recovered.
+package journal
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// pendingKey is the store key of the list of transactions that have not reached a final state
+const pendingKey = "pending"
+
+// State is the submission state of a journaled transaction
+type State string
+
+const (
+	// Endorsed indicates that the transaction was endorsed and signed but not yet acknowledged by an orderer
+	Endorsed State = "ENDORSED"
+	// Broadcast indicates that the transaction was acknowledged by an orderer
+	Broadcast State = "BROADCAST"
+	// Committed indicates that the transaction was committed and is valid
+	Committed State = "COMMITTED"
+	// Invalid indicates that the transaction was committed but was marked invalid
+	Invalid State = "INVALID"
+	// Failed indicates that the orderer rejected the transaction or that the transaction expired
+	// before it was found in the ledger; the transaction is not recovered
+	Failed State = "FAILED"
+)
+
+// Final returns true if no further state changes are expected
+func (s State) Final() bool {
+	return s == Committed || s == Invalid || s == Failed
+}
+
+// Entry is the journal record of a transaction
+type Entry struct {
+	TxnID            fab.TransactionID
+	ChannelID        string
+	State            State
+	Envelope         *fab.SignedEnvelope
+	TxValidationCode pb.TxValidationCode
+	Created          time.Time
+	Updated          time.Time
+}
+
+// Journal persists transaction entries in a key-value store. Each entry is stored under its
+// transaction ID; the IDs of the entries that are not in a final state are kept under a
+// separate key so that they can be enumerated on recovery.
+type Journal struct {
+	store  core.KVStore
+	expiry time.Duration
+	mutex  sync.Mutex
+}
+
+// Option is an option of the journal
+type Option func(*Journal)
+
+// WithExpiry sets the time after which pending transactions that are not found in the ledger
+// are no longer recovered. By default, pending transactions do not expire.
+func WithExpiry(expiry time.Duration) Option {
+	return func(j *Journal) {
+		j.expiry = expiry
+	}
+}
+
+// New returns a journal that persists its entries in the given store
+func New(store core.KVStore, opts ...Option) (*Journal, error) {
+	if store == nil {
+		return nil, errors.New("store is required")
+	}
+	j := &Journal{store: store}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j, nil
+}
+
+// Expired returns true if the entry was created longer ago than the expiry of the journal
+func (j *Journal) Expired(entry *Entry) bool {
+	return j.expiry > 0 && time.Since(entry.Created) > j.expiry
+}
+
+// Put stores the entry and updates the list of pending entries according to its state
+func (j *Journal) Put(entry *Entry) error {
+	if entry == nil || entry.TxnID == fab.EmptyTransactionID {
+		return errors.New("entry with transaction ID is required")
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.put(entry)
+}
+
+// Get returns the entry for the given transaction ID.
+// If the entry is not found, returns (nil, core.ErrKeyValueNotFound)
+func (j *Journal) Get(txnID fab.TransactionID) (*Entry, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.get(txnID)
+}
+
+// UpdateState changes the state of the entry for the given transaction ID
+func (j *Journal) UpdateState(txnID fab.TransactionID, state State) error {
+	return j.update(txnID, func(entry *Entry) {
+		entry.State = state
+	})
+}
+
+// Complete records the validation code of the committed transaction, moving the entry
+// to the Committed or Invalid state
+func (j *Journal) Complete(txnID fab.TransactionID, code pb.TxValidationCode) error {
+	return j.update(txnID, func(entry *Entry) {
+		entry.State = Committed
+		if code != pb.TxValidationCode_VALID {
+			entry.State = Invalid
+		}
+		entry.TxValidationCode = code
+	})
+}
+
+// Pending returns the entries that have not reached a final state
+func (j *Journal) Pending() ([]*Entry, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	txnIDs, err := j.pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, txnID := range txnIDs {
+		entry, err := j.get(txnID)
+		if err == core.ErrKeyValueNotFound {
+			logger.Warnf("journal entry for pending transaction [%s] not found", txnID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Delete removes the entry for the given transaction ID
+func (j *Journal) Delete(txnID fab.TransactionID) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := j.setPending(txnID, false); err != nil {
+		return err
+	}
+	return j.store.Delete(string(txnID))
+}
+
+func (j *Journal) update(txnID fab.TransactionID, apply func(entry *Entry)) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry, err := j.get(txnID)
+	if err != nil {
+		return err
+	}
+	apply(entry)
+
+	return j.put(entry)
+}
+
+func (j *Journal) put(entry *Entry) error {
+	entry.Updated = time.Now()
+	if entry.Created.IsZero() {
+		entry.Created = entry.Updated
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "marshal of journal entry failed")
+	}
+	if err := j.store.Store(string(entry.TxnID), value); err != nil {
+		return errors.WithMessage(err, "storing journal entry failed")
+	}
+	return j.setPending(entry.TxnID, !entry.State.Final())
+}
+
+func (j *Journal) get(txnID fab.TransactionID) (*Entry, error) {
+	value, err := j.store.Load(string(txnID))
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, errors.New("journal entry is not a byte array")
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal(valueBytes, entry); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of journal entry failed")
+	}
+	return entry, nil
+}
+
+func (j *Journal) pending() ([]fab.TransactionID, error) {
+	value, err := j.store.Load(pendingKey)
+	if err == core.ErrKeyValueNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading pending journal entries failed")
+	}
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, errors.New("pending journal entries are not a byte array")
+	}
+	var txnIDs []fab.TransactionID
+	if err := json.Unmarshal(valueBytes, &txnIDs); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of pending journal entries failed")
+	}
+	return txnIDs, nil
+}
+
+// setPending adds the transaction ID to or removes it from the list of pending entries
+func (j *Journal) setPending(txnID fab.TransactionID, pending bool) error {
+	txnIDs, err := j.pending()
+	if err != nil {
+		return err
+	}
+
+	updated := make([]fab.TransactionID, 0, len(txnIDs)+1)
+	found := false
+	for _, id := range txnIDs {
+		if id == txnID {
+			found = true
+			if !pending {
+				continue
+			}
+		}
+		updated = append(updated, id)
+	}
+	if found == pending {
+		// nothing changed
+		return nil
+	}
+	if pending {
+		updated = append(updated, txnID)
+	}
+
+	value, err := json.Marshal(updated)
+	if err != nil {
+		return errors.Wrap(err, "marshal of pending journal entries failed")
+	}
+	return j.store.Store(pendingKey, value)
+}
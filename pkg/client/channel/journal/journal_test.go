@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(nil)
+	assert.NotNil(t, err, "expected error for nil store")
+}
+
+func TestJournal(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err, "Got error %s", err)
+	defer os.RemoveAll(storePath)
+
+	j := newTestJournal(t, storePath)
+
+	err = j.Put(&Entry{State: Endorsed})
+	assert.NotNil(t, err, "expected error for entry without transaction ID")
+
+	envelope := &fab.SignedEnvelope{Payload: []byte("payload"), Signature: []byte("signature")}
+	for _, txnID := range []fab.TransactionID{"txid1", "txid2", "txid3", "txid4"} {
+		err = j.Put(&Entry{TxnID: txnID, ChannelID: "mychannel", State: Endorsed, Envelope: envelope})
+		assert.Nil(t, err, "Got error %s", err)
+	}
+
+	assert.Nil(t, j.UpdateState("txid1", Broadcast))
+	assert.Nil(t, j.Complete("txid2", pb.TxValidationCode_VALID))
+	assert.Nil(t, j.Complete("txid3", pb.TxValidationCode_MVCC_READ_CONFLICT))
+	assert.Nil(t, j.UpdateState("txid4", Failed))
+	assert.Equal(t, core.ErrKeyValueNotFound, j.UpdateState("unknown", Broadcast), "expected not found error")
+
+	// entries survive a restart
+	j = newTestJournal(t, storePath)
+
+	entry, err := j.Get("txid2")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, Committed, entry.State)
+
+	entry, err = j.Get("txid3")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, Invalid, entry.State)
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, entry.TxValidationCode)
+
+	pending, err := j.Pending()
+	assert.Nil(t, err, "Got error %s", err)
+	if assert.Len(t, pending, 1, "expected one pending entry") {
+		assert.Equal(t, fab.TransactionID("txid1"), pending[0].TxnID)
+		assert.Equal(t, "mychannel", pending[0].ChannelID)
+		assert.Equal(t, Broadcast, pending[0].State)
+		assert.Equal(t, envelope, pending[0].Envelope)
+	}
+
+	assert.Nil(t, j.Delete("txid1"))
+	_, err = j.Get("txid1")
+	assert.Equal(t, core.ErrKeyValueNotFound, err, "expected not found error")
+
+	pending, err = j.Pending()
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Empty(t, pending, "expected no pending entries")
+}
+
+func TestExpired(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err, "Got error %s", err)
+	defer os.RemoveAll(storePath)
+
+	store, err := keyvaluestore.New(&keyvaluestore.FileKeyValueStoreOptions{Path: storePath})
+	assert.Nil(t, err, "Got error %s", err)
+	j, err := New(store, WithExpiry(time.Minute))
+	assert.Nil(t, err, "Got error %s", err)
+
+	assert.Nil(t, j.Put(&Entry{TxnID: "txid1", State: Endorsed}))
+	entry, err := j.Get("txid1")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.False(t, entry.Created.IsZero(), "expected creation time to be recorded")
+	assert.False(t, j.Expired(entry), "expected new entry not to be expired")
+
+	created := entry.Created
+	assert.Nil(t, j.UpdateState("txid1", Broadcast))
+	entry, err = j.Get("txid1")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.True(t, created.Equal(entry.Created), "expected creation time to be kept on update")
+
+	entry.Created = time.Now().Add(-time.Hour)
+	assert.True(t, j.Expired(entry), "expected old entry to be expired")
+	assert.False(t, newTestJournal(t, storePath).Expired(entry), "expected entries not to expire without expiry")
+}
+
+func newTestJournal(t *testing.T, storePath string) *Journal {
+	store, err := keyvaluestore.New(&keyvaluestore.FileKeyValueStoreOptions{Path: storePath})
+	assert.Nil(t, err, "Got error %s", err)
+
+	j, err := New(store)
+	assert.Nil(t, err, "Got error %s", err)
+	return j
+}
@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/journal"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+)
+
+// RecoveryHandler is invoked by RecoverJournal with the outcome of each recovered transaction.
+// Rebroadcast is true if the envelope was not found in the ledger and was sent again.
+type RecoveryHandler func(response TxStatusResponse, rebroadcast bool, err error)
+
+// RecoverJournal replays the pending transactions of this channel found in the journal configured with WithJournal.
+// It is meant to be called on startup, before new transactions are executed; New calls it if the client
+// is created with WithJournalRecovery.
+//
+// The ledger of the target peers is queried for each pending transaction. Transactions that are
+// not found are broadcast again, whether or not an orderer acknowledged them, unless they have expired
+// (see journal.WithExpiry); the orderers may have dropped an acknowledged transaction, and a transaction
+// ordered twice is committed once, the second copy being invalidated as a duplicate. The event service
+// is then watched for each transaction until the request times out. The outcome is recorded in the journal
+// and reported to the handler; transactions whose status is unknown remain pending, expired transactions
+// are moved to the Failed state.
+func (cc *Client) RecoverJournal(handler RecoveryHandler, options ...RequestOption) error {
+	if cc.journal == nil {
+		return errors.New("journal is not configured")
+	}
+	if handler == nil {
+		return errors.New("recovery handler is required")
+	}
+
+	entries, err := cc.journal.Pending()
+	if err != nil {
+		return errors.WithMessage(err, "reading pending journal entries failed")
+	}
+
+	for _, entry := range entries {
+		if entry.ChannelID != cc.context.ChannelID() {
+			continue
+		}
+		response, rebroadcast, err := cc.recoverEntry(entry, options...)
+		handler(response, rebroadcast, err)
+	}
+	return nil
+}
+
+//recoverEntry determines the outcome of a journaled transaction, rebroadcasting its envelope if required
+func (cc *Client) recoverEntry(entry *journal.Entry, options ...RequestOption) (TxStatusResponse, bool, error) {
+	txnID := entry.TxnID
+	unknown := TxStatusResponse{TransactionID: txnID, Status: TxStatusUnknown}
+
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, cc.addDefaultTimeout(cc.context, core.Execute, options...)...)
+	if err != nil {
+		return unknown, false, err
+	}
+
+	reqCtx, cancel := cc.createReqContext(&txnOpts, "channel.RecoverJournal")
+	defer cancel()
+
+	reg, statusNotifier, err := cc.eventService.RegisterTxStatusEvent(string(txnID))
+	if err != nil {
+		return unknown, false, errors.WithMessage(err, "error registering for TxStatus event")
+	}
+	defer cc.eventService.Unregister(reg)
+
+	code, found, err := cc.queryTxValidationCode(reqCtx, txnID, txnOpts)
+	if err != nil {
+		return unknown, false, err
+	}
+	if found {
+		return cc.journalTxStatus(txnID, code), false, nil
+	}
+
+	if cc.journal.Expired(entry) {
+		if err := cc.journal.UpdateState(txnID, journal.Failed); err != nil {
+			logger.Warnf("journaling expiry of transaction [%s] failed: %s", txnID, err)
+		}
+		return unknown, false, errors.Errorf("transaction [%s] expired before it was found in the ledger", txnID)
+	}
+
+	if err := cc.rebroadcast(reqCtx, entry); err != nil {
+		return unknown, false, err
+	}
+
+	select {
+	case txStatus := <-statusNotifier:
+		return cc.journalTxStatus(txnID, txStatus.TxValidationCode), true, nil
+	case <-reqCtx.Done():
+		return unknown, true, nil
+	}
+}
+
+//rebroadcast sends the journaled envelope to the orderers again
+func (cc *Client) rebroadcast(reqCtx reqContext.Context, entry *journal.Entry) error {
+	if entry.Envelope == nil {
+		return errors.New("journal entry has no envelope")
+	}
+
+	transactor, err := cc.context.InfraProvider().CreateChannelTransactor(reqCtx, cc.context.ChannelService().ChannelConfig())
+	if err != nil {
+		return errors.WithMessage(err, "failed to create transactor")
+	}
+	sender, ok := transactor.(fab.EnvelopeSender)
+	if !ok {
+		return errors.New("transactor does not support sending envelopes")
+	}
+
+	if _, err := sender.SendEnvelope(entry.Envelope); err != nil {
+		return errors.WithMessage(err, "SendEnvelope failed")
+	}
+
+	if err := cc.journal.UpdateState(entry.TxnID, journal.Broadcast); err != nil {
+		logger.Warnf("journaling broadcast of transaction [%s] failed: %s", entry.TxnID, err)
+	}
+	return nil
+}
+
+//journalTxStatus records the final state of the transaction in the journal
+func (cc *Client) journalTxStatus(txnID fab.TransactionID, code pb.TxValidationCode) TxStatusResponse {
+	if err := cc.journal.Complete(txnID, code); err != nil {
+		logger.Warnf("journaling status of transaction [%s] failed: %s", txnID, err)
+	}
+	return newTxStatusResponse(txnID, code)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/journal"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestExecuteTxWithJournal(t *testing.T) {
+	j, cleanup := setupTestJournal(t)
+	defer cleanup()
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	broadcasts := make(chan *fab.SignedEnvelope, 10)
+	testOrderer1 := fcmocks.NewMockOrderer("", broadcasts)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{testOrderer1}, t)
+	WithJournal(j)(chClient)
+
+	mockEventService := fcmocks.NewMockEventService()
+	chClient.eventService = mockEventService
+	go func() {
+		select {
+		case txStatusReg := <-mockEventService.TxStatusRegCh:
+			txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}
+		case <-time.After(time.Second * 5):
+			t.Error("Timed out waiting for execute Tx to register event callback")
+		}
+	}()
+
+	response, err := chClient.Execute(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	assert.Nil(t, err, "Got error %s", err)
+
+	entry, err := j.Get(response.TransactionID)
+	assert.Nil(t, err, "expected journal entry for transaction")
+	assert.Equal(t, journal.Committed, entry.State)
+	assert.Equal(t, channelID, entry.ChannelID)
+	assert.Equal(t, <-broadcasts, entry.Envelope, "expected journaled envelope to be broadcast")
+
+	pending, err := j.Pending()
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Empty(t, pending, "expected no pending journal entries")
+}
+
+func TestRecoverJournalSkipsRejectedBroadcast(t *testing.T) {
+	j, cleanup := setupTestJournal(t)
+	defer cleanup()
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testOrderer1 := fcmocks.NewMockOrderer("", nil)
+	testOrderer1.EnqueueSendBroadcastError(status.New(status.OrdererServerStatus, int32(common.Status_BAD_REQUEST), "bad request", nil))
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{testOrderer1}, t)
+	WithJournal(j)(chClient)
+	chClient.eventService = fcmocks.NewMockEventService()
+
+	_, err := chClient.Execute(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	assert.NotNil(t, err, "expected broadcast error")
+
+	pending, err := j.Pending()
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Empty(t, pending, "expected no pending journal entries after rejected broadcast")
+
+	err = chClient.RecoverJournal(func(response TxStatusResponse, rebroadcast bool, err error) {
+		t.Errorf("unexpected recovery of transaction [%s]", response.TransactionID)
+	}, WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+}
+
+func TestExecuteTxWithJournalBroadcastTimeout(t *testing.T) {
+	j, cleanup := setupTestJournal(t)
+	defer cleanup()
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testOrderer1 := fcmocks.NewMockOrderer("", nil)
+	testOrderer1.EnqueueSendBroadcastError(status.New(status.ClientStatus, status.Timeout.ToInt32(), "request timed out", nil))
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{testOrderer1}, t)
+	WithJournal(j)(chClient)
+	chClient.eventService = fcmocks.NewMockEventService()
+
+	_, err := chClient.Execute(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	assert.NotNil(t, err, "expected broadcast error")
+
+	pending, err := j.Pending()
+	assert.Nil(t, err, "Got error %s", err)
+	if assert.Len(t, pending, 1, "expected the transaction to remain pending after a broadcast timeout") {
+		assert.Equal(t, journal.Endorsed, pending[0].State)
+	}
+}
+
+func TestRecoverJournal(t *testing.T) {
+	j, cleanup := setupTestJournal(t)
+	defer cleanup()
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	broadcasts := make(chan *fab.SignedEnvelope, 10)
+	testOrderer1 := fcmocks.NewMockOrderer("", broadcasts)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{testOrderer1}, t)
+
+	err := chClient.RecoverJournal(func(TxStatusResponse, bool, error) {})
+	assert.NotNil(t, err, "expected error without journal")
+
+	WithJournal(j)(chClient)
+	err = chClient.RecoverJournal(nil)
+	assert.NotNil(t, err, "expected error without handler")
+
+	envelope := &fab.SignedEnvelope{Payload: []byte("payload"), Signature: []byte("signature")}
+	assert.Nil(t, j.Put(&journal.Entry{TxnID: "endorsed", ChannelID: channelID, State: journal.Endorsed, Envelope: envelope}))
+	assert.Nil(t, j.Put(&journal.Entry{TxnID: "otherchannel", ChannelID: "otherchannel", State: journal.Endorsed, Envelope: envelope}))
+
+	// Transaction that was never acknowledged by the orderer is rebroadcast
+	testPeer1.Error = status.New(status.EndorserServerStatus, int32(common.Status_INTERNAL_SERVER_ERROR),
+		"Failed to get transaction with id endorsed, error no such transaction ID [endorsed] in index", nil)
+	mockEventService := fcmocks.NewMockEventService()
+	chClient.eventService = mockEventService
+	go func() {
+		txStatusReg := <-mockEventService.TxStatusRegCh
+		<-broadcasts
+		txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}
+	}()
+
+	var responses []TxStatusResponse
+	err = chClient.RecoverJournal(func(response TxStatusResponse, rebroadcast bool, err error) {
+		assert.Nil(t, err, "Got error %s", err)
+		assert.True(t, rebroadcast, "expected envelope to be rebroadcast")
+		responses = append(responses, response)
+	}, WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+	if assert.Len(t, responses, 1, "expected only the transaction of this channel to be recovered") {
+		assert.Equal(t, fab.TransactionID("endorsed"), responses[0].TransactionID)
+		assert.Equal(t, TxStatusCommitted, responses[0].Status)
+	}
+
+	entry, err := j.Get("endorsed")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, journal.Committed, entry.State)
+
+	// Transaction that was acknowledged by the orderer is found in the ledger
+	assert.Nil(t, j.Put(&journal.Entry{TxnID: "broadcast", ChannelID: channelID, State: journal.Broadcast, Envelope: envelope}))
+	processedTxn, err := proto.Marshal(&pb.ProcessedTransaction{ValidationCode: int32(pb.TxValidationCode_MVCC_READ_CONFLICT)})
+	assert.Nil(t, err, "Got error %s", err)
+	testPeer1.Error = nil
+	testPeer1.Payload = processedTxn
+	chClient.eventService = fcmocks.NewMockEventService()
+
+	responses = nil
+	err = chClient.RecoverJournal(func(response TxStatusResponse, rebroadcast bool, err error) {
+		assert.Nil(t, err, "Got error %s", err)
+		assert.False(t, rebroadcast, "expected envelope not to be rebroadcast")
+		responses = append(responses, response)
+	}, WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+	if assert.Len(t, responses, 1) {
+		assert.Equal(t, TxStatusInvalid, responses[0].Status)
+		assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, responses[0].TxValidationCode)
+	}
+
+	entry, err = j.Get("broadcast")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, journal.Invalid, entry.State)
+
+	// Transaction that was acknowledged by the orderer but is not in the ledger is rebroadcast
+	assert.Nil(t, j.Put(&journal.Entry{TxnID: "dropped", ChannelID: channelID, State: journal.Broadcast, Envelope: envelope}))
+	testPeer1.Payload = nil
+	testPeer1.Error = status.New(status.EndorserServerStatus, int32(common.Status_INTERNAL_SERVER_ERROR),
+		"Failed to get transaction with id dropped, error no such transaction ID [dropped] in index", nil)
+	mockEventService = fcmocks.NewMockEventService()
+	chClient.eventService = mockEventService
+	go func() {
+		txStatusReg := <-mockEventService.TxStatusRegCh
+		<-broadcasts
+		txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}
+	}()
+
+	responses = nil
+	err = chClient.RecoverJournal(func(response TxStatusResponse, rebroadcast bool, err error) {
+		assert.Nil(t, err, "Got error %s", err)
+		assert.True(t, rebroadcast, "expected envelope to be rebroadcast")
+		responses = append(responses, response)
+	}, WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+	if assert.Len(t, responses, 1) {
+		assert.Equal(t, TxStatusCommitted, responses[0].Status)
+	}
+}
+
+func TestRecoverJournalExpiry(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err, "Got error %s", err)
+	defer os.RemoveAll(storePath)
+	store, err := keyvaluestore.New(&keyvaluestore.FileKeyValueStoreOptions{Path: storePath})
+	assert.Nil(t, err, "Got error %s", err)
+	j, err := journal.New(store, journal.WithExpiry(time.Minute))
+	assert.Nil(t, err, "Got error %s", err)
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Error = status.New(status.EndorserServerStatus, int32(common.Status_INTERNAL_SERVER_ERROR),
+		"Failed to get transaction with id expired, error no such transaction ID [expired] in index", nil)
+	broadcasts := make(chan *fab.SignedEnvelope, 10)
+	testOrderer1 := fcmocks.NewMockOrderer("", broadcasts)
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{testOrderer1}, t)
+	WithJournal(j)(chClient)
+	chClient.eventService = fcmocks.NewMockEventService()
+
+	envelope := &fab.SignedEnvelope{Payload: []byte("payload"), Signature: []byte("signature")}
+	assert.Nil(t, j.Put(&journal.Entry{TxnID: "expired", ChannelID: channelID, State: journal.Broadcast, Envelope: envelope, Created: time.Now().Add(-time.Hour)}))
+
+	var recoveryErr error
+	err = chClient.RecoverJournal(func(response TxStatusResponse, rebroadcast bool, err error) {
+		assert.False(t, rebroadcast, "expected expired envelope not to be rebroadcast")
+		assert.Equal(t, TxStatusUnknown, response.Status)
+		recoveryErr = err
+	}, WithTargets(testPeer1))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.NotNil(t, recoveryErr, "expected error for expired transaction")
+	assert.Empty(t, broadcasts, "expected expired envelope not to be rebroadcast")
+
+	entry, err := j.Get("expired")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, journal.Failed, entry.State)
+}
+
+func TestNewWithJournalRecovery(t *testing.T) {
+	j, cleanup := setupTestJournal(t)
+	defer cleanup()
+
+	envelope := &fab.SignedEnvelope{Payload: []byte("payload"), Signature: []byte("signature")}
+	assert.Nil(t, j.Put(&journal.Entry{TxnID: "inflight", ChannelID: channelID, State: journal.Broadcast, Envelope: envelope}))
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	processedTxn, err := proto.Marshal(&pb.ProcessedTransaction{ValidationCode: int32(pb.TxValidationCode_VALID)})
+	assert.Nil(t, err, "Got error %s", err)
+	testPeer1.Payload = processedTxn
+
+	discoveryService, err := setupTestDiscovery(nil, []fab.Peer{testPeer1})
+	assert.Nil(t, err, "Failed to setup discovery service")
+	selectionService, err := setupTestSelection(nil, []fab.Peer{testPeer1})
+	assert.Nil(t, err, "Failed to setup selection service")
+	ctx := createChannelContext(setupCustomTestContext(t, selectionService, discoveryService, nil), channelID)
+
+	recovered := make(chan TxStatusResponse, 1)
+	_, err = New(ctx, WithJournal(j), WithJournalRecovery(func(response TxStatusResponse, rebroadcast bool, err error) {
+		assert.Nil(t, err, "Got error %s", err)
+		recovered <- response
+	}))
+	assert.Nil(t, err, "Got error %s", err)
+
+	select {
+	case response := <-recovered:
+		assert.Equal(t, fab.TransactionID("inflight"), response.TransactionID)
+		assert.Equal(t, TxStatusCommitted, response.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the recovery of the journal")
+	}
+
+	_, err = New(ctx, WithJournalRecovery(func(TxStatusResponse, bool, error) {}))
+	assert.NotNil(t, err, "expected error for journal recovery without journal")
+}
+
+func setupTestJournal(t *testing.T) (*journal.Journal, func()) {
+	storePath, err := ioutil.TempDir("", "journal")
+	assert.Nil(t, err, "Got error %s", err)
+
+	store, err := keyvaluestore.New(&keyvaluestore.FileKeyValueStoreOptions{Path: storePath})
+	assert.Nil(t, err, "Got error %s", err)
+
+	j, err := journal.New(store)
+	assert.Nil(t, err, "Got error %s", err)
+
+	return j, func() { os.RemoveAll(storePath) }
+}
@@ -24,8 +24,8 @@ type MockTransactor struct {
 }
 
 // CreateTransactionHeader creates a Transaction Header based on the current context.
-func (t *MockTransactor) CreateTransactionHeader() (fab.TransactionHeader, error) {
-	txh, err := txn.NewHeader(t.Ctx, t.ChannelID)
+func (t *MockTransactor) CreateTransactionHeader(opts ...fab.TxnHeaderOpt) (fab.TransactionHeader, error) {
+	txh, err := txn.NewHeader(t.Ctx, t.ChannelID, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "new transaction ID failed")
 	}
@@ -51,3 +51,17 @@ func (t *MockTransactor) SendTransaction(tx *fab.Transaction) (*fab.TransactionR
 	defer cancel()
 	return txn.Send(rqtx, tx, t.Orderers)
 }
+
+// CreateTransactionEnvelope creates the signed envelope of a transaction without sending it.
+func (t *MockTransactor) CreateTransactionEnvelope(tx *fab.Transaction) (*fab.SignedEnvelope, error) {
+	rqtx, cancel := contextImpl.NewRequest(t.Ctx, contextImpl.WithTimeout(10*time.Second))
+	defer cancel()
+	return txn.CreateSignedEnvelope(rqtx, tx)
+}
+
+// SendEnvelope sends a signed transaction envelope to the orderers.
+func (t *MockTransactor) SendEnvelope(envelope *fab.SignedEnvelope) (*fab.TransactionResponse, error) {
+	rqtx, cancel := contextImpl.NewRequest(t.Ctx, contextImpl.WithTimeout(10*time.Second))
+	defer cancel()
+	return txn.BroadcastEnvelope(rqtx, envelope, t.Orderers)
+}
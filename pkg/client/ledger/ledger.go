@@ -106,7 +106,7 @@ func (c *Client) QueryInfo(options ...RequestOption) (*fab.BlockchainInfoRespons
 		return nil, errors.WithMessage(err, "failed to determine target peers for QueryBlockByHash")
 	}
 
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "ledger.QueryInfo")
 	defer cancel()
 
 	responses, err := c.ledger.QueryInfo(reqCtx, peersToTxnProcessors(targets))
@@ -152,7 +152,7 @@ func (c *Client) QueryBlockByHash(blockHash []byte, options ...RequestOption) (*
 		return nil, errors.WithMessage(err, "failed to determine target peers for QueryBlockByHash")
 	}
 
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "ledger.QueryBlockByHash")
 	defer cancel()
 
 	responses, err := c.ledger.QueryBlockByHash(reqCtx, blockHash, peersToTxnProcessors(targets))
@@ -196,7 +196,7 @@ func (c *Client) QueryBlock(blockNumber int, options ...RequestOption) (*common.
 		return nil, errors.WithMessage(err, "failed to determine target peers for QueryBlock")
 	}
 
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "ledger.QueryBlock")
 	defer cancel()
 
 	responses, err := c.ledger.QueryBlock(reqCtx, blockNumber, peersToTxnProcessors(targets))
@@ -241,7 +241,7 @@ func (c *Client) QueryTransaction(transactionID fab.TransactionID, options ...Re
 		return nil, errors.WithMessage(err, "failed to determine target peers for QueryTransaction")
 	}
 
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "ledger.QueryTransaction")
 	defer cancel()
 
 	responses, err := c.ledger.QueryTransaction(reqCtx, transactionID, peersToTxnProcessors(targets))
@@ -289,7 +289,7 @@ func (c *Client) QueryConfig(options ...RequestOption) (fab.ChannelCfg, error) {
 		return nil, errors.WithMessage(err, "QueryConfig failed")
 	}
 
-	reqCtx, cancel := c.createRequestContext(opts)
+	reqCtx, cancel := c.createRequestContext(opts, "ledger.QueryConfig")
 	defer cancel()
 
 	return channelConfig.Query(reqCtx)
@@ -370,15 +370,23 @@ func (c *Client) calculateTargets(opts requestOptions) ([]fab.Peer, error) {
 	return targets[:numOfTargets], nil
 }
 
-//createRequestContext creates request context for grpc
-func (c *Client) createRequestContext(opts requestOptions) (reqContext.Context, reqContext.CancelFunc) {
+//createRequestContext creates request context for grpc, identifying the operation of the calls
+func (c *Client) createRequestContext(opts requestOptions, operation string) (reqContext.Context, reqContext.CancelFunc) {
 
 	timeout := opts.Timeout
 	if timeout == 0 {
 		timeout = c.ctx.Config().TimeoutOrDefault(core.PeerResponse)
 	}
 
-	return contextImpl.NewRequest(c.ctx, contextImpl.WithTimeout(timeout))
+	return contextImpl.NewRequest(c.clientContext(opts), contextImpl.WithTimeout(timeout), contextImpl.WithOperation(operation))
+}
+
+//clientContext returns the client context for the request, using the overriding identity if one was provided
+func (c *Client) clientContext(opts requestOptions) context.Client {
+	if opts.Identity == nil {
+		return c.ctx
+	}
+	return &contextImpl.Client{Providers: c.ctx, Identity: opts.Identity}
 }
 
 // filterTargets is helper method to filter peers
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/pkg/errors"
 )
@@ -47,6 +48,7 @@ type requestOptions struct {
 	MaxTargets   int           // maximum number of targets to select
 	MinTargets   int           // min number of targets that have to respond with no error (or agree on result)
 	Timeout      time.Duration //timeout options for QueryInfo,QueryBlockByHash,QueryBlock,QueryTransaction,QueryConfig
+	Identity     msp.Identity  // overrides the client's signing identity
 }
 
 //WithTargets encapsulates fab.Peer targets to ledger RequestOption
@@ -116,3 +118,15 @@ func WithTimeout(timeout time.Duration) RequestOption {
 		return nil
 	}
 }
+
+//WithIdentity encapsulates the identity used to sign the query proposals to ledger RequestOption.
+//If not provided, the identity the client was created with is used.
+func WithIdentity(identity msp.Identity) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if identity == nil {
+			return errors.New("identity is required")
+		}
+		opts.Identity = identity
+		return nil
+	}
+}
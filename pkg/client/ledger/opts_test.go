@@ -9,6 +9,8 @@ package ledger
 import (
 	"testing"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/stretchr/testify/assert"
@@ -69,6 +71,34 @@ func TestWithTargetURLsValid(t *testing.T) {
 	assert.Equal(t, npConfig1.MspID, opts.Targets[0].MSPID(), "", "Wrong MSP")
 }
 
+func TestWithIdentity(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	identity := fcmocks.NewMockUserWithMSPID("override", "Org2MSP")
+
+	opts := requestOptions{}
+	err := WithIdentity(nil)(ctx, &opts)
+	assert.NotNil(t, err, "Should have failed for nil identity")
+
+	err = WithIdentity(identity)(ctx, &opts)
+	assert.Nil(t, err, "Should not have failed for valid identity")
+	assert.Equal(t, identity, opts.Identity, "Wrong identity")
+
+	channelCtx, err := contextImpl.NewChannel(func() (context.Client, error) { return ctx, nil }, "mychannel")
+	assert.Nil(t, err, "Should not have failed to create channel context")
+
+	client := &Client{ctx: channelCtx}
+	reqCtx, cancel := client.createRequestContext(opts, "ledger.QueryInfo")
+	defer cancel()
+
+	reqClient, ok := contextImpl.RequestClientContext(reqCtx)
+	assert.True(t, ok, "expected client context in request context")
+	assert.Equal(t, "Org2MSP", reqClient.MspID(), "expected overriding identity to be used")
+	assert.Equal(t, ctx.InfraProvider(), reqClient.InfraProvider(), "expected providers to be shared")
+	operation, ok := contextImpl.RequestOperation(reqCtx)
+	assert.True(t, ok, "expected operation in request context")
+	assert.Equal(t, "ledger.QueryInfo", operation)
+}
+
 func setupTestContext(userName string, mspID string) *fcmocks.MockContext {
 	user := fcmocks.NewMockUserWithMSPID(userName, mspID)
 	ctx := fcmocks.NewMockContext(user)
@@ -70,7 +70,7 @@ func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
 
 	identityManager, ok := ctx.IdentityManager(orgName)
 	if !ok {
-		return nil, fmt.Errorf("identity manager not found for organization '%s", orgName)
+		return nil, fmt.Errorf("identity manager not found for organization '%s'", orgName)
 	}
 	caClient, err := msp.NewCAClient(orgName, identityManager, ctx.UserStore(), ctx.CryptoSuite(), ctx.Config())
 	if err != nil {
@@ -87,21 +87,58 @@ func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
 //
 // enrollmentID enrollment ID of a registered user
 // enrollmentSecret secret associated with the enrollment ID
-func (c *MSP) Enroll(enrollmentID string, enrollmentSecret string) error {
+// opts optional enrollment options (attribute requests, profile, CSR and CA name)
+func (c *MSP) Enroll(enrollmentID string, enrollmentSecret string, opts ...mspapi.EnrollmentOption) error {
 	ca, err := newCAClient(c.ctx, c.orgName)
 	if err != nil {
 		return err
 	}
-	return ca.Enroll(enrollmentID, enrollmentSecret)
+	return ca.Enroll(enrollmentID, enrollmentSecret, opts...)
 }
 
 // Reenroll reenrolls an enrolled user in order to obtain a new signed X509 certificate
-func (c *MSP) Reenroll(enrollmentID string) error {
+func (c *MSP) Reenroll(enrollmentID string, opts ...mspapi.EnrollmentOption) error {
 	ca, err := newCAClient(c.ctx, c.orgName)
 	if err != nil {
 		return err
 	}
-	return ca.Reenroll(enrollmentID)
+	return ca.Reenroll(enrollmentID, opts...)
+}
+
+// WithAttributeRequests enrollment option requests attributes to be added to the enrollment certificate.
+// The enrollment fails if the identity does not own a requested attribute that is not optional.
+func WithAttributeRequests(attrReqs ...*mspapi.AttributeRequest) mspapi.EnrollmentOption {
+	return func(o *mspapi.EnrollmentOptions) error {
+		for _, attrReq := range attrReqs {
+			if attrReq == nil || attrReq.Name == "" {
+				return errors.New("attribute request name is required")
+			}
+		}
+		o.AttrReqs = append(o.AttrReqs, attrReqs...)
+		return nil
+	}
+}
+
+// WithProfile enrollment option specifies the signing profile used by the CA to issue the certificate
+func WithProfile(profile string) mspapi.EnrollmentOption {
+	return func(o *mspapi.EnrollmentOptions) error {
+		o.Profile = profile
+		return nil
+	}
+}
+
+// WithCSR enrollment option specifies the Certificate Signing Request info
+func WithCSR(csr *mspapi.CSRInfo) mspapi.EnrollmentOption {
+	return func(o *mspapi.EnrollmentOptions) error {
+		o.CSR = csr
+		return nil
+	}
+}
+
+// WithCAName enrollment option specifies the name of the CA to enroll with,
+// for CA servers that host multiple CAs
+func WithCAName(caName string) mspapi.EnrollmentOption {
+	return mspapi.WithCAName(caName)
 }
 
 // Register registers a User with the Fabric CA
@@ -125,14 +162,142 @@ func (c *MSP) Revoke(request *mspapi.RevocationRequest) (*mspapi.RevocationRespo
 	return ca.Revoke(request)
 }
 
-// GetSigningIdentity returns a signing identity for the given user name
-func (c *MSP) GetSigningIdentity(userName string) (*mspctx.SigningIdentity, error) {
-	user, err := c.GetUser(userName)
+// GetAllIdentities returns all identities that the registrar is authorized to see
+// caname: name of the CA (optional)
+func (c *MSP) GetAllIdentities(caname string) ([]*mspapi.IdentityResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetAllIdentities(caname)
+}
+
+// GetIdentity returns the identity with the given enrollment ID
+// caname: name of the CA (optional)
+func (c *MSP) GetIdentity(id, caname string) (*mspapi.IdentityResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetIdentity(id, caname)
+}
+
+// CreateIdentity creates a new identity with the Fabric CA server.
+// An enrollment secret is returned in the response if no secret is given in the request.
+// request: Identity Request
+func (c *MSP) CreateIdentity(request *mspapi.IdentityRequest) (*mspapi.IdentityResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
 	if err != nil {
 		return nil, err
 	}
-	signingIdentity := &mspctx.SigningIdentity{MspID: user.MspID(), PrivateKey: user.PrivateKey(), EnrollmentCert: user.EnrollmentCertificate()}
-	return signingIdentity, nil
+	return ca.CreateIdentity(request)
+}
+
+// ModifyIdentity modifies an existing identity on the Fabric CA server
+// request: Identity Request
+func (c *MSP) ModifyIdentity(request *mspapi.IdentityRequest) (*mspapi.IdentityResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.ModifyIdentity(request)
+}
+
+// RemoveIdentity removes an existing identity from the Fabric CA server
+// request: Remove Identity Request
+func (c *MSP) RemoveIdentity(request *mspapi.RemoveIdentityRequest) (*mspapi.IdentityResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.RemoveIdentity(request)
+}
+
+// GetAllAffiliations returns the tree of all affiliations that the registrar is authorized to see
+// caname: name of the CA (optional)
+func (c *MSP) GetAllAffiliations(caname string) (*mspapi.AffiliationResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetAllAffiliations(caname)
+}
+
+// GetAffiliation returns the affiliation with the given name, including its child affiliations
+// caname: name of the CA (optional)
+func (c *MSP) GetAffiliation(affiliation, caname string) (*mspapi.AffiliationResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetAffiliation(affiliation, caname)
+}
+
+// AddAffiliation adds a new affiliation to the Fabric CA server
+// request: Affiliation Request
+func (c *MSP) AddAffiliation(request *mspapi.AffiliationRequest) (*mspapi.AffiliationResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.AddAffiliation(request)
+}
+
+// ModifyAffiliation renames an existing affiliation on the Fabric CA server
+// request: Modify Affiliation Request
+func (c *MSP) ModifyAffiliation(request *mspapi.ModifyAffiliationRequest) (*mspapi.AffiliationResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.ModifyAffiliation(request)
+}
+
+// RemoveAffiliation removes an existing affiliation from the Fabric CA server
+// request: Affiliation Request
+func (c *MSP) RemoveAffiliation(request *mspapi.AffiliationRequest) (*mspapi.AffiliationResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.RemoveAffiliation(request)
+}
+
+// GetCAInfo returns the generic information of the Fabric CA, including its CA chain, name and version
+// caname: name of the CA (optional)
+func (c *MSP) GetCAInfo(caname string) (*mspapi.GetCAInfoResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetCAInfo(caname)
+}
+
+// GetCertificates returns the certificates issued by the Fabric CA that are selected by the request,
+// e.g. by enrollment ID, serial and AKI, or expiry or revocation window
+// request: Get Certificates Request
+func (c *MSP) GetCertificates(request *mspapi.GetCertificatesRequest) (*mspapi.GetCertificatesResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GetCertificates(request)
+}
+
+// GenCRL returns the current CRL of the Fabric CA
+// request: Generate CRL Request
+func (c *MSP) GenCRL(request *mspapi.GenCRLRequest) (*mspapi.GenCRLResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return ca.GenCRL(request)
+}
+
+// GetSigningIdentity returns a signing identity for the given user name
+func (c *MSP) GetSigningIdentity(userName string) (*mspctx.SigningIdentity, error) {
+	im, _ := c.ctx.IdentityManager(c.orgName)
+	return im.GetSigningIdentity(userName)
 }
 
 // GetUser returns a user for the given user name
@@ -140,3 +305,71 @@ func (c *MSP) GetUser(userName string) (mspctx.User, error) {
 	im, _ := c.ctx.IdentityManager(c.orgName)
 	return im.GetUser(userName)
 }
+
+// userManager is implemented by identity managers that can manage the users in the user store
+type userManager interface {
+	ListUsers() ([]*mspctx.UserData, error)
+	DeleteUser(userName string) error
+	ExportUser(userName string) (*mspapi.IdentityBundle, error)
+	ImportUser(bundle *mspapi.IdentityBundle) error
+	ImportMSPDir(userName, dir string) error
+}
+
+func newUserManager(ctx context.Client, orgName string) (userManager, error) {
+	identityManager, ok := ctx.IdentityManager(orgName)
+	if !ok {
+		return nil, fmt.Errorf("identity manager not found for organization '%s'", orgName)
+	}
+	mgr, ok := identityManager.(userManager)
+	if !ok {
+		return nil, errors.New("identity manager does not support user management")
+	}
+	return mgr, nil
+}
+
+// ListUsers returns the users of the organization in the user store
+func (c *MSP) ListUsers() ([]*mspctx.UserData, error) {
+	mgr, err := newUserManager(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return mgr.ListUsers()
+}
+
+// DeleteUser deletes the user from the user store and its private key from the key store
+func (c *MSP) DeleteUser(userName string) error {
+	mgr, err := newUserManager(c.ctx, c.orgName)
+	if err != nil {
+		return err
+	}
+	return mgr.DeleteUser(userName)
+}
+
+// ExportUser returns a portable bundle containing the MSP ID, enrollment certificate,
+// private key and metadata of the user. The bundle can be serialized as JSON.
+func (c *MSP) ExportUser(userName string) (*mspapi.IdentityBundle, error) {
+	mgr, err := newUserManager(c.ctx, c.orgName)
+	if err != nil {
+		return nil, err
+	}
+	return mgr.ExportUser(userName)
+}
+
+// ImportUser stores the user of a bundle returned by ExportUser
+func (c *MSP) ImportUser(bundle *mspapi.IdentityBundle) error {
+	mgr, err := newUserManager(c.ctx, c.orgName)
+	if err != nil {
+		return err
+	}
+	return mgr.ImportUser(bundle)
+}
+
+// ImportMSPDir imports the identity of a cryptogen or fabric-ca-client MSP directory
+// (signcerts and keystore) as the given user
+func (c *MSP) ImportMSPDir(userName, dir string) error {
+	mgr, err := newUserManager(c.ctx, c.orgName)
+	if err != nil {
+		return err
+	}
+	return mgr.ImportMSPDir(userName, dir)
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -19,6 +21,7 @@ import (
 	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	mspapi "github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
 )
 
@@ -130,12 +133,275 @@ func TestMSP(t *testing.T) {
 
 }
 
+// TestEnrollWithOptions tests that enrollment options are sent to the CA
+func TestEnrollWithOptions(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	enrollUserName := randomUserName()
+
+	// Attribute request without name
+	err = msp.Enroll(enrollUserName, "enrollmentSecret", WithAttributeRequests(&mspapi.AttributeRequest{}))
+	if err == nil {
+		t.Fatalf("Expected error for attribute request without name")
+	}
+
+	// CSR common name different from enrollment ID
+	err = msp.Enroll(enrollUserName, "enrollmentSecret", WithCSR(&mspapi.CSRInfo{CN: "other"}))
+	if err == nil || !strings.Contains(err.Error(), "must match the enrollment ID") {
+		t.Fatalf("Expected error for CSR common name, got %v", err)
+	}
+
+	err = msp.Enroll(enrollUserName, "enrollmentSecret",
+		WithAttributeRequests(
+			&mspapi.AttributeRequest{Name: "hf.Affiliation"},
+			&mspapi.AttributeRequest{Name: "app.role", Optional: true},
+		),
+		WithProfile("tls"),
+		WithCAName("ca.org1.example.com"),
+		WithCSR(&mspapi.CSRInfo{
+			CN:         enrollUserName,
+			Hosts:      []string{"app.example.com"},
+			Names:      []mspapi.CSRName{{C: "US", O: "Org1", OU: "client"}},
+			KeyRequest: &mspapi.KeyRequest{Algo: "ecdsa", Size: 256},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	req := caServer.LastEnrollmentRequest()
+	if req == nil {
+		t.Fatalf("Expected enrollment request to be received by CA")
+	}
+	if len(req.AttrReqs) != 2 || req.AttrReqs[0].Name != "hf.Affiliation" || req.AttrReqs[0].Optional ||
+		req.AttrReqs[1].Name != "app.role" || !req.AttrReqs[1].Optional {
+		t.Fatalf("Unexpected attribute requests: %v", req.AttrReqs)
+	}
+	if req.Profile != "tls" || req.CAName != "ca.org1.example.com" {
+		t.Fatalf("Unexpected profile [%s] or CA name [%s]", req.Profile, req.CAName)
+	}
+	if len(req.Hosts) != 1 || req.Hosts[0] != "app.example.com" {
+		t.Fatalf("Unexpected hosts: %v", req.Hosts)
+	}
+
+	block, _ := pem.Decode([]byte(req.Request))
+	if block == nil {
+		t.Fatalf("Expected PEM encoded CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse CSR: %v", err)
+	}
+	if csr.Subject.CommonName != enrollUserName {
+		t.Fatalf("Unexpected CSR common name: %s", csr.Subject.CommonName)
+	}
+	if len(csr.Subject.OrganizationalUnit) != 1 || csr.Subject.OrganizationalUnit[0] != "client" {
+		t.Fatalf("Unexpected CSR organizational unit: %v", csr.Subject.OrganizationalUnit)
+	}
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != "app.example.com" {
+		t.Fatalf("Unexpected CSR DNS names: %v", csr.DNSNames)
+	}
+
+	// Re-enrollment options
+	err = msp.Reenroll(enrollUserName, WithAttributeRequests(&mspapi.AttributeRequest{Name: "hf.Type"}))
+	if err != nil {
+		t.Fatalf("Reenroll return error %v", err)
+	}
+	req = caServer.LastEnrollmentRequest()
+	if len(req.AttrReqs) != 1 || req.AttrReqs[0].Name != "hf.Type" {
+		t.Fatalf("Unexpected attribute requests: %v", req.AttrReqs)
+	}
+}
+
+// TestIdentityManagement tests identity management through the MSP client
+func TestIdentityManagement(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	id := randomUserName()
+	resp, err := msp.CreateIdentity(&mspapi.IdentityRequest{ID: id, Affiliation: "org1", Secret: "top-secret"})
+	if err != nil {
+		t.Fatalf("CreateIdentity return error %v", err)
+	}
+	if resp.Secret != "top-secret" {
+		t.Fatalf("Unexpected secret: %s", resp.Secret)
+	}
+
+	resp, err = msp.ModifyIdentity(&mspapi.IdentityRequest{ID: id, Type: "peer"})
+	if err != nil {
+		t.Fatalf("ModifyIdentity return error %v", err)
+	}
+
+	resp, err = msp.GetIdentity(id, "")
+	if err != nil {
+		t.Fatalf("GetIdentity return error %v", err)
+	}
+	if resp.Type != "peer" || resp.Affiliation != "org1" {
+		t.Fatalf("Unexpected identity: %+v", resp)
+	}
+
+	identities, err := msp.GetAllIdentities("")
+	if err != nil || len(identities) == 0 {
+		t.Fatalf("GetAllIdentities return error %v", err)
+	}
+
+	_, err = msp.RemoveIdentity(&mspapi.RemoveIdentityRequest{ID: id})
+	if err != nil {
+		t.Fatalf("RemoveIdentity return error %v", err)
+	}
+}
+
+// TestAffiliationManagement tests affiliation management through the MSP client
+func TestAffiliationManagement(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	dept := "org2." + randomUserName()
+	_, err = msp.AddAffiliation(&mspapi.AffiliationRequest{Name: dept})
+	if err != nil {
+		t.Fatalf("AddAffiliation return error %v", err)
+	}
+
+	_, err = msp.ModifyAffiliation(&mspapi.ModifyAffiliationRequest{AffiliationRequest: mspapi.AffiliationRequest{Name: dept}, NewName: dept + "x"})
+	if err != nil {
+		t.Fatalf("ModifyAffiliation return error %v", err)
+	}
+
+	resp, err := msp.GetAffiliation(dept+"x", "")
+	if err != nil || resp.Name != dept+"x" {
+		t.Fatalf("GetAffiliation return error %v", err)
+	}
+
+	resp, err = msp.GetAllAffiliations("")
+	if err != nil || len(resp.Affiliations) == 0 {
+		t.Fatalf("GetAllAffiliations return error %v", err)
+	}
+
+	_, err = msp.RemoveAffiliation(&mspapi.AffiliationRequest{Name: dept + "x"})
+	if err != nil {
+		t.Fatalf("RemoveAffiliation return error %v", err)
+	}
+}
+
+func TestCertificatesAndCRL(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	info, err := msp.GetCAInfo("")
+	if err != nil || len(info.CAChain) == 0 {
+		t.Fatalf("GetCAInfo return error %v", err)
+	}
+
+	enrollUsername := randomUserName()
+	if err := msp.Enroll(enrollUsername, "enrollmentSecret"); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	certs, err := msp.GetCertificates(&mspapi.GetCertificatesRequest{ID: enrollUsername})
+	if err != nil || len(certs.Certs) != 1 {
+		t.Fatalf("GetCertificates return error %v", err)
+	}
+
+	crl, err := msp.GenCRL(&mspapi.GenCRLRequest{})
+	if err != nil {
+		t.Fatalf("GenCRL return error %v", err)
+	}
+	if _, err := x509.ParseCRL(crl.CRL); err != nil {
+		t.Fatalf("Failed to parse CRL: %v", err)
+	}
+}
+
 type textFixture struct {
 	config core.Config
 }
 
 var caServer = &mocks.MockFabricCAServer{}
 
+// TestUserManagement tests listing, deleting, exporting and importing users
+func TestUserManagement(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	userName := randomUserName()
+	if err := msp.Enroll(userName, "enrollmentSecret"); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	users, err := msp.ListUsers()
+	if err != nil {
+		t.Fatalf("ListUsers return error %v", err)
+	}
+	if !containsUser(users, userName) {
+		t.Fatalf("Expected to list enrolled user")
+	}
+
+	bundle, err := msp.ExportUser(userName)
+	if err != nil {
+		t.Fatalf("ExportUser return error %v", err)
+	}
+	if bundle.MspID != "Org1MSP" || bundle.Name != userName || bundle.PrivateKey == "" {
+		t.Fatalf("Unexpected identity bundle: %+v", bundle)
+	}
+
+	if err := msp.DeleteUser(userName); err != nil {
+		t.Fatalf("DeleteUser return error %v", err)
+	}
+	if _, err := msp.GetUser(userName); err != mspctx.ErrUserNotFound {
+		t.Fatalf("Expected to not find deleted user")
+	}
+
+	if err := msp.ImportUser(bundle); err != nil {
+		t.Fatalf("ImportUser return error %v", err)
+	}
+	if _, err := msp.GetSigningIdentity(userName); err != nil {
+		t.Fatalf("Expected to find imported user")
+	}
+}
+
+func containsUser(users []*mspctx.UserData, userName string) bool {
+	for _, user := range users {
+		if user.Name == userName {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *textFixture) setup() *fabsdk.FabricSDK {
 
 	configProvider := config.FromFile(configPath)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/pkg/errors"
 )
@@ -97,3 +98,16 @@ func WithOrderer(orderer fab.Orderer) RequestOption {
 		return nil
 	}
 }
+
+// WithIdentity allows the identity that signs proposals, transactions and
+// channel configuration for the request to be specified. If not specified,
+// the identity the client was created with is used.
+func WithIdentity(identity msp.Identity) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if identity == nil {
+			return errors.New("identity is required")
+		}
+		opts.Identity = identity
+		return nil
+	}
+}
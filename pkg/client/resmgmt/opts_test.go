@@ -9,6 +9,7 @@ package resmgmt
 import (
 	"testing"
 
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/stretchr/testify/assert"
@@ -68,3 +69,28 @@ func TestWithTargetURLsValid(t *testing.T) {
 	assert.Equal(t, pConfig1.URL, opts.Targets[0].URL(), "", "Wrong URL")
 	assert.Equal(t, npConfig1.MspID, opts.Targets[0].MSPID(), "", "Wrong MSP")
 }
+
+func TestWithIdentity(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	identity := fcmocks.NewMockUserWithMSPID("override", "Org2MSP")
+
+	opts := requestOptions{}
+	err := WithIdentity(nil)(ctx, &opts)
+	assert.NotNil(t, err, "Should have failed for nil identity")
+
+	err = WithIdentity(identity)(ctx, &opts)
+	assert.Nil(t, err, "Should not have failed for valid identity")
+	assert.Equal(t, identity, opts.Identity, "Wrong identity")
+
+	client := &Client{ctx: ctx}
+	reqCtx, cancel := client.createRequestContext(opts, "resmgmt.QueryChannels", core.PeerResponse)
+	defer cancel()
+
+	reqClient, ok := contextImpl.RequestClientContext(reqCtx)
+	assert.True(t, ok, "expected client context in request context")
+	assert.Equal(t, "Org2MSP", reqClient.MspID(), "expected overriding identity to be used")
+	assert.Equal(t, ctx.InfraProvider(), reqClient.InfraProvider(), "expected providers to be shared")
+	operation, ok := contextImpl.RequestOperation(reqCtx)
+	assert.True(t, ok, "expected operation in request context")
+	assert.Equal(t, "resmgmt.QueryChannels", operation)
+}
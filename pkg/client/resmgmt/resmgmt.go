@@ -82,6 +82,7 @@ type requestOptions struct {
 	TargetFilter TargetFilter  // target filter
 	Timeout      time.Duration // timeout options for instantiate and upgrade CC
 	Orderer      fab.Orderer   // use specific orderer
+	Identity     msp.Identity  // overrides the client's signing identity
 }
 
 //SaveChannelRequest used to save channel request
@@ -180,7 +181,8 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 		//use Channelmgmt timeout from config as default when overall timeout not supplied
 		opts.Timeout = rc.ctx.Config().TimeoutOrDefault(core.ResMgmt)
 	}
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeout))
+	ctx := rc.clientContext(opts)
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeout(opts.Timeout), contextImpl.WithOperation("resmgmt.JoinChannel"))
 	defer parentReqCancel()
 
 	targets, err := rc.calculateTargets(rc.discovery, opts.Targets, opts.TargetFilter)
@@ -197,7 +199,7 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 		return errors.WithMessage(err, "failed to find orderer for request")
 	}
 
-	ordrReqCtx, ordrReqCtxCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeoutType(core.OrdererResponse), contextImpl.WithReqContext(parentReqCtx))
+	ordrReqCtx, ordrReqCtxCancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(core.OrdererResponse), contextImpl.WithReqContext(parentReqCtx))
 	defer ordrReqCtxCancel()
 
 	genesisBlock, err := resource.GenesisBlockFromOrderer(ordrReqCtx, channelID, orderer)
@@ -209,7 +211,7 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 		GenesisBlock: genesisBlock,
 	}
 
-	peerReqCtx, peerReqCtxCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeout), contextImpl.WithReqContext(parentReqCtx))
+	peerReqCtx, peerReqCtxCancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeout(opts.Timeout), contextImpl.WithReqContext(parentReqCtx))
 	defer peerReqCtxCancel()
 	err = resource.JoinChannel(peerReqCtx, joinChannelRequest, peersToTxnProcessors(targets))
 	if err != nil {
@@ -322,7 +324,8 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 		//use ChaincodeMgmt timeout from config as default when overall timeout not supplied
 		opts.Timeout = rc.ctx.Config().TimeoutOrDefault(core.ResMgmt)
 	}
-	parentReqCtx, parentReqCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeout))
+	ctx := rc.clientContext(opts)
+	parentReqCtx, parentReqCancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeout(opts.Timeout), contextImpl.WithOperation("resmgmt.InstallCC"))
 	defer parentReqCancel()
 
 	//Default targets when targets are not provided in options
@@ -348,7 +351,7 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 	// Targets will be adjusted if cc has already been installed
 	newTargets := make([]fab.Peer, 0)
 	for _, target := range targets {
-		reqCtx, cancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeoutType(core.PeerResponse), contextImpl.WithReqContext(parentReqCtx))
+		reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(core.PeerResponse), contextImpl.WithReqContext(parentReqCtx))
 		defer cancel()
 
 		installed, err := rc.isChaincodeInstalled(reqCtx, req, target)
@@ -373,7 +376,7 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 		return responses, nil
 	}
 
-	reqCtx, cancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(opts.Timeout), contextImpl.WithReqContext(parentReqCtx))
+	reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeout(opts.Timeout), contextImpl.WithReqContext(parentReqCtx))
 	defer cancel()
 
 	icr := api.InstallChaincodeRequest{Name: req.Name, Path: req.Path, Version: req.Version, Package: req.Package}
@@ -407,7 +410,7 @@ func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, opti
 		return errors.WithMessage(err, "failed to get opts for InstantiateCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.InstantiateCC", core.PeerResponse)
 	defer cancel()
 
 	return rc.sendCCProposal(reqCtx, InstantiateChaincode, channelID, req, opts)
@@ -421,7 +424,7 @@ func (rc *Client) UpgradeCC(channelID string, req UpgradeCCRequest, options ...R
 		return errors.WithMessage(err, "failed to get opts for UpgradeCC")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.UpgradeCC", core.PeerResponse)
 	defer cancel()
 
 	return rc.sendCCProposal(reqCtx, UpgradeChaincode, channelID, InstantiateCCRequest(req), opts)
@@ -440,7 +443,7 @@ func (rc *Client) QueryInstalledChaincodes(options ...RequestOption) (*pb.Chainc
 		return nil, errors.New("only one target is supported")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.QueryInstalledChaincodes", core.PeerResponse)
 	defer cancel()
 
 	return resource.QueryInstalledChaincodes(reqCtx, opts.Targets[0])
@@ -480,7 +483,7 @@ func (rc *Client) QueryInstantiatedChaincodes(channelID string, options ...Reque
 		return nil, err
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.QueryInstantiatedChaincodes", core.PeerResponse)
 	defer cancel()
 
 	responses, err := l.QueryInstantiatedChaincodes(reqCtx, []fab.ProposalProcessor{target})
@@ -504,7 +507,7 @@ func (rc *Client) QueryChannels(options ...RequestOption) (*pb.ChannelQueryRespo
 		return nil, errors.New("only one target is supported")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.QueryChannels", core.PeerResponse)
 	defer cancel()
 
 	return resource.QueryChannels(reqCtx, opts.Targets[0])
@@ -556,7 +559,7 @@ func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chain
 	// create a transaction proposal for chaincode deployment
 	deployProposal := chaincodeDeployRequest(req)
 
-	txid, err := txn.NewHeader(rc.ctx, channelID)
+	txid, err := txn.NewHeader(rc.clientContext(opts), channelID)
 	if err != nil {
 		return errors.WithMessage(err, "create transaction ID failed")
 	}
@@ -679,6 +682,8 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 				signers = append(signers, id)
 			}
 		}
+	} else if opts.Identity != nil {
+		signers = append(signers, opts.Identity)
 	} else if rc.ctx != nil {
 		signers = append(signers, rc.ctx)
 	} else {
@@ -722,7 +727,7 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		Signatures: configSignatures,
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.OrdererResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.SaveChannel", core.OrdererResponse)
 	defer cancel()
 
 	_, err = resource.CreateChannel(reqCtx, request)
@@ -753,7 +758,7 @@ func (rc *Client) QueryConfigFromOrderer(channelID string, options ...RequestOpt
 		return nil, errors.WithMessage(err, "QueryConfig failed")
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.OrdererResponse)
+	reqCtx, cancel := rc.createRequestContext(opts, "resmgmt.QueryConfigFromOrderer", core.OrdererResponse)
 	defer cancel()
 
 	return channelConfig.Query(reqCtx)
@@ -811,13 +816,21 @@ func (rc *Client) prepareRequestOpts(options ...RequestOption) (requestOptions,
 	return opts, nil
 }
 
-//createRequestContext creates request context for grpc
-func (rc *Client) createRequestContext(opts requestOptions, defaultTimeoutType core.TimeoutType) (reqContext.Context, reqContext.CancelFunc) {
+//createRequestContext creates request context for grpc, identifying the operation of the calls
+func (rc *Client) createRequestContext(opts requestOptions, operation string, defaultTimeoutType core.TimeoutType) (reqContext.Context, reqContext.CancelFunc) {
 
 	timeout := opts.Timeout
 	if timeout == 0 {
 		timeout = rc.ctx.Config().TimeoutOrDefault(defaultTimeoutType)
 	}
 
-	return contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(timeout))
+	return contextImpl.NewRequest(rc.clientContext(opts), contextImpl.WithTimeout(timeout), contextImpl.WithOperation(operation))
+}
+
+//clientContext returns the client context for the request, using the overriding identity if one was provided
+func (rc *Client) clientContext(opts requestOptions) context.Client {
+	if opts.Identity == nil {
+		return rc.ctx
+	}
+	return &contextImpl.Client{Providers: rc.ctx, Identity: opts.Identity}
 }
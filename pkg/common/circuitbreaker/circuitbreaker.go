@@ -0,0 +1,278 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package circuitbreaker stops requests from being sent to endpoints that keep failing.
+//
+// A circuit is kept per endpoint. It opens after a number of consecutive failures, after which
+// no requests are sent to the endpoint for the open interval. A single probe request is then
+// allowed (half-open state); the circuit closes if the probe succeeds and opens again if it fails.
+package circuitbreaker
+
+import (
+	reqContext "context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+var logger = logging.NewLogger("fabsdk/common")
+
+const (
+	// DefaultThreshold is the default number of consecutive failures after which a circuit opens
+	DefaultThreshold = 3
+)
+
+// State is the state of the circuit of an endpoint
+type State int
+
+const (
+	// Closed requests are sent to the endpoint
+	Closed State = iota
+	// Open no requests are sent to the endpoint
+	Open
+	// HalfOpen a single probe request is sent to the endpoint
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "CLOSED"
+	case Open:
+		return "OPEN"
+	case HalfOpen:
+		return "HALF_OPEN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// EndpointState describes the circuit of an endpoint, for diagnostics
+type EndpointState struct {
+	Address   string
+	State     State
+	Failures  int
+	LastError string
+	Changed   time.Time
+}
+
+type circuit struct {
+	state     State
+	failures  int
+	lastError string
+	changed   time.Time
+}
+
+// Breaker keeps a circuit for each endpoint. Endpoints are identified by their address,
+// so a peer or orderer URL with or without protocol refer to the same circuit.
+type Breaker struct {
+	threshold    int
+	openInterval time.Duration
+	mutex        sync.Mutex
+	circuits     map[string]*circuit
+	now          func() time.Time
+}
+
+// New returns a Breaker that opens the circuit of an endpoint after the given number of
+// consecutive failures and keeps it open for the given interval before allowing a probe.
+// If the threshold is not positive, DefaultThreshold is used.
+func New(threshold int, openInterval time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	return &Breaker{
+		threshold:    threshold,
+		openInterval: openInterval,
+		circuits:     make(map[string]*circuit),
+		now:          time.Now,
+	}
+}
+
+// NewFromPolicy returns a Breaker for the given policy. The default open interval is used
+// if the policy does not define one.
+func NewFromPolicy(policy core.CircuitBreakerPolicy, defaultOpenInterval time.Duration) *Breaker {
+	openInterval := policy.OpenInterval
+	if openInterval == 0 {
+		openInterval = defaultOpenInterval
+	}
+	return New(policy.Threshold, openInterval)
+}
+
+// Accept returns whether or not to accept a peer as a candidate for endorsement.
+// Breaker therefore implements fab.TargetFilter and can be used with the discovery filter service.
+// Accepting a peer does not claim the probe of a half-open circuit, the probe is claimed
+// when the proposal is sent (see ProposalProcessor).
+func (b *Breaker) Accept(peer fab.Peer) bool {
+	return b.Available(peer.URL())
+}
+
+// Available returns whether a request may be sent to the endpoint, without claiming
+// the probe of the circuit if the open interval has passed
+func (b *Breaker) Available(url string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[endpoint.ToAddress(url)]
+	return !ok || b.available(c)
+}
+
+// Allow returns whether a request may be sent to the endpoint and must be called right before
+// the request is sent. Once the open interval has passed, the circuit becomes half-open and only
+// the first caller is allowed to send a probe. Another probe is allowed if the outcome of the
+// probe is not recorded within the open interval.
+func (b *Breaker) Allow(url string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[endpoint.ToAddress(url)]
+	if !ok || c.state == Closed {
+		return true
+	}
+	if !b.available(c) {
+		logger.Debugf("Rejecting endpoint %s with %s circuit", url, c.state)
+		return false
+	}
+
+	logger.Debugf("Allowing probe of endpoint %s", url)
+	c.state = HalfOpen
+	c.changed = b.now()
+	return true
+}
+
+// Record records the outcome of a request sent to the endpoint. Only errors that indicate
+// a problem with the endpoint count as failures; any other outcome shows that the endpoint is healthy.
+func (b *Breaker) Record(url string, err error) {
+	if err != nil && EndpointFailure(err) {
+		b.failure(url, err)
+		return
+	}
+	b.success(url)
+}
+
+// State returns the state of the circuit of the endpoint
+func (b *Breaker) State(url string) State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[endpoint.ToAddress(url)]
+	if !ok {
+		return Closed
+	}
+	return c.state
+}
+
+// Endpoints returns the state of the circuits of the endpoints that have failed, sorted by address
+func (b *Breaker) Endpoints() []EndpointState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	states := make([]EndpointState, 0, len(b.circuits))
+	for address, c := range b.circuits {
+		states = append(states, EndpointState{
+			Address:   address,
+			State:     c.state,
+			Failures:  c.failures,
+			LastError: c.lastError,
+			Changed:   c.changed,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Address < states[j].Address })
+	return states
+}
+
+func (b *Breaker) failure(url string, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	address := endpoint.ToAddress(url)
+	c, ok := b.circuits[address]
+	if !ok {
+		c = &circuit{changed: b.now()}
+		b.circuits[address] = c
+	}
+	c.failures++
+	c.lastError = err.Error()
+
+	// a failure of an endpoint whose circuit is not closed, probe or not, keeps the circuit open for another interval
+	if c.state != Closed || c.failures >= b.threshold {
+		logger.Infof("Opening circuit of endpoint %s after %d failures: %s", url, c.failures, err)
+		c.state = Open
+		c.changed = b.now()
+	}
+}
+
+func (b *Breaker) available(c *circuit) bool {
+	return c.state == Closed || !b.now().Before(c.changed.Add(b.openInterval))
+}
+
+func (b *Breaker) success(url string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	address := endpoint.ToAddress(url)
+	if c, ok := b.circuits[address]; ok {
+		if c.state != Closed {
+			logger.Infof("Closing circuit of endpoint %s", url)
+		}
+		delete(b.circuits, address)
+	}
+}
+
+// ProposalProcessor returns a proposal processor that claims the probe of the circuit of the
+// given URL before sending a proposal and records the outcome of the proposals sent to the given
+// processor. A proposal is not sent if another request is probing the endpoint.
+func (b *Breaker) ProposalProcessor(url string, processor fab.ProposalProcessor) fab.ProposalProcessor {
+	return &proposalProcessor{ProposalProcessor: processor, url: url, breaker: b}
+}
+
+type proposalProcessor struct {
+	fab.ProposalProcessor
+	url     string
+	breaker *Breaker
+}
+
+func (p *proposalProcessor) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	if !p.breaker.Allow(p.url) {
+		return nil, errors.Errorf("circuit of endpoint %s is open", p.url)
+	}
+	resp, err := p.ProposalProcessor.ProcessTransactionProposal(ctx, request)
+	p.breaker.Record(p.url, err)
+	return resp, err
+}
+
+// EndpointFailure decides whether the error indicates a problem with the endpoint,
+// such as a failed connection, a timeout or the endpoint being unavailable
+func EndpointFailure(err error) bool {
+	if errors.Cause(err) == reqContext.DeadlineExceeded {
+		return true
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch s.Group {
+	case status.EndorserClientStatus, status.OrdererClientStatus:
+		return s.Code == status.ConnectionFailed.ToInt32()
+	case status.ClientStatus:
+		return s.Code == status.Timeout.ToInt32()
+	case status.GRPCTransportStatus:
+		return s.Code == int32(grpcCodes.Unavailable) || s.Code == int32(grpcCodes.DeadlineExceeded)
+	case status.EndorserServerStatus, status.OrdererServerStatus:
+		return s.Code == int32(common.Status_SERVICE_UNAVAILABLE) || s.Code == int32(common.Status_INTERNAL_SERVER_ERROR)
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package circuitbreaker
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+var connectionFailed = status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil)
+
+func TestBreakerThreshold(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+
+	b.Record("grpcs://peer1.com:7051", connectionFailed)
+	assert.Equal(t, Closed, b.State("peer1.com:7051"))
+	assert.True(t, b.Allow("peer1.com:7051"))
+
+	// A success resets the consecutive failures
+	b.Record("peer1.com:7051", nil)
+	assert.Empty(t, b.Endpoints())
+
+	b.Record("peer1.com:7051", connectionFailed)
+	b.Record("peer1.com:7051", connectionFailed)
+	assert.Equal(t, Open, b.State("grpcs://peer1.com:7051"))
+	assert.False(t, b.Allow("peer1.com:7051"))
+	assert.True(t, b.Allow("peer2.com:7051"))
+
+	states := b.Endpoints()
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, "peer1.com:7051", states[0].Address)
+		assert.Equal(t, Open, states[0].State)
+		assert.Equal(t, 2, states[0].Failures)
+		assert.Equal(t, connectionFailed.Error(), states[0].LastError)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+
+	b.Record("peer1.com", connectionFailed)
+	assert.False(t, b.Allow("peer1.com"))
+
+	// A single probe is allowed after the open interval
+	clock.advance(time.Minute)
+	assert.True(t, b.Allow("peer1.com"))
+	assert.Equal(t, HalfOpen, b.State("peer1.com"))
+	assert.False(t, b.Allow("peer1.com"), "expected only a single probe")
+
+	// A failed probe opens the circuit again
+	b.Record("peer1.com", connectionFailed)
+	assert.Equal(t, Open, b.State("peer1.com"))
+	assert.False(t, b.Allow("peer1.com"))
+
+	// Another probe is allowed if the outcome of a probe is not recorded
+	clock.advance(time.Minute)
+	assert.True(t, b.Allow("peer1.com"))
+	clock.advance(time.Minute)
+	assert.True(t, b.Allow("peer1.com"))
+
+	// A successful probe closes the circuit
+	b.Record("peer1.com", nil)
+	assert.Equal(t, Closed, b.State("peer1.com"))
+	assert.True(t, b.Allow("peer1.com"))
+	assert.Empty(t, b.Endpoints())
+}
+
+func TestBreakerIgnoresApplicationErrors(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+
+	b.Record("peer1.com", status.New(status.EndorserServerStatus, int32(common.Status_BAD_REQUEST), "bad request", nil))
+	b.Record("peer1.com", errors.New("other error"))
+	assert.Equal(t, Closed, b.State("peer1.com"))
+	assert.Empty(t, b.Endpoints())
+}
+
+func TestNewFromPolicy(t *testing.T) {
+	b := NewFromPolicy(core.CircuitBreakerPolicy{}, time.Second)
+	assert.Equal(t, DefaultThreshold, b.threshold)
+	assert.Equal(t, time.Second, b.openInterval)
+
+	b = NewFromPolicy(core.CircuitBreakerPolicy{Threshold: 5, OpenInterval: time.Minute}, time.Second)
+	assert.Equal(t, 5, b.threshold)
+	assert.Equal(t, time.Minute, b.openInterval)
+}
+
+func TestEndpointFailure(t *testing.T) {
+	failures := []error{
+		connectionFailed,
+		status.New(status.OrdererClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil),
+		status.New(status.ClientStatus, status.Timeout.ToInt32(), "timeout", nil),
+		status.New(status.GRPCTransportStatus, int32(grpcCodes.Unavailable), "unavailable", nil),
+		status.New(status.GRPCTransportStatus, int32(grpcCodes.DeadlineExceeded), "deadline exceeded", nil),
+		status.New(status.EndorserServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "unavailable", nil),
+		status.New(status.OrdererServerStatus, int32(common.Status_INTERNAL_SERVER_ERROR), "internal error", nil),
+		errors.Wrap(reqContext.DeadlineExceeded, "request timed out"),
+	}
+	for _, err := range failures {
+		assert.True(t, EndpointFailure(err), "expected endpoint failure: %s", err)
+	}
+
+	others := []error{
+		status.New(status.EndorserServerStatus, int32(common.Status_BAD_REQUEST), "bad request", nil),
+		status.New(status.GRPCTransportStatus, int32(grpcCodes.PermissionDenied), "permission denied", nil),
+		status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no peers", nil),
+		errors.New("other error"),
+	}
+	for _, err := range others {
+		assert.False(t, EndpointFailure(err), "expected no endpoint failure: %s", err)
+	}
+}
+
+func TestAcceptAndProposalProcessor(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+
+	peer := mocks.NewMockPeer("Peer1", "http://peer1.com")
+	processor := b.ProposalProcessor(peer.URL(), peer)
+	assert.True(t, b.Accept(peer))
+
+	_, err := processor.ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, Closed, b.State(peer.URL()))
+
+	peer.Error = connectionFailed
+	_, err = processor.ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	assert.Equal(t, connectionFailed, err)
+	assert.Equal(t, Open, b.State(peer.URL()))
+	assert.False(t, b.Accept(peer))
+}
+
+func TestProbeClaimedOnSend(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+
+	peer := mocks.NewMockPeer("Peer1", "http://peer1.com")
+	processor := b.ProposalProcessor(peer.URL(), peer)
+	b.Record(peer.URL(), connectionFailed)
+	clock.advance(time.Minute)
+
+	// Accepting the peer, e.g. by the discovery filter, does not claim the probe
+	assert.True(t, b.Accept(peer))
+	assert.True(t, b.Accept(peer))
+	assert.True(t, b.Available(peer.URL()))
+	assert.Equal(t, Open, b.State(peer.URL()))
+
+	// The probe is claimed when the proposal is sent
+	peer.Error = connectionFailed
+	_, err := processor.ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	assert.Equal(t, connectionFailed, err)
+	assert.Equal(t, Open, b.State(peer.URL()))
+	assert.False(t, b.Accept(peer))
+
+	// A proposal is not sent while another request is probing the endpoint
+	clock.advance(time.Minute)
+	assert.True(t, b.Allow(peer.URL()))
+	assert.Equal(t, HalfOpen, b.State(peer.URL()))
+	peer.Error = nil
+	_, err = processor.ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	assert.NotNil(t, err, "expected error while endpoint is probed")
+	assert.Equal(t, HalfOpen, b.State(peer.URL()))
+
+	b.Record(peer.URL(), nil)
+	_, err = processor.ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	assert.Nil(t, err, "Got error %s", err)
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(threshold int, openInterval time.Duration) (*Breaker, *testClock) {
+	clock := &testClock{now: time.Now()}
+	b := New(threshold, openInterval)
+	b.now = func() time.Time { return clock.now }
+	return b, clock
+}
@@ -40,6 +40,8 @@ func DefaultMockConfig(mockCtrl *gomock.Controller) *MockConfig {
 	config.EXPECT().TLSCACertPool().Return(CertPool, nil).AnyTimes()
 	config.EXPECT().TimeoutOrDefault(core.EndorserConnection).Return(time.Second * 5).AnyTimes()
 	config.EXPECT().TLSClientCerts().Return([]tls.Certificate{TLSCert}, nil).AnyTimes()
+	config.EXPECT().NetworkConfig().Return(&core.NetworkConfig{}, nil).AnyTimes()
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil).AnyTimes()
 
 	return config
 }
@@ -53,6 +55,8 @@ func BadTLSClientMockConfig(mockCtrl *gomock.Controller) *MockConfig {
 	config.EXPECT().TLSCACertPool().Return(CertPool, nil).AnyTimes()
 	config.EXPECT().TimeoutOrDefault(core.EndorserConnection).Return(time.Second * 5).AnyTimes()
 	config.EXPECT().TLSClientCerts().Return(nil, errors.Errorf(ErrorMessage)).AnyTimes()
+	config.EXPECT().NetworkConfig().Return(&core.NetworkConfig{}, nil).AnyTimes()
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil).AnyTimes()
 
 	return config
 }
@@ -102,6 +102,19 @@ func (mr *MockConfigMockRecorder) CAConfig(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CAConfig", reflect.TypeOf((*MockConfig)(nil).CAConfig), arg0)
 }
 
+// CAConfigs mocks base method
+func (m *MockConfig) CAConfigs(arg0 string) ([]core.CAConfig, error) {
+	ret := m.ctrl.Call(m, "CAConfigs", arg0)
+	ret0, _ := ret[0].([]core.CAConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CAConfigs indicates an expected call of CAConfigs
+func (mr *MockConfigMockRecorder) CAConfigs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CAConfigs", reflect.TypeOf((*MockConfig)(nil).CAConfigs), arg0)
+}
+
 // CAKeyStorePath mocks base method
 func (m *MockConfig) CAKeyStorePath() string {
 	ret := m.ctrl.Call(m, "CAKeyStorePath")
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package core
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 )
 
@@ -33,6 +35,16 @@ type ClientConfig struct {
 	TLS             TLSType
 	TLSCerts        MutualTLSConfig
 	CredentialStore CredentialStoreType
+	BCCSP           BCCSPType
+	// Proxy is the proxy of the connections to the endpoints that do not configure their own
+	Proxy ProxyConfig
+}
+
+// ProxyConfig configures the proxy through which connections to an endpoint are established
+type ProxyConfig struct {
+	// URL of the proxy: http://[user:password@]host:port for an HTTP CONNECT proxy with optional basic
+	// authentication, or socks5://[user:password@]host:port for a SOCKS5 proxy
+	URL string
 }
 
 // LoggingType defines the level of logging
@@ -57,6 +69,43 @@ type CredentialStoreType struct {
 		Path string
 	}
 	Wallet string
+	// Type selects the store of certificates and keys: empty for files, "wallet" for an encrypted wallet at Path
+	Type string
+	// PassphraseEnv is the environment variable holding the passphrase of the wallet
+	PassphraseEnv string
+}
+
+// BCCSPType defines the settings of security providers that are not exposed by Config
+type BCCSPType struct {
+	Security struct {
+		// Remote configures the signing service of the REMOTE security provider
+		Remote RemoteSignerConfig
+		// PKCS11 configures the sessions of the PKCS11 security provider
+		PKCS11 PKCS11Config
+		// Options are the settings of security providers registered by applications
+		Options map[string]interface{}
+	}
+}
+
+// PKCS11Config defines the session pool of the PKCS11 security provider
+type PKCS11Config struct {
+	// SessionPoolSize is the maximum number of sessions, and therefore of concurrent signatures; 10 by default
+	SessionPoolSize int
+	// HealthCheckInterval is the idle time after which a pooled session is checked before it is used again;
+	// sessions are checked every time they are used if not set
+	HealthCheckInterval time.Duration
+}
+
+// RemoteSignerConfig defines the connection to the signing service of the REMOTE security provider
+type RemoteSignerConfig struct {
+	// URL of the signing service; TLS is used with the grpcs scheme
+	URL string
+	// TLSCACerts are the root certificates of the signing service's TLS certificate
+	TLSCACerts endpoint.TLSConfig
+	// Client key and cert for mutual TLS with the signing service
+	Client TLSKeyPair
+	// Timeout of requests to the signing service
+	Timeout time.Duration
 }
 
 // ChannelConfig provides the definition of channels for the network
@@ -68,6 +117,48 @@ type ChannelConfig struct {
 	Peers map[string]PeerChannelConfig
 	// Chaincodes list of services
 	Chaincodes []string
+	// Policies defines the client side policies of the channel
+	Policies ChannelPolicies
+}
+
+// ChannelPolicies defines the client side policies of a channel
+type ChannelPolicies struct {
+	// OrdererSelection defines how the orderer a transaction is broadcast to is selected
+	OrdererSelection OrdererSelectionPolicy
+	// CircuitBreaker defines when requests stop being sent to the peers and orderers of the channel
+	CircuitBreaker CircuitBreakerPolicy
+}
+
+// OrdererSelectionStrategy defines the order in which orderers are tried
+type OrdererSelectionStrategy string
+
+const (
+	// FirstAvailable tries the orderers in the order they are listed in the channel configuration
+	FirstAvailable OrdererSelectionStrategy = "FirstAvailable"
+	// RoundRobin starts with the next orderer on each broadcast
+	RoundRobin OrdererSelectionStrategy = "RoundRobin"
+	// Random tries the orderers in random order (default)
+	Random OrdererSelectionStrategy = "Random"
+)
+
+// OrdererSelectionPolicy defines the orderer selection strategy of a channel
+type OrdererSelectionPolicy struct {
+	// Strategy is one of FirstAvailable, RoundRobin or Random
+	Strategy OrdererSelectionStrategy
+	// RetryAttempts is the number of times the orderers are tried again
+	// if they report that the service is unavailable.
+	// Defaults to 3; a negative value disables retries
+	RetryAttempts int
+}
+
+// CircuitBreakerPolicy defines when requests stop being sent to an endpoint that keeps failing
+type CircuitBreakerPolicy struct {
+	// Threshold is the number of consecutive failures after which no requests are sent to the endpoint.
+	// Default: 3
+	Threshold int
+	// OpenInterval is how long no requests are sent to the endpoint before a single probe request is allowed.
+	// Defaults to the discovery greylist expiry
+	OpenInterval time.Duration
 }
 
 // PeerChannelConfig defines the peer capabilities
@@ -99,6 +190,17 @@ type OrganizationConfig struct {
 	CertificateAuthorities []string
 	AdminPrivateKey        endpoint.TLSConfig
 	SignedCert             endpoint.TLSConfig
+	// UserKeys reference the private keys of users held by a hardware security module
+	UserKeys map[string]UserKeyConfig
+	// TLSClientCert is the TLS client certificate of the connections to the peers of the organization
+	TLSClientCert TLSClientCertConfig
+}
+
+// UserKeyConfig references the private key of a user held by a hardware security module,
+// by its label (CKA_LABEL) or its hex encoded identifier (CKA_ID)
+type UserKeyConfig struct {
+	Label string
+	ID    string
 }
 
 // OrdererConfig defines an orderer configuration
@@ -106,6 +208,10 @@ type OrdererConfig struct {
 	URL         string
 	GRPCOptions map[string]interface{}
 	TLSCACerts  endpoint.TLSConfig
+	// TLSClientCert is the TLS client certificate of the connections to the orderer
+	TLSClientCert TLSClientCertConfig
+	// Proxy is the proxy of the connections to the orderer, which overrides the proxy of the client config
+	Proxy ProxyConfig
 }
 
 // PeerConfig defines a peer configuration
@@ -114,6 +220,11 @@ type PeerConfig struct {
 	EventURL    string
 	GRPCOptions map[string]interface{}
 	TLSCACerts  endpoint.TLSConfig
+	// TLSClientCert is the TLS client certificate of the connections to the peer,
+	// which overrides the certificate of the peer's organization
+	TLSClientCert TLSClientCertConfig
+	// Proxy is the proxy of the connections to the peer, which overrides the proxy of the client config
+	Proxy ProxyConfig
 }
 
 // CAConfig defines a CA configuration
@@ -123,6 +234,8 @@ type CAConfig struct {
 	TLSCACerts  MutualTLSConfig
 	Registrar   EnrollCredentials
 	CAName      string
+	// Proxy is the proxy of the connections to the CA, which overrides the proxy of the client config
+	Proxy ProxyConfig
 }
 
 // EnrollCredentials holds credentials used for enrollment
@@ -147,6 +260,21 @@ type TLSKeyPair struct {
 	Cert endpoint.TLSConfig
 }
 
+// TLSClientCertConfig selects the TLS client certificate of mutual TLS connections: a key pair,
+// or the enrollment certificate and private key of the identity on whose behalf connections are made.
+// Connections use the client certificate of client.tlsCerts if neither is configured.
+type TLSClientCertConfig struct {
+	Key  endpoint.TLSConfig
+	Cert endpoint.TLSConfig
+	// FromIdentity derives the client certificate from the calling identity
+	FromIdentity bool
+}
+
+// IsSet returns true if the config selects a TLS client certificate
+func (c TLSClientCertConfig) IsSet() bool {
+	return c.FromIdentity || c.Cert.Pem != "" || c.Cert.Path != ""
+}
+
 // MatchConfig contains match pattern and substitution pattern
 // for pattern matching of network configured hostnames with static config
 type MatchConfig struct {
@@ -25,3 +25,14 @@ type SignedEnvelope struct {
 	Payload   []byte
 	Signature []byte
 }
+
+// OrdererSelector determines the order in which orderers are tried when broadcasting
+// and keeps track of the orderers that failed
+type OrdererSelector interface {
+	// Select returns the orderers in the order they should be tried
+	Select(orderers []Orderer) []Orderer
+	// Failed reports that broadcasting to the orderer failed with the given error
+	Failed(orderer Orderer, err error)
+	// Succeeded reports that broadcasting to the orderer succeeded
+	Succeeded(orderer Orderer)
+}
@@ -19,7 +19,7 @@ type ProposalProcessor interface {
 
 // ProposalSender provides the ability for a transaction proposal to be created and sent.
 type ProposalSender interface {
-	CreateTransactionHeader() (TransactionHeader, error)
+	CreateTransactionHeader(opts ...TxnHeaderOpt) (TransactionHeader, error)
 	SendTransactionProposal(*TransactionProposal, []ProposalProcessor) ([]*TransactionProposalResponse, error)
 }
 
@@ -40,6 +40,14 @@ type TransactionHeader interface {
 	ChannelID() string
 }
 
+// TxnHeaderOptions contains options for creating a Transaction Header
+type TxnHeaderOptions struct {
+	Nonce []byte
+}
+
+// TxnHeaderOpt is a Transaction Header option
+type TxnHeaderOpt func(*TxnHeaderOptions)
+
 // ChaincodeInvokeRequest contains the parameters for sending a transaction proposal.
 type ChaincodeInvokeRequest struct {
 	ChaincodeID  string
@@ -24,6 +24,13 @@ type Sender interface {
 	SendTransaction(tx *Transaction) (*TransactionResponse, error)
 }
 
+// EnvelopeSender provides the ability for the signed envelope of a transaction to be
+// created and broadcast in separate steps, so that the envelope can be persisted before it is sent.
+type EnvelopeSender interface {
+	CreateTransactionEnvelope(tx *Transaction) (*SignedEnvelope, error)
+	SendEnvelope(envelope *SignedEnvelope) (*TransactionResponse, error)
+}
+
 // The Transaction object created from an endorsed proposal.
 type Transaction struct {
 	Proposal    *TransactionProposal
@@ -44,12 +44,20 @@ type UserData struct {
 	Name                  string
 	MspID                 string
 	EnrollmentCertificate []byte
+	// Metadata is optional information about the user
+	Metadata map[string]string
 }
 
 // UserStore is responsible for UserData persistence
 type UserStore interface {
 	Store(*UserData) error
 	Load(UserIdentifier) (*UserData, error)
+	Delete(UserIdentifier) error
+}
+
+// UserLister is implemented by user stores that can enumerate the users they store
+type UserLister interface {
+	List() ([]*UserData, error)
 }
 
 // UserIdentifier is the User's unique identifier
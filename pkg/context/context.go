@@ -231,6 +231,8 @@ type reqContextKey string
 var reqContextCommManager = reqContextKey("commManager")
 var reqContextTimeout = reqContextKey("timeout")
 var reqContextClient = reqContextKey("clientContext")
+var reqContextCredential = reqContextKey("credential")
+var reqContextOperation = reqContextKey("operation")
 
 //WithTimeoutType sets timeout by type defined in config to request context
 func WithTimeoutType(timeoutType core.TimeoutType) ReqContextOptions {
@@ -253,6 +255,13 @@ func WithReqContext(context reqContext.Context) ReqContextOptions {
 	}
 }
 
+//WithOperation sets the SDK operation identifying the GRPC calls made with the request context
+func WithOperation(operation string) ReqContextOptions {
+	return func(ctx *requestContextOpts) {
+		ctx.operation = operation
+	}
+}
+
 //ReqContextOptions parameter for creating requestContext
 type ReqContextOptions func(opts *requestContextOpts)
 
@@ -260,6 +269,7 @@ type requestContextOpts struct {
 	timeoutType   core.TimeoutType
 	timeout       time.Duration
 	parentContext reqContext.Context
+	operation     string
 }
 
 // NewRequest creates a request-scoped context.
@@ -285,6 +295,9 @@ func NewRequest(client context.Client, options ...ReqContextOptions) (reqContext
 	}
 	ctx := reqContext.WithValue(parentContext, reqContextCommManager, client.InfraProvider().CommManager())
 	ctx = reqContext.WithValue(ctx, reqContextClient, client)
+	if reqCtxOpts.operation != "" {
+		ctx = RequestWithOperation(ctx, reqCtxOpts.operation)
+	}
 	ctx, cancel := reqContext.WithTimeout(ctx, timeout)
 
 	return ctx, cancel
@@ -301,3 +314,28 @@ func RequestClientContext(ctx reqContext.Context) (context.Client, bool) {
 	clientContext, ok := ctx.Value(reqContextClient).(context.Client)
 	return clientContext, ok
 }
+
+// RequestWithCredential returns a copy of the request-scoped context whose connections are established with the
+// credential identified by the given key, so that the comm manager does not share them with connections to the
+// same target established with other credentials.
+func RequestWithCredential(ctx reqContext.Context, credential string) reqContext.Context {
+	return reqContext.WithValue(ctx, reqContextCredential, credential)
+}
+
+// RequestCredential extracts the key of the credential of connections from the request-scoped context.
+func RequestCredential(ctx reqContext.Context) (string, bool) {
+	credential, ok := ctx.Value(reqContextCredential).(string)
+	return credential, ok
+}
+
+// RequestWithOperation returns a copy of the request-scoped context identifying the SDK operation
+// of the GRPC calls made with it, which is sent to the servers in the metadata of the calls.
+func RequestWithOperation(ctx reqContext.Context, operation string) reqContext.Context {
+	return reqContext.WithValue(ctx, reqContextOperation, operation)
+}
+
+// RequestOperation extracts the SDK operation from the request-scoped context.
+func RequestOperation(ctx reqContext.Context) (string, bool) {
+	operation, ok := ctx.Value(reqContextOperation).(string)
+	return operation, ok
+}
@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+
+	"github.com/golang/protobuf/proto"
+	cutil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
+)
+
+// ClientCertsProvider returns the TLS client certificates of connections
+type ClientCertsProvider func() ([]tls.Certificate, error)
+
+// PeerTLSClientCert returns the TLS client certificate config of the peer entry,
+// or else the config of the organization with the given MSP ID
+func PeerTLSClientCert(config core.Config, peerCfg *core.PeerConfig, mspID string) (core.TLSClientCertConfig, error) {
+	if peerCfg != nil && peerCfg.TLSClientCert.IsSet() {
+		return peerCfg.TLSClientCert, nil
+	}
+	if mspID == "" {
+		return core.TLSClientCertConfig{}, nil
+	}
+
+	networkConfig, err := config.NetworkConfig()
+	if err != nil {
+		return core.TLSClientCertConfig{}, errors.WithMessage(err, "unable to load network config")
+	}
+	if networkConfig == nil {
+		return core.TLSClientCertConfig{}, nil
+	}
+	for _, org := range networkConfig.Organizations {
+		if org.MspID == mspID {
+			return org.TLSClientCert, nil
+		}
+	}
+	return core.TLSClientCertConfig{}, nil
+}
+
+// ClientCerts returns the provider of the TLS client certificates selected by the TLS client certificate config
+// of a target, and the key identifying the certificates, which is empty if the config selects none and the
+// client certificates of the config are used. The certificate of the identity is used if the config derives
+// it from the calling identity.
+// Key pairs loaded from files are reloaded by the TLS credential source of the config, if any.
+func ClientCerts(config core.Config, certConfig core.TLSClientCertConfig, identity msp.Identity, cs core.CryptoSuite) (ClientCertsProvider, string, error) {
+	if !certConfig.IsSet() {
+		return config.TLSClientCerts, "", nil
+	}
+	if cs == nil {
+		cs = cryptosuite.GetDefault()
+	}
+
+	if certConfig.FromIdentity {
+		cert, err := IdentityKeyPair(identity, cs)
+		if err != nil {
+			return nil, "", err
+		}
+		certs := []tls.Certificate{cert}
+		return func() ([]tls.Certificate, error) { return certs, nil }, hex.EncodeToString(ClientCertHash(certs)), nil
+	}
+
+	load := func() (tls.Certificate, error) {
+		return TLSKeyPair(certConfig.Cert, certConfig.Key, cs)
+	}
+	var provider ClientCertsProvider
+	if p, ok := config.(TLSCredentialSourceProvider); ok && p.TLSCredentialSource() != nil {
+		// The key pair is reloaded with the other TLS credentials of the source
+		var err error
+		provider, err = p.TLSCredentialSource().KeyPair(certConfig.Cert.Path, certConfig.Key.Path, load)
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		cert, err := load()
+		if err != nil {
+			return nil, "", err
+		}
+		certs := []tls.Certificate{cert}
+		provider = func() ([]tls.Certificate, error) { return certs, nil }
+	}
+
+	// The credential key identifies the key pair by its certificate when it is first loaded
+	certs, err := provider()
+	if err != nil {
+		return nil, "", err
+	}
+	return provider, hex.EncodeToString(ClientCertHash(certs)), nil
+}
+
+// TLSKeyPair loads the TLS client certificate, whose private key is retrieved from the cryptosuite,
+// or else loaded from the key config
+func TLSKeyPair(certConfig, keyConfig endpoint.TLSConfig, cs core.CryptoSuite) (tls.Certificate, error) {
+	cb, err := certConfig.Bytes()
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "failed to load tls client cert")
+	}
+	if len(cb) == 0 {
+		return tls.Certificate{}, errors.New("tls client cert is not configured")
+	}
+
+	pk, err := cryptoutil.GetPrivateKeyFromCert(cb, cs)
+	if err == nil && pk != nil {
+		// private key was retrieved from cert
+		return cryptoutil.X509KeyPair(cb, pk, cs)
+	}
+
+	logger.Debugf("Reading pk from config, unable to retrieve from cert: %s", err)
+	kb, err := keyConfig.Bytes()
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "Failed to load key from file path '%s'", keyConfig.Path)
+	}
+
+	// load the key/cert pair from []byte
+	cert, err := tls.X509KeyPair(cb, kb)
+	if err != nil {
+		return tls.Certificate{}, errors.Errorf("Error loading cert/key pair as TLS client credentials: %v", err)
+	}
+	return cert, nil
+}
+
+// IdentityKeyPair returns the TLS client certificate made of the enrollment certificate and the private key of the identity
+func IdentityKeyPair(identity msp.Identity, cs core.CryptoSuite) (tls.Certificate, error) {
+	if identity == nil {
+		return tls.Certificate{}, errors.New("identity is required for a TLS client certificate derived from the calling identity")
+	}
+
+	serializedID, err := identity.SerializedIdentity()
+	if err != nil {
+		return tls.Certificate{}, errors.WithMessage(err, "failed to get serialized identity")
+	}
+	sID := &mb.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedID, sID); err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "unmarshal serialized identity failed")
+	}
+
+	cert, err := cryptoutil.X509KeyPair(sID.IdBytes, identity.PrivateKey(), cs)
+	if err != nil {
+		return tls.Certificate{}, errors.WithMessage(err, "failed to create TLS client certificate from identity")
+	}
+	return cert, nil
+}
+
+// ClientCertHash returns the SHA256 hash of the first TLS client certificate, nil without certificate
+func ClientCertHash(certs []tls.Certificate) []byte {
+	if len(certs) == 0 || len(certs[0].Certificate) == 0 {
+		return nil
+	}
+	return cutil.ComputeSHA256(certs[0].Certificate[0])
+}
@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerTLSClientCert(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	orgCert := core.TLSClientCertConfig{Cert: endpoint.TLSConfig{Path: "org1.pem"}}
+	peerCert := core.TLSClientCertConfig{FromIdentity: true}
+	config := mock_core.NewMockConfig(mockCtrl)
+	config.EXPECT().NetworkConfig().Return(&core.NetworkConfig{
+		Organizations: map[string]core.OrganizationConfig{
+			"org1": {MspID: "Org1MSP", TLSClientCert: orgCert},
+			"org2": {MspID: "Org2MSP"},
+		},
+	}, nil).AnyTimes()
+
+	certConfig, err := PeerTLSClientCert(config, &core.PeerConfig{TLSClientCert: peerCert}, "Org1MSP")
+	assert.NoError(t, err)
+	assert.Equal(t, peerCert, certConfig, "expected the certificate of the peer entry to override the organization's")
+
+	certConfig, err = PeerTLSClientCert(config, &core.PeerConfig{}, "Org1MSP")
+	assert.NoError(t, err)
+	assert.Equal(t, orgCert, certConfig, "expected the certificate of the organization")
+
+	certConfig, err = PeerTLSClientCert(config, &core.PeerConfig{}, "Org2MSP")
+	assert.NoError(t, err)
+	assert.False(t, certConfig.IsSet(), "expected no certificate for an organization without certificate")
+
+	certConfig, err = PeerTLSClientCert(config, nil, "")
+	assert.NoError(t, err)
+	assert.False(t, certConfig.IsSet(), "expected no certificate without peer and organization")
+}
+
+func TestClientCerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ca := newTestCertificate(t, "ca", nil)
+	defaultCert := newTestCertificate(t, "default", ca)
+	orgCert := newTestCertificate(t, "org1", ca)
+	config := mock_core.NewMockConfig(mockCtrl)
+	config.EXPECT().TLSClientCerts().Return([]tls.Certificate{defaultCert.tlsCert}, nil).AnyTimes()
+
+	provider, credential, err := ClientCerts(config, core.TLSClientCertConfig{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get client certs: %s", err)
+	}
+	assert.Empty(t, credential, "expected no credential key for the client certificates of the config")
+	assertClientCert(t, defaultCert.cert, provider)
+
+	keyPair := core.TLSClientCertConfig{
+		Cert: endpoint.TLSConfig{Pem: string(orgCert.certPEM)},
+		Key:  endpoint.TLSConfig{Pem: string(orgCert.keyPEM)},
+	}
+	provider, credential, err = ClientCerts(config, keyPair, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get client certs: %s", err)
+	}
+	assertClientCert(t, orgCert.cert, provider)
+	hash := sha256.Sum256(orgCert.cert.Raw)
+	assert.Equal(t, hex.EncodeToString(hash[:]), credential, "expected the hash of the certificate as credential key")
+
+	keyPair.Key = endpoint.TLSConfig{Pem: string(defaultCert.keyPEM)}
+	_, _, err = ClientCerts(config, keyPair, nil, nil)
+	assert.Error(t, err, "expected error for a key that does not match the certificate")
+}
+
+func TestClientCertsReloadedBySource(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dir, err := ioutil.TempDir("", "tlsfiles")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCertificate(t, "ca", nil)
+	orgCert := newTestCertificate(t, "org1", ca)
+	keyPair := core.TLSClientCertConfig{
+		Cert: endpoint.TLSConfig{Path: filepath.Join(dir, "org1.pem")},
+		Key:  endpoint.TLSConfig{Path: filepath.Join(dir, "org1-key.pem")},
+	}
+	orgCert.write(t, keyPair.Cert.Path, keyPair.Key.Path)
+	source := NewTLSCredentialSource(nil, nil)
+	config := &sourceConfig{Config: mock_core.NewMockConfig(mockCtrl), source: source}
+
+	provider, credential, err := ClientCerts(config, keyPair, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get client certs: %s", err)
+	}
+	assertClientCert(t, orgCert.cert, provider)
+	hash := sha256.Sum256(orgCert.cert.Raw)
+	assert.Equal(t, hex.EncodeToString(hash[:]), credential)
+
+	// The key pair is reloaded by the credential source
+	rotated := newTestCertificate(t, "org1", ca)
+	rotated.write(t, keyPair.Cert.Path, keyPair.Key.Path)
+	source.Update(nil, nil)
+	assertClientCert(t, rotated.cert, provider)
+}
+
+func TestClientCertsFromIdentity(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ca := newTestCertificate(t, "ca", nil)
+	user1 := newTestCertificate(t, "user1", ca)
+	user2 := newTestCertificate(t, "user2", ca)
+	config := mock_core.NewMockConfig(mockCtrl)
+	fromIdentity := core.TLSClientCertConfig{FromIdentity: true}
+
+	_, _, err := ClientCerts(config, fromIdentity, nil, nil)
+	assert.Error(t, err, "expected error without identity")
+
+	provider1, credential1, err := ClientCerts(config, fromIdentity, newTestIdentity(t, user1), nil)
+	if err != nil {
+		t.Fatalf("Failed to get client certs: %s", err)
+	}
+	assertClientCert(t, user1.cert, provider1)
+
+	provider2, credential2, err := ClientCerts(config, fromIdentity, newTestIdentity(t, user2), nil)
+	if err != nil {
+		t.Fatalf("Failed to get client certs: %s", err)
+	}
+	assertClientCert(t, user2.cert, provider2)
+	assert.NotEqual(t, credential1, credential2, "expected different credential keys for different identities")
+}
+
+func TestTLSConfigWithClientCerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ca := newTestCertificate(t, "ca", nil)
+	client := newTestCertificate(t, "client", ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	config := mock_core.NewMockConfig(mockCtrl)
+	config.EXPECT().TLSCACertPool(gomock.Any()).Return(pool, nil).AnyTimes()
+
+	tlsConfig, err := TLSConfigWithClientCerts(nil, "peer0", config, func() ([]tls.Certificate, error) {
+		return []tls.Certificate{client.tlsCert}, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to get TLS config: %s", err)
+	}
+	assert.Equal(t, []tls.Certificate{client.tlsCert}, tlsConfig.Certificates)
+	assert.Equal(t, "peer0", tlsConfig.ServerName)
+}
+
+func assertClientCert(t *testing.T, expected *x509.Certificate, provider ClientCertsProvider) {
+	certs, err := provider()
+	if err != nil {
+		t.Fatalf("Failed to get client certs from provider: %s", err)
+	}
+	if len(certs) != 1 || len(certs[0].Certificate) == 0 {
+		t.Fatalf("Expected one client certificate but got %d", len(certs))
+	}
+	assert.Equal(t, expected.Raw, certs[0].Certificate[0])
+}
+
+// sourceConfig is a config with a TLS credential source
+type sourceConfig struct {
+	core.Config
+	source *TLSCredentialSource
+}
+
+func (c *sourceConfig) TLSCredentialSource() *TLSCredentialSource {
+	return c.source
+}
+
+type testIdentity struct {
+	serializedID []byte
+	key          core.Key
+}
+
+func newTestIdentity(t *testing.T, cert *testCertificate) *testIdentity {
+	serializedID, err := proto.Marshal(&mb.SerializedIdentity{Mspid: "Org1MSP", IdBytes: cert.certPEM})
+	if err != nil {
+		t.Fatalf("Failed to marshal serialized identity: %s", err)
+	}
+	return &testIdentity{serializedID: serializedID}
+}
+
+func (i *testIdentity) MspID() string {
+	return "Org1MSP"
+}
+
+func (i *testIdentity) SerializedIdentity() ([]byte, error) {
+	return i.serializedID, nil
+}
+
+func (i *testIdentity) PrivateKey() core.Key {
+	return i.key
+}
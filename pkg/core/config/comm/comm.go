@@ -7,18 +7,25 @@ SPDX-License-Identifier: Apache-2.0
 package comm
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
 
 	"crypto/x509"
 
-	cutil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
 )
 
 // TLSConfig returns the appropriate config for TLS including the root CAs,
 // certs for mutual TLS, and server host override. Works with certs loaded either from a path or embedded pem.
 func TLSConfig(cert *x509.Certificate, serverName string, config core.Config) (*tls.Config, error) {
+	return TLSConfigWithClientCerts(cert, serverName, config, config.TLSClientCerts)
+}
+
+// TLSConfigWithClientCerts returns the config for TLS like TLSConfig, with the certs for mutual TLS of the provider
+func TLSConfigWithClientCerts(cert *x509.Certificate, serverName string, config core.Config, clientCertsProvider ClientCertsProvider) (*tls.Config, error) {
 	certPool, err := config.TLSCACertPool()
 	if err != nil {
 		return nil, err
@@ -35,7 +42,7 @@ func TLSConfig(cert *x509.Certificate, serverName string, config core.Config) (*
 		return nil, err
 	}
 
-	clientCerts, err := config.TLSClientCerts()
+	clientCerts, err := clientCertsProvider()
 	if err != nil {
 		return nil, errors.Errorf("Error loading cert/key pair for TLS client credentials: %v", err)
 	}
@@ -46,15 +53,62 @@ func TLSConfig(cert *x509.Certificate, serverName string, config core.Config) (*
 // TLSCertHash is a utility method to calculate the SHA256 hash of the configured certificate (for usage in channel headers)
 func TLSCertHash(config core.Config) []byte {
 	certs, err := config.TLSClientCerts()
-	if err != nil || len(certs) == 0 {
+	if err != nil {
 		return nil
 	}
+	return ClientCertHash(certs)
+}
 
-	cert := certs[0]
-	if len(cert.Certificate) == 0 {
-		return nil
+// TransportCredentials returns GRPC transport credentials with the TLS config of TLSConfig. The TLS config is built
+// again for every handshake, so that new connections use the current TLS credentials of the config
+// even if the dial options were created before the credentials were updated.
+func TransportCredentials(cert *x509.Certificate, serverName string, config core.Config) (credentials.TransportCredentials, error) {
+	return TransportCredentialsWithClientCerts(cert, serverName, config, config.TLSClientCerts)
+}
+
+// TransportCredentialsWithClientCerts returns GRPC transport credentials like TransportCredentials,
+// with the certs for mutual TLS of the provider
+func TransportCredentialsWithClientCerts(cert *x509.Certificate, serverName string, config core.Config, clientCertsProvider ClientCertsProvider) (credentials.TransportCredentials, error) {
+	tlsConfig, err := TLSConfigWithClientCerts(cert, serverName, config, clientCertsProvider)
+	if err != nil {
+		return nil, err
 	}
+	return &transportCredentials{
+		TransportCredentials: credentials.NewTLS(tlsConfig),
+		cert:                 cert,
+		serverName:           serverName,
+		config:               config,
+		clientCerts:          clientCertsProvider,
+	}, nil
+}
+
+type transportCredentials struct {
+	credentials.TransportCredentials
+	cert        *x509.Certificate
+	serverName  string
+	config      core.Config
+	clientCerts ClientCertsProvider
+}
+
+func (c *transportCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	tlsConfig, err := TLSConfigWithClientCerts(c.cert, c.serverName, c.config, c.clientCerts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentials.NewTLS(tlsConfig).ClientHandshake(ctx, authority, rawConn)
+}
+
+func (c *transportCredentials) Clone() credentials.TransportCredentials {
+	return &transportCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		cert:                 c.cert,
+		serverName:           c.serverName,
+		config:               c.config,
+		clientCerts:          c.clientCerts,
+	}
+}
 
-	h := cutil.ComputeSHA256(cert.Certificate[0])
-	return h
+func (c *transportCredentials) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return c.TransportCredentials.OverrideServerName(serverName)
 }
@@ -0,0 +1,395 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+// TLSCredentialSourceProvider is implemented by configs whose TLS credentials are provided by a credential source
+type TLSCredentialSourceProvider interface {
+	TLSCredentialSource() *TLSCredentialSource
+}
+
+// TLSCredentialSource holds TLS client certificates and CA certificates that may change while the SDK is running,
+// either because they are pushed with Update or because the files they are loaded from are watched.
+// The source also reloads the key pairs of the TLS client certificates configured per organization, peer
+// or orderer (see KeyPair). Subscribers are notified of every change, so that connections established
+// with the previous credentials can be drained.
+type TLSCredentialSource struct {
+	mutex       sync.RWMutex
+	clientCerts []tls.Certificate
+	caCerts     []*x509.Certificate
+	keyPairs    map[string]*keyPair
+	subscribers map[int]func()
+	nextID      int
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewTLSCredentialSource returns a credential source with the given client certificates and CA certificates,
+// which are updated with Update
+func NewTLSCredentialSource(clientCerts []tls.Certificate, caCerts []*x509.Certificate) *TLSCredentialSource {
+	return &TLSCredentialSource{
+		clientCerts: clientCerts,
+		caCerts:     caCerts,
+		keyPairs:    make(map[string]*keyPair),
+		subscribers: make(map[int]func()),
+		closed:      make(chan struct{}),
+	}
+}
+
+// TLSFiles are the PEM files of the TLS credentials; files may be left empty
+type TLSFiles struct {
+	// CertFile and KeyFile are the client certificate and its private key
+	CertFile string
+	KeyFile  string
+	// CAFiles contain the CA certificates, several per file if needed
+	CAFiles []string
+}
+
+// WatchTLSFiles returns a credential source loaded from the files, which are checked for changes at the given interval
+// until Close is called. Credentials that fail to load, like a certificate that does not match the key
+// while both files are being replaced, are retried at the next interval.
+func WatchTLSFiles(files TLSFiles, interval time.Duration) (*TLSCredentialSource, error) {
+	if interval <= 0 {
+		return nil, errors.New("watch interval must be positive")
+	}
+
+	w := &fileWatcher{files: files}
+	clientCerts, caCerts, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	s := NewTLSCredentialSource(clientCerts, caCerts)
+	go s.watch(w, interval)
+
+	return s, nil
+}
+
+// ClientCerts returns the current client certificates
+func (s *TLSCredentialSource) ClientCerts() []tls.Certificate {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.clientCerts
+}
+
+// CACerts returns the current CA certificates
+func (s *TLSCredentialSource) CACerts() []*x509.Certificate {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.caCerts
+}
+
+// Update replaces the credentials, reloads the key pairs and notifies the subscribers
+func (s *TLSCredentialSource) Update(clientCerts []tls.Certificate, caCerts []*x509.Certificate) {
+	s.mutex.Lock()
+	s.clientCerts = clientCerts
+	s.caCerts = caCerts
+	s.mutex.Unlock()
+
+	for _, kp := range s.currentKeyPairs() {
+		if err := kp.reload(); err != nil {
+			logger.Warnf("Reloading TLS client key pair failed, keeping the current one: %s", err)
+		}
+	}
+	s.notify()
+}
+
+// KeyPair returns the provider of the TLS client certificate loaded by the function from the certificate and
+// key files, which is loaded again whenever the credentials of the source are updated or, if the source watches
+// files, when the files change. Key pairs are loaded once per certificate and key file; a key pair that is not
+// loaded from files, like an embedded PEM, is not reloaded.
+func (s *TLSCredentialSource) KeyPair(certFile, keyFile string, load func() (tls.Certificate, error)) (ClientCertsProvider, error) {
+	if certFile == "" && keyFile == "" {
+		cert, err := load()
+		if err != nil {
+			return nil, err
+		}
+		certs := []tls.Certificate{cert}
+		return func() ([]tls.Certificate, error) { return certs, nil }, nil
+	}
+
+	id := certFile + "\x00" + keyFile
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kp, ok := s.keyPairs[id]
+	if !ok {
+		kp = &keyPair{watcher: &fileWatcher{files: TLSFiles{CertFile: certFile, KeyFile: keyFile}}, load: load}
+		if err := kp.reload(); err != nil {
+			return nil, err
+		}
+		s.keyPairs[id] = kp
+	}
+	return kp.clientCerts, nil
+}
+
+func (s *TLSCredentialSource) currentKeyPairs() []*keyPair {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keyPairs := make([]*keyPair, 0, len(s.keyPairs))
+	for _, kp := range s.keyPairs {
+		keyPairs = append(keyPairs, kp)
+	}
+	return keyPairs
+}
+
+// notify calls the subscribers in the order of subscription
+func (s *TLSCredentialSource) notify() {
+	s.mutex.RLock()
+	subscribers := make([]func(), 0, len(s.subscribers))
+	for id := 0; id < s.nextID; id++ {
+		if fn, ok := s.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	s.mutex.RUnlock()
+
+	logger.Debugf("TLS credentials updated, notifying %d subscribers", len(subscribers))
+	for _, fn := range subscribers {
+		fn()
+	}
+}
+
+// Subscribe registers a function called after every update, in the order of subscription,
+// and returns a function that cancels the subscription
+func (s *TLSCredentialSource) Subscribe(fn func()) func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = fn
+
+	return func() {
+		s.mutex.Lock()
+		delete(s.subscribers, id)
+		s.mutex.Unlock()
+	}
+}
+
+// Close stops watching the files of the credentials
+func (s *TLSCredentialSource) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
+
+func (s *TLSCredentialSource) watch(w *fileWatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+			updated := s.reloadFiles(w)
+			if s.reloadKeyPairs() || updated {
+				s.notify()
+			}
+		}
+	}
+}
+
+// reloadFiles reloads the credentials from the files if they have changed, and returns true if they were reloaded
+func (s *TLSCredentialSource) reloadFiles(w *fileWatcher) bool {
+	changed, err := w.changed()
+	if err != nil {
+		logger.Warnf("Checking TLS credential files failed: %s", err)
+		return false
+	}
+	if !changed {
+		return false
+	}
+	clientCerts, caCerts, err := w.load()
+	if err != nil {
+		logger.Warnf("Reloading TLS credentials failed, keeping the current ones: %s", err)
+		return false
+	}
+	logger.Infof("TLS credential files have changed, reloading TLS credentials")
+
+	s.mutex.Lock()
+	s.clientCerts = clientCerts
+	s.caCerts = caCerts
+	s.mutex.Unlock()
+	return true
+}
+
+// reloadKeyPairs reloads the key pairs whose files have changed, and returns true if any was reloaded
+func (s *TLSCredentialSource) reloadKeyPairs() bool {
+	reloaded := false
+	for _, kp := range s.currentKeyPairs() {
+		changed, err := kp.watcher.changed()
+		if err != nil {
+			logger.Warnf("Checking TLS client key pair files failed: %s", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := kp.reload(); err != nil {
+			logger.Warnf("Reloading TLS client key pair failed, keeping the current one: %s", err)
+			continue
+		}
+		logger.Infof("TLS client key pair files %s and %s have changed, reloading the key pair", kp.watcher.files.CertFile, kp.watcher.files.KeyFile)
+		reloaded = true
+	}
+	return reloaded
+}
+
+// keyPair is a TLS client certificate loaded from a certificate file and a key file
+type keyPair struct {
+	watcher *fileWatcher
+	load    func() (tls.Certificate, error)
+	mutex   sync.RWMutex
+	certs   []tls.Certificate
+}
+
+func (kp *keyPair) clientCerts() ([]tls.Certificate, error) {
+	kp.mutex.RLock()
+	defer kp.mutex.RUnlock()
+
+	return kp.certs, nil
+}
+
+// reload loads the key pair and remembers the contents of its files
+func (kp *keyPair) reload() error {
+	contents, err := kp.watcher.read()
+	if err != nil {
+		return err
+	}
+	cert, err := kp.load()
+	if err != nil {
+		return err
+	}
+
+	kp.mutex.Lock()
+	defer kp.mutex.Unlock()
+
+	kp.certs = []tls.Certificate{cert}
+	kp.watcher.contents = contents
+	return nil
+}
+
+// fileWatcher detects changes of the contents of TLS credential files, which are replaced rather than
+// modified in place by tools like certificate managers
+type fileWatcher struct {
+	files    TLSFiles
+	contents [][]byte
+}
+
+func (w *fileWatcher) paths() []string {
+	var paths []string
+	for _, path := range append([]string{w.files.CertFile, w.files.KeyFile}, w.files.CAFiles...) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func (w *fileWatcher) read() ([][]byte, error) {
+	var contents [][]byte
+	for _, path := range w.paths() {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading TLS credential file %s failed", path)
+		}
+		contents = append(contents, b)
+	}
+	return contents, nil
+}
+
+func (w *fileWatcher) changed() (bool, error) {
+	contents, err := w.read()
+	if err != nil {
+		return false, err
+	}
+	if len(contents) != len(w.contents) {
+		return true, nil
+	}
+	for i := range contents {
+		if !bytes.Equal(contents[i], w.contents[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// load loads the credentials from the files and remembers their contents
+func (w *fileWatcher) load() ([]tls.Certificate, []*x509.Certificate, error) {
+	contents, err := w.read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var clientCerts []tls.Certificate
+	i := 0
+	if w.files.CertFile != "" || w.files.KeyFile != "" {
+		if w.files.CertFile == "" || w.files.KeyFile == "" {
+			return nil, nil, errors.New("both the client certificate and key files are required")
+		}
+		cert, err := tls.X509KeyPair(contents[0], contents[1])
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "loading TLS client certificate and key failed")
+		}
+		clientCerts = []tls.Certificate{cert}
+		i = 2
+	}
+
+	var caCerts []*x509.Certificate
+	for j, b := range contents[i:] {
+		certs, err := parseCerts(b)
+		if err != nil {
+			return nil, nil, errors.WithMessage(err, "loading CA certificates from "+w.files.CAFiles[j]+" failed")
+		}
+		caCerts = append(caCerts, certs...)
+	}
+
+	w.contents = contents
+	return clientCerts, caCerts, nil
+}
+
+// parseCerts parses the PEM encoded certificates
+func parseCerts(pemCerts []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for len(pemCerts) > 0 {
+		var block *pem.Block
+		block, pemCerts = pem.Decode(pemCerts)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing certificate failed")
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
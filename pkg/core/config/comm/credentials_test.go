@@ -0,0 +1,296 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSCredentialSourceUpdate(t *testing.T) {
+	ca := newTestCertificate(t, "ca", nil)
+	client := newTestCertificate(t, "client", ca)
+	source := NewTLSCredentialSource(nil, []*x509.Certificate{ca.cert})
+	assert.Empty(t, source.ClientCerts())
+	assert.Equal(t, []*x509.Certificate{ca.cert}, source.CACerts())
+
+	var notified []int
+	source.Subscribe(func() { notified = append(notified, 1) })
+	unsubscribe := source.Subscribe(func() { notified = append(notified, 2) })
+	source.Subscribe(func() { notified = append(notified, 3) })
+
+	source.Update([]tls.Certificate{client.tlsCert}, nil)
+	assert.Equal(t, []int{1, 2, 3}, notified, "expected subscribers to be notified in order")
+	assert.Equal(t, []tls.Certificate{client.tlsCert}, source.ClientCerts())
+	assert.Empty(t, source.CACerts())
+
+	unsubscribe()
+	notified = nil
+	source.Update(nil, nil)
+	assert.Equal(t, []int{1, 3}, notified, "expected no notification after unsubscribing")
+}
+
+func TestWatchTLSFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsfiles")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := TLSFiles{
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client-key.pem"),
+		CAFiles:  []string{filepath.Join(dir, "ca.pem")},
+	}
+	ca := newTestCertificate(t, "ca", nil)
+	client := newTestCertificate(t, "client", ca)
+	ca.write(t, files.CAFiles[0], "")
+	client.write(t, files.CertFile, files.KeyFile)
+
+	_, err = WatchTLSFiles(files, 0)
+	assert.Error(t, err, "expected error without interval")
+	_, err = WatchTLSFiles(TLSFiles{CertFile: files.CertFile}, time.Millisecond)
+	assert.Error(t, err, "expected error without key file")
+
+	source, err := WatchTLSFiles(files, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to watch TLS files: %s", err)
+	}
+	defer source.Close()
+	assert.Equal(t, client.tlsCert.Certificate, source.ClientCerts()[0].Certificate)
+	assert.Equal(t, ca.cert.Raw, source.CACerts()[0].Raw)
+
+	updated := make(chan struct{}, 10)
+	source.Subscribe(func() { updated <- struct{}{} })
+
+	// A certificate that does not match the key is not loaded
+	rotated := newTestCertificate(t, "rotated", ca)
+	rotated.write(t, files.CertFile, "")
+	select {
+	case <-updated:
+		t.Fatal("Expected mismatching certificate and key not to be loaded")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, client.tlsCert.Certificate, source.ClientCerts()[0].Certificate)
+
+	rotated.write(t, files.CertFile, files.KeyFile)
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected rotated certificate to be loaded")
+	}
+	assert.Equal(t, rotated.tlsCert.Certificate, source.ClientCerts()[0].Certificate)
+}
+
+func TestTLSCredentialSourceKeyPair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsfiles")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCertificate(t, "ca", nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	ca.write(t, caFile, "")
+	certFile, keyFile := filepath.Join(dir, "org1.pem"), filepath.Join(dir, "org1-key.pem")
+	org1 := newTestCertificate(t, "org1", ca)
+	org1.write(t, certFile, keyFile)
+	loads := 0
+	load := func() (tls.Certificate, error) {
+		loads++
+		return tls.LoadX509KeyPair(certFile, keyFile)
+	}
+
+	source, err := WatchTLSFiles(TLSFiles{CAFiles: []string{caFile}}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to watch TLS files: %s", err)
+	}
+	defer source.Close()
+	updated := make(chan struct{}, 10)
+	source.Subscribe(func() { updated <- struct{}{} })
+
+	provider, err := source.KeyPair(certFile, keyFile, load)
+	if err != nil {
+		t.Fatalf("Failed to get key pair: %s", err)
+	}
+	assertClientCert(t, org1.cert, provider)
+	_, err = source.KeyPair(certFile, keyFile, load)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loads, "expected the key pair to be loaded once")
+
+	// The key pair is reloaded when its files change
+	rotated := newTestCertificate(t, "org1", ca)
+	rotated.write(t, certFile, keyFile)
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected rotated key pair to be loaded")
+	}
+	assertClientCert(t, rotated.cert, provider)
+
+	// The key pair is reloaded when the credentials are updated
+	rotated = newTestCertificate(t, "org1", ca)
+	source.Close()
+	rotated.write(t, certFile, keyFile)
+	source.Update(nil, nil)
+	assertClientCert(t, rotated.cert, provider)
+
+	_, err = source.KeyPair(filepath.Join(dir, "missing.pem"), keyFile, load)
+	assert.Error(t, err, "expected error for missing certificate file")
+}
+
+func TestTransportCredentialsUseCurrentCredentials(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ca := newTestCertificate(t, "ca", nil)
+	server := newTestCertificate(t, "localhost", ca)
+	client := newTestCertificate(t, "client", ca)
+	rotated := newTestCertificate(t, "rotated", ca)
+	source := NewTLSCredentialSource([]tls.Certificate{client.tlsCert}, nil)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	config := mock_core.NewMockConfig(mockCtrl)
+	config.EXPECT().TLSCACertPool(gomock.Any()).Return(pool, nil).AnyTimes()
+	config.EXPECT().TLSClientCerts().DoAndReturn(func() ([]tls.Certificate, error) {
+		return source.ClientCerts(), nil
+	}).AnyTimes()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{server.tlsCert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	defer listener.Close()
+	clientCerts := make(chan *x509.Certificate, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if err := tlsConn.Handshake(); err == nil {
+				clientCerts <- tlsConn.ConnectionState().PeerCertificates[0]
+			}
+			conn.Close()
+		}
+	}()
+
+	creds, err := TransportCredentials(nil, "localhost", config)
+	if err != nil {
+		t.Fatalf("Failed to get transport credentials: %s", err)
+	}
+	handshake := func() *x509.Certificate {
+		rawConn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to dial: %s", err)
+		}
+		defer rawConn.Close()
+		if _, _, err := creds.ClientHandshake(context.Background(), "localhost", rawConn); err != nil {
+			t.Fatalf("Handshake failed: %s", err)
+		}
+		return <-clientCerts
+	}
+
+	assert.Equal(t, client.cert.Raw, handshake().Raw)
+	source.Update([]tls.Certificate{rotated.tlsCert}, nil)
+	assert.Equal(t, rotated.cert.Raw, handshake().Raw, "expected new handshakes to use the rotated certificate")
+}
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	tlsCert tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCertificate creates a certificate issued by the CA, or a self-signed CA certificate without CA
+func newTestCertificate(t *testing.T, name string, ca *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("Failed to generate serial number: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	parent, signer := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+	c := &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	c.tlsCert, err = tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("Failed to create key pair: %s", err)
+	}
+	return c
+}
+
+// write writes the certificate and the key to the files whose path is not empty
+func (c *testCertificate) write(t *testing.T, certFile, keyFile string) {
+	if certFile != "" {
+		if err := ioutil.WriteFile(certFile, c.certPEM, 0600); err != nil {
+			t.Fatalf("Failed to write certificate: %s", err)
+		}
+	}
+	if keyFile != "" {
+		if err := ioutil.WriteFile(keyFile, c.keyPEM, 0600); err != nil {
+			t.Fatalf("Failed to write key: %s", err)
+		}
+	}
+}
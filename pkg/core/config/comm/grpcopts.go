@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultMaxMsgSize is the default maximum size of the messages sent and received over GRPC (same as Fabric)
+	DefaultMaxMsgSize = 100 * 1024 * 1024
+
+	// minWindowSize is the minimum HTTP/2 window size, smaller window sizes are ignored by GRPC
+	minWindowSize = 64 * 1024
+)
+
+// keepaliveTimeWarning warns once per process that the keep-alive time option of other SDKs is ignored,
+// since the grpcOptions are parsed whenever a peer or orderer config is read
+var keepaliveTimeWarning sync.Once
+
+// grpcOptions holds the options of the grpcOptions of a peer or orderer that are applied to the GRPC dial options.
+// The TLS, keep-alive and fail-fast options are read by the peer and orderer.
+type grpcOptions struct {
+	maxRecvMsgSize        int
+	maxSendMsgSize        int
+	compression           string
+	initialWindowSize     int32
+	initialConnWindowSize int32
+	backoffMaxDelay       time.Duration
+	userAgent             string
+}
+
+// ValidateGRPCOptions returns an error if the grpcOptions of a peer or orderer
+// contain an unknown key or a value that can not be converted to the type of the option
+func ValidateGRPCOptions(options map[string]interface{}) error {
+	_, err := parseGRPCOptions(options)
+	return err
+}
+
+// GRPCDialOptions returns the dial options of the message sizes, compression, initial window sizes,
+// connect backoff and user agent configured in the grpcOptions of a peer or orderer. The maximum
+// message sizes default to DefaultMaxMsgSize. The connect backoff is configured by its maximum delay,
+// GRPC does not expose its base delay, multiplier and jitter.
+func GRPCDialOptions(options map[string]interface{}) ([]grpc.DialOption, error) {
+	opts, err := parseGRPCOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(opts.maxRecvMsgSize), grpc.MaxCallSendMsgSize(opts.maxSendMsgSize)),
+	}
+	if opts.compression == "gzip" {
+		dialOpts = append(dialOpts, grpc.WithCompressor(grpc.NewGZIPCompressor()), grpc.WithDecompressor(grpc.NewGZIPDecompressor()))
+	}
+	if opts.initialWindowSize > 0 {
+		dialOpts = append(dialOpts, grpc.WithInitialWindowSize(opts.initialWindowSize))
+	}
+	if opts.initialConnWindowSize > 0 {
+		dialOpts = append(dialOpts, grpc.WithInitialConnWindowSize(opts.initialConnWindowSize))
+	}
+	if opts.backoffMaxDelay > 0 {
+		dialOpts = append(dialOpts, grpc.WithBackoffMaxDelay(opts.backoffMaxDelay))
+	}
+	if opts.userAgent != "" {
+		dialOpts = append(dialOpts, grpc.WithUserAgent(opts.userAgent))
+	}
+	return dialOpts, nil
+}
+
+func parseGRPCOptions(options map[string]interface{}) (*grpcOptions, error) {
+	opts := &grpcOptions{
+		maxRecvMsgSize: DefaultMaxMsgSize,
+		maxSendMsgSize: DefaultMaxMsgSize,
+	}
+
+	for key, value := range options {
+		var err error
+		switch strings.ToLower(key) {
+		case "ssl-target-name-override":
+			_, err = cast.ToStringE(value)
+		case "fail-fast", "allow-insecure", "keep-alive-permit":
+			_, err = cast.ToBoolE(value)
+		case "keep-alive-time", "keep-alive-timeout":
+			_, err = cast.ToDurationE(value)
+		case "max-recv-message-size":
+			opts.maxRecvMsgSize, err = positiveInt(value)
+		case "max-send-message-size":
+			opts.maxSendMsgSize, err = positiveInt(value)
+		case "grpc.max_receive_message_length", "grpc-max-receive-message-length":
+			opts.maxRecvMsgSize, err = legacyMsgSize(value)
+		case "grpc.max_send_message_length", "grpc-max-send-message-length":
+			opts.maxSendMsgSize, err = legacyMsgSize(value)
+		case "grpc.http2.keepalive_time":
+			// option of the connection profiles of other SDKs in milliseconds, not applied
+			if _, err = positiveInt(value); err == nil {
+				keepaliveTimeWarning.Do(func() {
+					logger.Warnf("grpc option [%s] is not supported, use keep-alive-time", key)
+				})
+			}
+		case "compression":
+			opts.compression, err = compression(value)
+		case "initial-window-size":
+			opts.initialWindowSize, err = windowSize(value)
+		case "initial-conn-window-size":
+			opts.initialConnWindowSize, err = windowSize(value)
+		case "backoff-max-delay":
+			opts.backoffMaxDelay, err = cast.ToDurationE(value)
+		case "user-agent":
+			opts.userAgent, err = cast.ToStringE(value)
+		default:
+			return nil, errors.Errorf("unknown grpc option [%s]", key)
+		}
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid value of grpc option ["+key+"]")
+		}
+	}
+	return opts, nil
+}
+
+func positiveInt(value interface{}) (int, error) {
+	i, err := cast.ToIntE(value)
+	if err != nil {
+		return 0, err
+	}
+	if i <= 0 || i > math.MaxInt32 {
+		return 0, errors.Errorf("%d is out of range [1, %d]", i, math.MaxInt32)
+	}
+	return i, nil
+}
+
+// legacyMsgSize returns the message size of the options of the connection profiles of other SDKs,
+// where -1 stands for the largest message size
+func legacyMsgSize(value interface{}) (int, error) {
+	if i, err := cast.ToIntE(value); err == nil && i == -1 {
+		return math.MaxInt32, nil
+	}
+	return positiveInt(value)
+}
+
+func windowSize(value interface{}) (int32, error) {
+	size, err := positiveInt(value)
+	if err != nil {
+		return 0, err
+	}
+	if size < minWindowSize {
+		return 0, errors.Errorf("window size %d is smaller than the minimum window size %d", size, minWindowSize)
+	}
+	return int32(size), nil
+}
+
+func compression(value interface{}) (string, error) {
+	c, err := cast.ToStringE(value)
+	if err != nil {
+		return "", err
+	}
+	switch c = strings.ToLower(c); c {
+	case "", "none", "gzip":
+		return c, nil
+	default:
+		return "", errors.Errorf("unsupported compression [%s], gzip is supported", c)
+	}
+}
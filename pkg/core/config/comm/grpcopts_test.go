@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestValidateGRPCOptions(t *testing.T) {
+	assert.NoError(t, ValidateGRPCOptions(nil))
+	assert.NoError(t, ValidateGRPCOptions(map[string]interface{}{
+		"ssl-target-name-override":     "peer0.org1.example.com",
+		"keep-alive-time":              "0s",
+		"keep-alive-timeout":           "20s",
+		"keep-alive-permit":            false,
+		"fail-fast":                    false,
+		"allow-insecure":               false,
+		"grpc-max-send-message-length": 104857600,
+		"grpc.http2.keepalive_time":    120000,
+		"max-recv-message-size":        4194304,
+		"max-send-message-size":        "4194304",
+		"compression":                  "gzip",
+		"initial-window-size":          1048576,
+		"initial-conn-window-size":     1048576,
+		"backoff-max-delay":            "10s",
+		"user-agent":                   "fabric-sdk-go",
+	}))
+
+	invalid := []map[string]interface{}{
+		{"unknown-option": true},
+		{"max-recv-message-size": 0},
+		{"max-send-message-size": "large"},
+		{"compression": "snappy"},
+		{"initial-window-size": 1024},
+		{"backoff-max-delay": "soon"},
+		{"fail-fast": "maybe"},
+		{"grpc-max-send-message-length": 0},
+		{"grpc.max_receive_message_length": "large"},
+		{"grpc.http2.keepalive_time": -5},
+	}
+	for _, options := range invalid {
+		assert.Error(t, ValidateGRPCOptions(options), "expected error for grpcOptions %v", options)
+	}
+}
+
+func TestParseGRPCOptionsLegacyMessageSizes(t *testing.T) {
+	opts, err := parseGRPCOptions(map[string]interface{}{
+		"grpc-max-send-message-length":    4194304,
+		"grpc.max_receive_message_length": "8388608",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4194304, opts.maxSendMsgSize)
+	assert.Equal(t, 8388608, opts.maxRecvMsgSize)
+
+	opts, err = parseGRPCOptions(map[string]interface{}{
+		"grpc.max_send_message_length":    -1,
+		"grpc-max-receive-message-length": -1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, math.MaxInt32, opts.maxSendMsgSize, "expected -1 to stand for the largest message size")
+	assert.Equal(t, math.MaxInt32, opts.maxRecvMsgSize, "expected -1 to stand for the largest message size")
+}
+
+func TestGRPCDialOptions(t *testing.T) {
+	dialOpts, err := GRPCDialOptions(nil)
+	assert.NoError(t, err)
+	assert.Len(t, dialOpts, 1, "expected the default message sizes only")
+
+	dialOpts, err = GRPCDialOptions(map[string]interface{}{
+		"compression":              "gzip",
+		"initial-window-size":      1048576,
+		"initial-conn-window-size": 1048576,
+		"backoff-max-delay":        "10s",
+		"user-agent":               "fabric-sdk-go",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, dialOpts, 7)
+
+	_, err = GRPCDialOptions(map[string]interface{}{"unknown-option": true})
+	assert.Error(t, err, "expected error for unknown option")
+}
+
+func TestGRPCDialOptionsMaxSendMsgSize(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	dialOpts, err := GRPCDialOptions(map[string]interface{}{"max-send-message-size": 16, "compression": "gzip"})
+	if err != nil {
+		t.Fatalf("Failed to get dial options: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(), append(dialOpts, grpc.WithInsecure(), grpc.WithBlock())...)
+	if err != nil {
+		t.Fatalf("Failed to dial GRPC server: %s", err)
+	}
+	defer conn.Close()
+
+	err = conn.Invoke(ctx, "/test.Service/Method", &testMessage{data: make([]byte, 32)}, &testMessage{})
+	if err == nil {
+		t.Fatal("Expected error for message larger than the max send message size")
+	}
+	assert.Contains(t, err.Error(), "larger than max")
+}
+
+// testMessage is a raw message for the GRPC codec of proto messages
+type testMessage struct {
+	data []byte
+}
+
+func (m *testMessage) Reset()         { m.data = nil }
+func (m *testMessage) String() string { return string(m.data) }
+func (m *testMessage) ProtoMessage()  {}
+
+func (m *testMessage) Marshal() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m *testMessage) Unmarshal(b []byte) error {
+	m.data = append([]byte(nil), b...)
+	return nil
+}
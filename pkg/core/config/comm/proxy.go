@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/pkg/errors"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// ProxyURL returns the URL of the proxy of an endpoint: the proxy configured for the endpoint,
+// or else the proxy of the client config; nil if connections to the endpoint are direct
+func ProxyURL(config core.Config, endpointProxy core.ProxyConfig) (*url.URL, error) {
+	if endpointProxy.URL != "" {
+		return ParseProxyURL(endpointProxy.URL)
+	}
+
+	clientConfig, err := config.Client()
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to load client config")
+	}
+	if clientConfig == nil || clientConfig.Proxy.URL == "" {
+		return nil, nil
+	}
+	return ParseProxyURL(clientConfig.Proxy.URL)
+}
+
+// ParseProxyURL parses the URL of an HTTP CONNECT proxy (http://[user:password@]host:port)
+// or of a SOCKS5 proxy (socks5://[user:password@]host:port)
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy URL [%s]", rawURL)
+	}
+	switch proxyURL.Scheme {
+	case "http", "socks5":
+	default:
+		return nil, errors.Errorf("unsupported proxy scheme [%s], http and socks5 are supported", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, errors.Errorf("proxy URL [%s] has no host", rawURL)
+	}
+	return proxyURL, nil
+}
+
+// ProxyDialOption returns the GRPC dial option establishing connections through the proxy
+func ProxyDialOption(proxyURL *url.URL) grpc.DialOption {
+	return grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return DialProxy(ctx, proxyURL, addr)
+	})
+}
+
+// DialProxy connects to the address through the proxy
+func DialProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	logger.Debugf("Connecting to [%s] through proxy [%s]", addr, proxyURL.Host)
+
+	var conn net.Conn
+	var err error
+	switch proxyURL.Scheme {
+	case "http":
+		conn, err = dialHTTPConnect(ctx, proxyURL, addr)
+	case "socks5":
+		conn, err = dialSOCKS5(ctx, proxyURL, addr)
+	default:
+		err = errors.Errorf("unsupported proxy scheme [%s]", proxyURL.Scheme)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "connecting through proxy "+proxyURL.Host+" failed")
+	}
+	return conn, nil
+}
+
+// dialHTTPConnect tunnels a connection to the address through an HTTP proxy with the CONNECT method
+func dialHTTPConnect(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing proxy failed")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "sending CONNECT request failed")
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "reading CONNECT response failed")
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, errors.Errorf("proxy refused CONNECT to %s: %s", addr, resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	if r.Buffered() > 0 {
+		// the server has already sent data through the tunnel
+		return &bufferedConn{Conn: conn, r: r}, nil
+	}
+	return conn, nil
+}
+
+// dialSOCKS5 connects to the address through a SOCKS5 proxy
+func dialSOCKS5(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	var auth *proxy.Auth
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		auth = &proxy.Auth{User: user.Username(), Password: password}
+	}
+
+	forward := &net.Dialer{}
+	if deadline, ok := ctx.Deadline(); ok {
+		forward.Deadline = deadline
+	}
+	dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, forward)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating SOCKS5 dialer failed")
+	}
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "SOCKS5 connect failed")
+	}
+	return conn, nil
+}
+
+// bufferedConn reads the data buffered while reading the CONNECT response before reading from the connection
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -13,19 +13,19 @@ import (
 	"encoding/pem"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"math/rand"
 	"os"
 	"path"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
-	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"github.com/pkg/errors"
@@ -58,12 +58,18 @@ type Config struct {
 	ordererMatchers     map[int]*regexp.Regexp
 	caMatchers          map[int]*regexp.Regexp
 	opts                options
+	tlsCertPoolMutex    sync.Mutex
+	// tlsCACerts are the certificates added to the cert pool, which are kept when the pool is reloaded
+	tlsCACerts map[string]*x509.Certificate
+	// customTLSCertPool is the cert pool set with SetTLSCACertPool, which is kept when the pool is reloaded
+	customTLSCertPool *x509.CertPool
 }
 
 type options struct {
-	envPrefix    string
-	templatePath string
-	template     *Config
+	envPrefix           string
+	templatePath        string
+	template            *Config
+	tlsCredentialSource *comm.TLSCredentialSource
 }
 
 // Option configures the package.
@@ -152,6 +158,16 @@ func WithEnvPrefix(prefix string) Option {
 	}
 }
 
+// WithTLSCredentialSource provides the TLS client certificates and CA certificates from the credential source,
+// instead of the client.tlsCerts config section. CA certificates are added to the cert pool; the pool is reloaded
+// whenever the credentials of the source are updated.
+func WithTLSCredentialSource(source *comm.TLSCredentialSource) Option {
+	return func(opts *options) error {
+		opts.tlsCredentialSource = source
+		return nil
+	}
+}
+
 /*
 // WithTemplatePath loads the named file to populate a configuration template prior to loading the instance configuration.
 func WithTemplatePath(path string) Option {
@@ -216,6 +232,14 @@ func initConfig(c *Config) (*Config, error) {
 		return nil, err
 	}
 	c.tlsCertPool = tlsCertPool
+	c.tlsCACerts = make(map[string]*x509.Certificate)
+
+	if source := c.opts.tlsCredentialSource; source != nil {
+		for _, cert := range source.CACerts() {
+			c.tlsCertPool.AddCert(cert)
+		}
+		source.Subscribe(c.reloadTLSCACertPool)
+	}
 
 	if err = c.cacheNetworkConfiguration(); err != nil {
 		return nil, errors.WithMessage(err, "network configuration load failed")
@@ -298,6 +322,10 @@ func (c *Config) Client() (*core.ClientConfig, error) {
 	client.TLSCerts.Path = SubstPathVars(client.TLSCerts.Path)
 	client.TLSCerts.Client.Key.Path = SubstPathVars(client.TLSCerts.Client.Key.Path)
 	client.TLSCerts.Client.Cert.Path = SubstPathVars(client.TLSCerts.Client.Cert.Path)
+	remote := &client.BCCSP.Security.Remote
+	remote.TLSCACerts.Path = SubstPathVars(remote.TLSCACerts.Path)
+	remote.Client.Key.Path = SubstPathVars(remote.Client.Key.Path)
+	remote.Client.Cert.Path = SubstPathVars(remote.Client.Cert.Path)
 
 	return &client, nil
 }
@@ -318,6 +346,51 @@ func (c *Config) CAConfig(org string) (*core.CAConfig, error) {
 	return &caConfig, nil
 }
 
+// CAConfigs returns the configurations of all CAs of the organization, in the order they are listed
+// by the organization. The first CA is the default CA of the organization. File paths in the TLS
+// configuration of the CAs are substituted.
+func (c *Config) CAConfigs(org string) ([]core.CAConfig, error) {
+	config, err := c.NetworkConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	certAuthorityNames := config.Organizations[strings.ToLower(org)].CertificateAuthorities
+	if len(certAuthorityNames) == 0 {
+		return nil, errors.Errorf("organization %s has no Certificate Authorities setup. Make sure each org has at least 1 configured", org)
+	}
+
+	caConfigs := make([]core.CAConfig, 0, len(certAuthorityNames))
+	for _, certAuthorityName := range certAuthorityNames {
+		if certAuthorityName == "" {
+			return nil, errors.Errorf("certificate authority empty for %s. Make sure each org has non empty certificate authority names", org)
+		}
+
+		caConfig, ok := config.CertificateAuthorities[strings.ToLower(certAuthorityName)]
+		if !ok {
+			matchedConfig, _, err := c.tryMatchingCAConfig(strings.ToLower(certAuthorityName))
+			if err != nil {
+				return nil, errors.WithMessage(err, fmt.Sprintf("CA Server Name %s not found", certAuthorityName))
+			}
+			caConfig = *matchedConfig
+		}
+
+		if caConfig.TLSCACerts.Path != "" {
+			certFiles := strings.Split(caConfig.TLSCACerts.Path, ",")
+			for i, v := range certFiles {
+				certFiles[i] = SubstPathVars(v)
+			}
+			caConfig.TLSCACerts.Path = strings.Join(certFiles, ",")
+		}
+		caConfig.TLSCACerts.Client.Key.Path = SubstPathVars(caConfig.TLSCACerts.Client.Key.Path)
+		caConfig.TLSCACerts.Client.Cert.Path = SubstPathVars(caConfig.TLSCACerts.Client.Cert.Path)
+
+		caConfigs = append(caConfigs, caConfig)
+	}
+
+	return caConfigs, nil
+}
+
 // CAServerCertPems Read configuration option for the server certificates
 // will send a list of cert pem contents directly from the config bytes array
 func (c *Config) CAServerCertPems(org string) ([]string, error) {
@@ -663,11 +736,53 @@ func (c *Config) cacheNetworkConfiguration() error {
 		return err
 	}
 
+	if err = validateGRPCOptions(&networkConfig); err != nil {
+		return err
+	}
+
+	substTLSClientCertPaths(&networkConfig)
+
 	c.networkConfig = &networkConfig
 	c.networkConfigCached = true
 	return nil
 }
 
+// substTLSClientCertPaths substitutes the path variables of the TLS client certificates of the organizations,
+// orderers and peers
+func substTLSClientCertPaths(networkConfig *core.NetworkConfig) {
+	subst := func(c *core.TLSClientCertConfig) {
+		c.Key.Path = SubstPathVars(c.Key.Path)
+		c.Cert.Path = SubstPathVars(c.Cert.Path)
+	}
+	for name, org := range networkConfig.Organizations {
+		subst(&org.TLSClientCert)
+		networkConfig.Organizations[name] = org
+	}
+	for name, orderer := range networkConfig.Orderers {
+		subst(&orderer.TLSClientCert)
+		networkConfig.Orderers[name] = orderer
+	}
+	for name, peer := range networkConfig.Peers {
+		subst(&peer.TLSClientCert)
+		networkConfig.Peers[name] = peer
+	}
+}
+
+// validateGRPCOptions rejects the orderers and peers with unknown or invalid grpcOptions
+func validateGRPCOptions(networkConfig *core.NetworkConfig) error {
+	for name, orderer := range networkConfig.Orderers {
+		if err := comm.ValidateGRPCOptions(orderer.GRPCOptions); err != nil {
+			return errors.WithMessage(err, "invalid grpcOptions of orderer "+name)
+		}
+	}
+	for name, peer := range networkConfig.Peers {
+		if err := comm.ValidateGRPCOptions(peer.GRPCOptions); err != nil {
+			return errors.WithMessage(err, "invalid grpcOptions of peer "+name)
+		}
+	}
+	return nil
+}
+
 // OrderersConfig returns a list of defined orderers
 func (c *Config) OrderersConfig() ([]core.OrdererConfig, error) {
 	orderers := []core.OrdererConfig{}
@@ -1281,25 +1396,72 @@ func (c *Config) verifyPeerConfig(p core.PeerConfig, peerName string, tlsEnabled
 
 // SetTLSCACertPool allows a user to set a global cert pool with a set of
 // root TLS CAs that will be used for all outgoing connections
+// The CA certificates of the TLS credential source are added to the cert pool, which is kept when they are updated.
+// Since certificates cannot be removed from a cert pool, CA certificates removed from the source remain in the pool.
 func (c *Config) SetTLSCACertPool(certPool *x509.CertPool) {
 	if certPool == nil {
 		certPool = x509.NewCertPool()
 	}
+	c.tlsCertPoolMutex.Lock()
+	defer c.tlsCertPoolMutex.Unlock()
+
+	if source := c.opts.tlsCredentialSource; source != nil {
+		for _, cert := range source.CACerts() {
+			certPool.AddCert(cert)
+		}
+	}
 	c.tlsCertPool = certPool
+	c.customTLSCertPool = certPool
 }
 
 // TLSCACertPool returns the configured cert pool. If a certConfig
 // is provided, the certficate is added to the pool
 func (c *Config) TLSCACertPool(certs ...*x509.Certificate) (*x509.CertPool, error) {
+	c.tlsCertPoolMutex.Lock()
+	defer c.tlsCertPoolMutex.Unlock()
+
 	for _, cert := range certs {
 		if cert != nil {
 			c.tlsCertPool.AddCert(cert)
+			if c.opts.tlsCredentialSource != nil {
+				c.tlsCACerts[string(cert.Raw)] = cert
+			}
 		}
 	}
 
 	return c.tlsCertPool, nil
 }
 
+// TLSCredentialSource returns the TLS credential source of the config, if any
+func (c *Config) TLSCredentialSource() *comm.TLSCredentialSource {
+	return c.opts.tlsCredentialSource
+}
+
+// reloadTLSCACertPool replaces the cert pool by a pool with the current CA certificates of the TLS credential source,
+// so that CA certificates removed from the source are no longer trusted. The cert pool set with SetTLSCACertPool,
+// if any, is kept and the current CA certificates are added to it.
+func (c *Config) reloadTLSCACertPool() {
+	c.tlsCertPoolMutex.Lock()
+	defer c.tlsCertPoolMutex.Unlock()
+
+	certPool := c.customTLSCertPool
+	if certPool == nil {
+		var err error
+		if certPool, err = getCertPool(c.configViper); err != nil {
+			logger.Warnf("Reloading TLS CA cert pool failed: %s", err)
+			return
+		}
+	}
+
+	for _, cert := range c.opts.tlsCredentialSource.CACerts() {
+		certPool.AddCert(cert)
+	}
+	for _, cert := range c.tlsCACerts {
+		certPool.AddCert(cert)
+	}
+	c.tlsCertPool = certPool
+}
+
 // IsSecurityEnabled ...
 func (c *Config) IsSecurityEnabled() bool {
 	return c.configViper.GetBool("client.BCCSP.security.enabled")
@@ -1385,51 +1547,22 @@ func (c *Config) CryptoConfigPath() string {
 
 // TLSClientCerts loads the client's certs for mutual TLS
 // It checks the config for embedded pem files before looking for cert files
+// The client certificates of the TLS credential source, if any, take precedence.
 func (c *Config) TLSClientCerts() ([]tls.Certificate, error) {
+	if source := c.opts.tlsCredentialSource; source != nil && len(source.ClientCerts()) > 0 {
+		return source.ClientCerts(), nil
+	}
+
 	clientConfig, err := c.Client()
 	if err != nil {
 		return nil, err
 	}
-	var clientCerts tls.Certificate
-	var cb, kb []byte
-	cb, err = clientConfig.TLSCerts.Client.Cert.Bytes()
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to load tls client cert")
-	}
-
-	if len(cb) == 0 {
+	if clientConfig.TLSCerts.Client.Cert.Pem == "" && clientConfig.TLSCerts.Client.Cert.Path == "" {
 		// if no cert found in the config, return empty cert chain
-		return []tls.Certificate{clientCerts}, nil
+		return []tls.Certificate{{}}, nil
 	}
 
-	// Load private key from cert using default crypto suite
-	cs := cs.GetDefault()
-	pk, err := cryptoutil.GetPrivateKeyFromCert(cb, cs)
-
-	// If CryptoSuite fails to load private key from cert then load private key from config
-	if err != nil || pk == nil {
-		logger.Debugf("Reading pk from config, unable to retrieve from cert: %s", err)
-		if clientConfig.TLSCerts.Client.Key.Pem != "" {
-			kb = []byte(clientConfig.TLSCerts.Client.Key.Pem)
-		} else if clientConfig.TLSCerts.Client.Key.Path != "" {
-			kb, err = loadByteKeyOrCertFromFile(clientConfig, true)
-			if err != nil {
-				return nil, errors.Wrapf(err, "Failed to load key from file path '%s'", clientConfig.TLSCerts.Client.Key.Path)
-			}
-		}
-
-		// load the key/cert pair from []byte
-		clientCerts, err = tls.X509KeyPair(cb, kb)
-		if err != nil {
-			return nil, errors.Errorf("Error loading cert/key pair as TLS client credentials: %v", err)
-		}
-
-		return []tls.Certificate{clientCerts}, nil
-
-	}
-
-	// private key was retrieved from cert
-	clientCerts, err = cryptoutil.X509KeyPair(cb, pk, cs)
+	clientCerts, err := comm.TLSKeyPair(clientConfig.TLSCerts.Client.Cert, clientConfig.TLSCerts.Client.Key, cs.GetDefault())
 	if err != nil {
 		return nil, err
 	}
@@ -1469,24 +1602,6 @@ func NetworkPeerConfigFromURL(cfg core.Config, url string) (*core.NetworkPeer, e
 	return &np, nil
 }
 
-func loadByteKeyOrCertFromFile(c *core.ClientConfig, isKey bool) ([]byte, error) {
-	var path string
-	a := "key"
-	if isKey {
-		path = SubstPathVars(c.TLSCerts.Client.Key.Path)
-		c.TLSCerts.Client.Key.Path = path
-	} else {
-		a = "cert"
-		path = SubstPathVars(c.TLSCerts.Client.Cert.Path)
-		c.TLSCerts.Client.Cert.Path = path
-	}
-	bts, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, errors.Errorf("Error loading %s file from '%s' err: %v", a, path, err)
-	}
-	return bts, nil
-}
-
 // loadCAKey
 func loadCAKey(rawData []byte) (*x509.Certificate, error) {
 	block, _ := pem.Decode(rawData)
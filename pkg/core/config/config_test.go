@@ -9,6 +9,7 @@ package config
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"os"
 	"path"
@@ -20,6 +21,7 @@ import (
 	"reflect"
 
 	api "github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"github.com/pkg/errors"
@@ -183,6 +185,82 @@ func TestCAConfig(t *testing.T) {
 	}
 }
 
+func TestCAConfigs(t *testing.T) {
+	configProvider, err := FromFile(configTestFilePath)()
+	if err != nil {
+		t.Fatalf("Unexpected error reading config: %v", err)
+	}
+	sampleConfig := configProvider.(*Config)
+
+	caConfigs, err := sampleConfig.CAConfigs(org1)
+	if err != nil {
+		t.Fatalf("Get CA Configs failed: %v", err)
+	}
+	if len(caConfigs) != 1 || caConfigs[0].CAName != "ca.org1.example.com" {
+		t.Fatalf("Expected the CA of %s, got %+v", org1, caConfigs)
+	}
+	certfile, err := sampleConfig.CAClientCertPath(org1)
+	if err != nil || caConfigs[0].TLSCACerts.Client.Cert.Path != certfile {
+		t.Fatalf("Expected CA client cert file %s, got %s", certfile, caConfigs[0].TLSCACerts.Client.Cert.Path)
+	}
+	sCertFiles, err := sampleConfig.CAServerCertPaths(org1)
+	if err != nil || caConfigs[0].TLSCACerts.Path != strings.Join(sCertFiles, ",") {
+		t.Fatalf("Expected CA server cert files %v, got %s", sCertFiles, caConfigs[0].TLSCACerts.Path)
+	}
+
+	// All CAs of the organization are returned in order
+	sampleConfig.networkConfigCached = false
+	sampleConfig.configViper.Set("organizations.org1.certificateAuthorities", []string{"local.ca.org2.example.com", "ca.org1.example.com"})
+	caConfigs, err = sampleConfig.CAConfigs(org1)
+	if err != nil {
+		t.Fatalf("Get CA Configs failed: %v", err)
+	}
+	if len(caConfigs) != 2 || caConfigs[0].CAName != "ca.org2.example.com" || caConfigs[1].CAName != "ca.org1.example.com" {
+		t.Fatalf("Expected the CAs of org2 and org1, got %+v", caConfigs)
+	}
+
+	sampleConfig.networkConfigCached = false
+	sampleConfig.configViper.Set("organizations.org1.certificateAuthorities", []string{"ca.org1.example.com", ""})
+	if _, err := sampleConfig.CAConfigs(org1); err == nil {
+		t.Fatal("Get CA Configs supposed to fail for empty CA name")
+	}
+
+	if _, err := sampleConfig.CAConfigs("missingorg"); err == nil {
+		t.Fatal("Get CA Configs supposed to fail for organization without CAs")
+	}
+}
+
+func TestRemoteSignerConfig(t *testing.T) {
+	configYAML := `
+client:
+  BCCSP:
+    security:
+      default:
+        provider: "REMOTE"
+      remote:
+        url: grpcs://signer.example.com:7070
+        tlsCACerts:
+          path: ${GOPATH}/signer_ca.pem
+        timeout: 3s
+`
+	configProvider, err := FromRaw([]byte(configYAML), "yaml")()
+	if err != nil {
+		t.Fatalf("Unexpected error reading config: %v", err)
+	}
+	clientConfig, err := configProvider.Client()
+	if err != nil {
+		t.Fatalf("Get client config failed: %v", err)
+	}
+
+	remote := clientConfig.BCCSP.Security.Remote
+	if remote.URL != "grpcs://signer.example.com:7070" || remote.Timeout != 3*time.Second {
+		t.Fatalf("Unexpected remote signer config: %+v", remote)
+	}
+	if remote.TLSCACerts.Path != SubstPathVars("${GOPATH}/signer_ca.pem") {
+		t.Fatalf("Expected substituted TLS CA certs path, got %s", remote.TLSCACerts.Path)
+	}
+}
+
 func TestCAConfigFailsByNetworkConfig(t *testing.T) {
 
 	//Tamper 'client.network' value and use a new config to avoid conflicting with other tests
@@ -486,6 +564,29 @@ func TestChannelOrderers(t *testing.T) {
 	}
 }
 
+func TestChannelPolicies(t *testing.T) {
+	chConfig, err := configImpl.ChannelConfig("mychannel")
+	if chConfig == nil || err != nil {
+		t.Fatal("Testing ChannelConfig failed")
+	}
+
+	policy := chConfig.Policies.OrdererSelection
+	if policy.Strategy != api.Random {
+		t.Fatalf("Expecting %s orderer selection strategy got %s", api.Random, policy.Strategy)
+	}
+	if policy.RetryAttempts != 3 {
+		t.Fatalf("Expecting 3 retry attempts got %d", policy.RetryAttempts)
+	}
+
+	breakerPolicy := chConfig.Policies.CircuitBreaker
+	if breakerPolicy.Threshold != 3 {
+		t.Fatalf("Expecting circuit breaker threshold 3 got %d", breakerPolicy.Threshold)
+	}
+	if breakerPolicy.OpenInterval != 5*time.Second {
+		t.Fatalf("Expecting 5s circuit breaker open interval got %s", breakerPolicy.OpenInterval)
+	}
+}
+
 func testCommonConfigPeerByURL(t *testing.T, expectedConfigURL string, fetchedConfigURL string) {
 	expectedConfig, err := configImpl.peerConfig(expectedConfigURL)
 	if err != nil {
@@ -1445,6 +1546,132 @@ func TestTLSClientCertsNoCerts(t *testing.T) {
 	}
 }
 
+func TestTLSCredentialSource(t *testing.T) {
+	clientCert, err := tls.LoadX509KeyPair("../../../test/fixtures/config/mutual_tls/client_sdk_go.pem", "../../../test/fixtures/config/mutual_tls/client_sdk_go-key.pem")
+	if err != nil {
+		t.Fatalf("Failed to load client cert: %s", err)
+	}
+	caCert, err := x509.ParseCertificate(clientCert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse cert: %s", err)
+	}
+	addedCert, err := endpoint.TLSConfig{Path: "../../../test/fixtures/fabric/v1/crypto-config/ordererOrganizations/example.com/tlsca/tlsca.example.com-cert.pem"}.TLSCert()
+	if err != nil {
+		t.Fatalf("Failed to load cert: %s", err)
+	}
+
+	source := comm.NewTLSCredentialSource(nil, nil)
+	c, err := FromFile(configPemTestFilePath, WithTLSCredentialSource(source))()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	assert.Equal(t, source, c.(*Config).TLSCredentialSource())
+
+	// Without client certs, the certs of the config are used
+	certs, err := c.TLSClientCerts()
+	assert.Nil(t, err)
+	assert.NotEqual(t, clientCert.Certificate, certs[0].Certificate)
+
+	pool, err := c.TLSCACertPool(addedCert)
+	assert.Nil(t, err)
+	subjects := len(pool.Subjects())
+
+	source.Update([]tls.Certificate{clientCert}, []*x509.Certificate{caCert})
+	certs, err = c.TLSClientCerts()
+	assert.Nil(t, err)
+	assert.Equal(t, clientCert.Certificate, certs[0].Certificate)
+
+	// The reloaded pool has the CA certs of the source and keeps the added certs
+	pool, err = c.TLSCACertPool()
+	assert.Nil(t, err)
+	assert.Contains(t, pool.Subjects(), caCert.RawSubject)
+	assert.Contains(t, pool.Subjects(), addedCert.RawSubject)
+
+	source.Update(nil, nil)
+	pool, err = c.TLSCACertPool()
+	assert.Nil(t, err)
+	assert.Equal(t, subjects, len(pool.Subjects()), "expected CA certs removed from the source to be removed from the pool")
+}
+
+func TestTLSCredentialSourceWithCustomCertPool(t *testing.T) {
+	caCert, err := endpoint.TLSConfig{Path: "../../../test/fixtures/fabric/v1/crypto-config/ordererOrganizations/example.com/tlsca/tlsca.example.com-cert.pem"}.TLSCert()
+	if err != nil {
+		t.Fatalf("Failed to load cert: %s", err)
+	}
+	customCert, err := endpoint.TLSConfig{Path: "../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem"}.TLSCert()
+	if err != nil {
+		t.Fatalf("Failed to load cert: %s", err)
+	}
+
+	source := comm.NewTLSCredentialSource(nil, nil)
+	c, err := FromFile(configPemTestFilePath, WithTLSCredentialSource(source))()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+
+	customPool := x509.NewCertPool()
+	customPool.AddCert(customCert)
+	c.SetTLSCACertPool(customPool)
+
+	// The pool set by the caller is kept when the CA certs of the source are updated
+	source.Update(nil, []*x509.Certificate{caCert})
+	pool, err := c.TLSCACertPool()
+	assert.Nil(t, err)
+	assert.True(t, pool == customPool, "expected the cert pool set by the caller")
+	assert.Contains(t, pool.Subjects(), customCert.RawSubject)
+	assert.Contains(t, pool.Subjects(), caCert.RawSubject)
+}
+
+func TestTLSClientCertConfig(t *testing.T) {
+	configYAML := `
+organizations:
+  org1:
+    mspid: Org1MSP
+    tlsClientCert:
+      key:
+        path: ${GOPATH}/org1/client.key
+      cert:
+        path: ${GOPATH}/org1/client.crt
+orderers:
+  orderer.example.com:
+    url: orderer.example.com:7050
+    tlsClientCert:
+      fromIdentity: true
+peers:
+  peer0.org1.example.com:
+    url: peer0.org1.example.com:7051
+    tlsClientCert:
+      cert:
+        pem: peer0-cert
+`
+	c, err := FromRaw([]byte(configYAML), "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	networkConfig, err := c.NetworkConfig()
+	if err != nil {
+		t.Fatalf("Failed to get network config: %s", err)
+	}
+
+	orgCert := networkConfig.Organizations["org1"].TLSClientCert
+	assert.Equal(t, goPath()+"/org1/client.key", orgCert.Key.Path)
+	assert.Equal(t, goPath()+"/org1/client.crt", orgCert.Cert.Path)
+	assert.False(t, orgCert.FromIdentity)
+
+	ordererCfg, err := c.OrdererConfig("orderer.example.com")
+	if err != nil {
+		t.Fatalf("Failed to get orderer config: %s", err)
+	}
+	assert.True(t, ordererCfg.TLSClientCert.FromIdentity)
+
+	peerCfg, err := c.PeerConfigByURL("peer0.org1.example.com:7051")
+	if err != nil {
+		t.Fatalf("Failed to get peer config: %s", err)
+	}
+	assert.Equal(t, "peer0-cert", peerCfg.TLSClientCert.Cert.Pem)
+	assert.True(t, peerCfg.TLSClientCert.IsSet())
+}
+
 func TestNetworkPeerConfigFromURL(t *testing.T) {
 	configProvider, err := FromFile(configTestFilePath)()
 	if err != nil {
@@ -1664,3 +1891,38 @@ func TestFromDefaultPathFailure(t *testing.T) {
 	}
 }
 */
+
+func TestGRPCOptionsConfig(t *testing.T) {
+	configYAML := `
+peers:
+  peer0.org1.example.com:
+    url: peer0.org1.example.com:7051
+    grpcOptions:
+      ssl-target-name-override: peer0.org1.example.com
+      max-recv-message-size: 4194304
+      compression: gzip
+      user-agent: fabric-sdk-go
+`
+	c, err := FromRaw([]byte(configYAML), "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	peerCfg, err := c.PeerConfigByURL("peer0.org1.example.com:7051")
+	if err != nil {
+		t.Fatalf("Failed to get peer config: %s", err)
+	}
+	assert.Equal(t, "gzip", peerCfg.GRPCOptions["compression"])
+
+	_, err = FromRaw([]byte(configYAML+"      max-message-size: 4194304\n"), "yaml")()
+	assert.Error(t, err, "expected error for unknown grpc option of peer")
+
+	ordererYAML := `
+orderers:
+  orderer.example.com:
+    url: orderer.example.com:7050
+    grpcOptions:
+      compression: snappy
+`
+	_, err = FromRaw([]byte(ordererYAML), "yaml")()
+	assert.Error(t, err, "expected error for unsupported compression of orderer")
+}
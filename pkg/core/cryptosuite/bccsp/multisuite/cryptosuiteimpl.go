@@ -8,19 +8,14 @@ package multisuite
 
 import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
-	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
-	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
-	"github.com/pkg/errors"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+
+	// Register the PKCS11 and REMOTE security providers
+	_ "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
+	_ "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/remote"
 )
 
 //GetSuiteByConfig returns cryptosuite adaptor for bccsp loaded according to given config
 func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
-	switch config.SecurityProvider() {
-	case "SW":
-		return sw.GetSuiteByConfig(config)
-	case "PKCS11":
-		return pkcs11.GetSuiteByConfig(config)
-	}
-
-	return nil, errors.Errorf("Unsupported security provider requested: %s", config.SecurityProvider())
+	return cryptosuite.GetSuiteByConfig(config)
 }
@@ -14,6 +14,8 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/pkcs11"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	pkcs11Suite "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/remote"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 )
 
@@ -43,6 +45,7 @@ func TestCryptoSuiteByConfigSW(t *testing.T) {
 	mockConfig.EXPECT().SecurityLevel().Return(256)
 	mockConfig.EXPECT().KeyStorePath().Return("")
 	mockConfig.EXPECT().Ephemeral().Return(true)
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
 
 	//Get cryptosuite using config
 	c, err := GetSuiteByConfig(mockConfig)
@@ -72,6 +75,7 @@ func TestCryptoSuiteByConfigPKCS11(t *testing.T) {
 	mockConfig.EXPECT().SecurityProviderLabel().Return(softHSMTokenLabel)
 	mockConfig.EXPECT().SecurityProviderPin().Return(softHSMPin)
 	mockConfig.EXPECT().SoftVerify().Return(true)
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
 
 	//Get cryptosuite using config
 	c, err := GetSuiteByConfig(mockConfig)
@@ -79,7 +83,36 @@ func TestCryptoSuiteByConfigPKCS11(t *testing.T) {
 		t.Fatalf("Not supposed to get error, but got: %v", err)
 	}
 
-	verifySuiteType(t, c, "*pkcs11.impl")
+	p11, ok := c.(*pkcs11Suite.CryptoSuite)
+	if !ok {
+		t.Fatal("Unexpected cryptosuite type")
+	}
+	verifySuiteType(t, p11.CryptoSuite, "*pkcs11.impl")
+}
+
+func TestCryptoSuiteByConfigRemote(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	clientConfig := &core.ClientConfig{}
+	clientConfig.BCCSP.Security.Remote.URL = "grpc://localhost:7070"
+
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("REMOTE")
+	mockConfig.EXPECT().SecurityProvider().Return("REMOTE")
+	mockConfig.EXPECT().SecurityAlgorithm().Return("SHA2")
+	mockConfig.EXPECT().SecurityLevel().Return(256)
+	mockConfig.EXPECT().Client().Return(clientConfig, nil)
+
+	//Get cryptosuite using config
+	c, err := GetSuiteByConfig(mockConfig)
+	if err != nil {
+		t.Fatalf("Not supposed to get error, but got: %v", err)
+	}
+
+	if _, ok := c.(*remote.CryptoSuite); !ok {
+		t.Fatalf("Unexpected cryptosuite type: %T", c)
+	}
 }
 
 func verifySuiteType(t *testing.T, c core.CryptoSuite, expectedType string) {
@@ -10,7 +10,10 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	bccspPkcs11 "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/factory/pkcs11"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/pkcs11"
+	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"github.com/pkg/errors"
@@ -18,6 +21,12 @@ import (
 
 var logger = logging.NewLogger("fabsdk/core")
 
+func init() {
+	if err := cryptosuite.RegisterProvider("PKCS11", GetSuiteByConfig); err != nil {
+		logger.Panicf("Could not register PKCS11 security provider: %v", err)
+	}
+}
+
 //GetSuiteByConfig returns cryptosuite adaptor for bccsp loaded according to given config
 func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
 	// TODO: delete this check?
@@ -31,7 +40,20 @@ func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &wrapper.CryptoSuite{BCCSP: bccsp}, nil
+
+	clientConfig, err := config.Client()
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to retrieve client config")
+	}
+	pool, err := NewSessionPool(opts.Library, opts.Label, opts.Pin, &clientConfig.BCCSP.Security.PKCS11)
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating PKCS11 session pool failed")
+	}
+	local, err := sw.GetSuite(opts.SecLevel, opts.HashFamily, bccspSw.NewDummyKeyStore())
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating local cryptosuite failed")
+	}
+	return NewCryptoSuite(&wrapper.CryptoSuite{BCCSP: bccsp}, pool, local), nil
 }
 
 func getBCCSPFromOpts(config *pkcs11.PKCS11Opts) (bccsp.BCCSP, error) {
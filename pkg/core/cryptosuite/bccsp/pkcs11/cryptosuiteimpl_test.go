@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
@@ -62,6 +64,7 @@ func TestCryptoSuiteByConfigPKCS11(t *testing.T) {
 	mockConfig.EXPECT().SecurityProviderLabel().Return(softHSMTokenLabel)
 	mockConfig.EXPECT().SecurityProviderPin().Return(softHSMPin)
 	mockConfig.EXPECT().SoftVerify().Return(true)
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
 
 	//Get cryptosuite using config
 	c, err := GetSuiteByConfig(mockConfig)
@@ -144,6 +147,15 @@ func TestPKCS11CSPConfigWithEmptyProviderName(t *testing.T) {
 	}
 }
 
+func TestIsKeyFailure(t *testing.T) {
+	assert.False(t, isKeyFailure(nil))
+	assert.False(t, isKeyFailure(errors.New("other failure")))
+	assert.False(t, isKeyFailure(errors.Wrap(p11.Error(p11.CKR_DATA_INVALID), "signing failed")))
+	for _, code := range []uint{p11.CKR_SESSION_HANDLE_INVALID, p11.CKR_DEVICE_REMOVED, p11.CKR_KEY_HANDLE_INVALID, p11.CKR_OBJECT_HANDLE_INVALID} {
+		assert.True(t, isKeyFailure(errors.Wrap(p11.Error(code), "signing failed")), "expected key failure for error %d", code)
+	}
+}
+
 func configurePKCS11Options(hashFamily string, securityLevel int) *pkcs11.PKCS11Opts {
 	providerLib, softHSMPin, softHSMTokenLabel := pkcs11.FindPKCS11Lib()
 
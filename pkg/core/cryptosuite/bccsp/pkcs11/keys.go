@@ -0,0 +1,346 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+)
+
+var errKeyNotFound = errors.New("key not found")
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// CryptoSuite is the cryptosuite of the PKCS11 security provider.
+// Private ECDSA keys of the token are looked up by SKI, label or identifier and sign with
+// the sessions of a session pool, so that digests are signed concurrently.
+// Other operations are performed by the PKCS11 BCCSP.
+type CryptoSuite struct {
+	core.CryptoSuite
+	pool *SessionPool
+	// local creates the public keys of the token's private keys, with which signatures are verified
+	local core.CryptoSuite
+	// keys caches the keys of the token by SKI, until signing with a key fails because of the session or the token
+	keys sync.Map
+}
+
+// NewCryptoSuite returns a cryptosuite signing with the keys of the session pool's token,
+// which delegates to the given cryptosuite of the PKCS11 BCCSP otherwise
+func NewCryptoSuite(cs core.CryptoSuite, pool *SessionPool, local core.CryptoSuite) *CryptoSuite {
+	return &CryptoSuite{CryptoSuite: cs, pool: pool, local: local}
+}
+
+// GetKey returns the private key of the token whose identifier (CKA_ID) is the given SKI,
+// or the key of the PKCS11 BCCSP with the SKI
+func (c *CryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	if k, ok := c.keys.Load(string(ski)); ok {
+		return k.(*key), nil
+	}
+	k, err := c.findKey(pkcs11.NewAttribute(pkcs11.CKA_ID, ski))
+	if err != nil {
+		if errors.Cause(err) == errKeyNotFound {
+			return c.CryptoSuite.GetKey(ski)
+		}
+		return nil, err
+	}
+	c.keys.Store(string(ski), k)
+	return k, nil
+}
+
+// GetKeyByLabel returns the private key of the token with the given label (CKA_LABEL)
+func (c *CryptoSuite) GetKeyByLabel(label string) (core.Key, error) {
+	if label == "" {
+		return nil, errors.New("key label is required")
+	}
+	k, err := c.findKey(pkcs11.NewAttribute(pkcs11.CKA_LABEL, label))
+	if err != nil {
+		return nil, errors.WithMessage(err, "key lookup by label failed")
+	}
+	return k, nil
+}
+
+// GetKeyByID returns the private key of the token with the given identifier (CKA_ID)
+func (c *CryptoSuite) GetKeyByID(id []byte) (core.Key, error) {
+	if len(id) == 0 {
+		return nil, errors.New("key identifier is required")
+	}
+	k, err := c.findKey(pkcs11.NewAttribute(pkcs11.CKA_ID, id))
+	if err != nil {
+		return nil, errors.WithMessage(err, "key lookup by identifier failed")
+	}
+	return k, nil
+}
+
+// Sign signs the digest with a session of the pool, or with the PKCS11 BCCSP for its own keys
+func (c *CryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	pk, ok := k.(*key)
+	if !ok {
+		return c.CryptoSuite.Sign(k, digest, opts)
+	}
+	if len(digest) == 0 {
+		return nil, errors.New("invalid digest, cannot be empty")
+	}
+
+	raw, err := c.sign(pk, digest)
+	if isKeyFailure(err) {
+		// The handle of the key may no longer be valid, e.g. if the token was removed and inserted again,
+		// so the key is evicted from the cache and signing is retried once with the handle looked up again
+		logger.Warnf("Looking up PKCS11 key again after signing failed: %s", err)
+		c.keys.Delete(string(pk.SKI()))
+		if refreshErr := c.refresh(pk); refreshErr != nil {
+			return nil, errors.WithMessage(err, "looking up the key again failed: "+refreshErr.Error())
+		}
+		raw, err = c.sign(pk, digest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(big.Int).SetBytes(raw[:len(raw)/2])
+	s := new(big.Int).SetBytes(raw[len(raw)/2:])
+	signature, err := utils.MarshalECDSASignature(r, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling signature failed")
+	}
+	// Fabric only accepts signatures with low S values
+	return utils.SignatureToLowS(pk.ecdsaPubKey, signature)
+}
+
+// Verify verifies the signature with the public key of the token's private keys, or with the PKCS11 BCCSP
+func (c *CryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.SignerOpts) (bool, error) {
+	if pk, ok := k.(*key); ok {
+		return c.local.Verify(pk.pub, signature, digest, opts)
+	}
+	return c.CryptoSuite.Verify(k, signature, digest, opts)
+}
+
+// HealthCheck returns an error if the token cannot be used
+func (c *CryptoSuite) HealthCheck() error {
+	return c.pool.HealthCheck()
+}
+
+// Close closes the sessions of the session pool
+func (c *CryptoSuite) Close() {
+	c.pool.Close()
+}
+
+// sign signs the digest with the private key using a session of the pool
+func (c *CryptoSuite) sign(pk *key, digest []byte) ([]byte, error) {
+	handle := pk.objectHandle()
+	var raw []byte
+	err := c.pool.Do(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		err := ctx.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, handle)
+		if err != nil {
+			return errors.Wrap(err, "sign initialization failed")
+		}
+		raw, err = ctx.Sign(session, digest)
+		return errors.Wrap(err, "signing failed")
+	})
+	return raw, err
+}
+
+// refresh looks up the handle of the private key again with the attribute by which it was found
+func (c *CryptoSuite) refresh(pk *key) error {
+	found, err := c.findKey(pk.attr)
+	if err != nil {
+		return errors.WithMessage(err, "key lookup failed")
+	}
+	if !bytes.Equal(found.SKI(), pk.SKI()) {
+		return errors.New("key lookup returned another key")
+	}
+	pk.setObjectHandle(found.handle)
+	return nil
+}
+
+// findKey finds the private key matching the attribute and the public key of the same key pair
+func (c *CryptoSuite) findKey(attr *pkcs11.Attribute) (*key, error) {
+	var handle pkcs11.ObjectHandle
+	var pubKey *ecdsa.PublicKey
+	err := c.pool.Do(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		var err error
+		handle, err = findObject(ctx, session, pkcs11.CKO_PRIVATE_KEY, attr)
+		if err != nil {
+			return err
+		}
+		// The key pair shares the identifier, or else the label
+		attrs, err := ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+		})
+		if err != nil {
+			return errors.Wrap(err, "getting private key attributes failed")
+		}
+		pairAttr := attr
+		for _, a := range attrs {
+			if len(a.Value) > 0 {
+				pairAttr = pkcs11.NewAttribute(a.Type, a.Value)
+				break
+			}
+		}
+		pubHandle, err := findObject(ctx, session, pkcs11.CKO_PUBLIC_KEY, pairAttr)
+		if err != nil {
+			return errors.WithMessage(err, "public key of key pair not found")
+		}
+		pubKey, err = ecPublicKey(ctx, session, pubHandle)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := c.local.KeyImport(pubKey, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, errors.WithMessage(err, "importing public key failed")
+	}
+	return &key{attr: attr, handle: handle, pub: pub, ecdsaPubKey: pubKey}, nil
+}
+
+// findObject finds the single object of the class matching the attribute
+func findObject(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, attr *pkcs11.Attribute) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_CLASS, class), attr}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, errors.Wrap(err, "find objects initialization failed")
+	}
+	objs, _, err := ctx.FindObjects(session, 2)
+	if finalErr := ctx.FindObjectsFinal(session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "finding objects failed")
+	}
+
+	switch len(objs) {
+	case 0:
+		return 0, errKeyNotFound
+	case 1:
+		return objs[0], nil
+	}
+	return 0, errors.New("more than one key matches")
+}
+
+// ecPublicKey reads the EC point of a public key, which some tokens like SoftHSM return DER encoded
+func ecPublicKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, handle pkcs11.ObjectHandle) (*ecdsa.PublicKey, error) {
+	attrs, err := ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting EC point failed")
+	}
+
+	var point []byte
+	var curve elliptic.Curve
+	for _, a := range attrs {
+		switch a.Type {
+		case pkcs11.CKA_EC_POINT:
+			point = a.Value
+		case pkcs11.CKA_EC_PARAMS:
+			oid := asn1.ObjectIdentifier{}
+			if _, err := asn1.Unmarshal(a.Value, &oid); err != nil {
+				return nil, errors.Wrap(err, "unmarshalling curve OID failed")
+			}
+			curve = namedCurve(oid)
+		}
+	}
+	if curve == nil {
+		return nil, errors.New("unsupported curve, only ECDSA keys on curves P-256, P-384 and P-521 are supported")
+	}
+
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		var octets []byte
+		if rest, err := asn1.Unmarshal(point, &octets); err == nil && len(rest) == 0 {
+			x, y = elliptic.Unmarshal(curve, octets)
+		}
+	}
+	if x == nil {
+		return nil, errors.New("unmarshalling EC point failed")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func namedCurve(oid asn1.ObjectIdentifier) elliptic.Curve {
+	switch {
+	case oid.Equal(oidNamedCurveP256):
+		return elliptic.P256()
+	case oid.Equal(oidNamedCurveP384):
+		return elliptic.P384()
+	case oid.Equal(oidNamedCurveP521):
+		return elliptic.P521()
+	}
+	return nil
+}
+
+// isKeyFailure returns true if signing failed because the session, the token or the handle of the key
+// can no longer be used
+func isKeyFailure(err error) bool {
+	if isSessionFailure(err) {
+		return true
+	}
+	switch errors.Cause(err) {
+	case pkcs11.Error(pkcs11.CKR_KEY_HANDLE_INVALID), pkcs11.Error(pkcs11.CKR_OBJECT_HANDLE_INVALID):
+		return true
+	}
+	return false
+}
+
+// key is a private ECDSA key of the token
+type key struct {
+	// attr is the attribute by which the key was found
+	attr        *pkcs11.Attribute
+	mutex       sync.RWMutex
+	handle      pkcs11.ObjectHandle
+	pub         core.Key
+	ecdsaPubKey *ecdsa.PublicKey
+}
+
+func (k *key) objectHandle() pkcs11.ObjectHandle {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.handle
+}
+
+func (k *key) setObjectHandle(handle pkcs11.ObjectHandle) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.handle = handle
+}
+
+// Bytes is not supported, since the private key never leaves the token
+func (k *key) Bytes() ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func (k *key) SKI() []byte {
+	return k.pub.SKI()
+}
+
+func (k *key) Symmetric() bool {
+	return false
+}
+
+func (k *key) Private() bool {
+	return true
+}
+
+func (k *key) PublicKey() (core.Key, error) {
+	return k.pub, nil
+}
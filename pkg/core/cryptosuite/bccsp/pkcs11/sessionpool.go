@@ -0,0 +1,228 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+)
+
+// DefaultSessionPoolSize is the number of sessions of a session pool whose size is not configured
+const DefaultSessionPoolSize = 10
+
+// SessionPool is a pool of logged in sessions with a token, which are used concurrently.
+// Sessions that have been idle for longer than the health check interval are checked
+// before they are used again, and sessions that fail are replaced by new ones.
+type SessionPool struct {
+	ctx                 *pkcs11.Ctx
+	slot                uint
+	pin                 string
+	healthCheckInterval time.Duration
+	// idle holds the sessions that are not in use
+	idle chan *session
+	// available holds a token for each session that may be used, limiting the number of open sessions
+	available chan struct{}
+	closed    int32
+}
+
+type session struct {
+	handle   pkcs11.SessionHandle
+	lastUsed time.Time
+}
+
+// NewSessionPool loads the PKCS11 library and returns a pool of sessions with the token of the given label
+func NewSessionPool(lib, label, pin string, config *core.PKCS11Config) (*SessionPool, error) {
+	if lib == "" {
+		return nil, errors.New("PKCS11 library is required")
+	}
+	if pin == "" {
+		return nil, errors.New("PKCS11 PIN is required")
+	}
+
+	ctx := pkcs11.New(lib)
+	if ctx == nil {
+		return nil, errors.Errorf("instantiating PKCS11 library %s failed", lib)
+	}
+	// The library is shared with the BCCSP, which may have initialized it already
+	if err := ctx.Initialize(); err != nil && err != pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return nil, errors.Wrapf(err, "initializing PKCS11 library %s failed", lib)
+	}
+	slot, err := findSlot(ctx, label)
+	if err != nil {
+		return nil, err
+	}
+
+	size := config.SessionPoolSize
+	if size <= 0 {
+		size = DefaultSessionPoolSize
+	}
+	p := &SessionPool{
+		ctx:                 ctx,
+		slot:                slot,
+		pin:                 pin,
+		healthCheckInterval: config.HealthCheckInterval,
+		idle:                make(chan *session, size),
+		available:           make(chan struct{}, size),
+	}
+	for i := 0; i < size; i++ {
+		p.available <- struct{}{}
+	}
+
+	// Open and log in a first session, so that a wrong PIN is reported right away
+	if err := p.HealthCheck(); err != nil {
+		return nil, err
+	}
+	logger.Debugf("Created PKCS11 session pool of %d sessions with token %s", size, label)
+
+	return p, nil
+}
+
+// Do runs the function with a session of the pool, waiting while all sessions are in use.
+// The session is closed instead of being returned to the pool if the function fails
+// because the session or the token can no longer be used.
+func (p *SessionPool) Do(fn func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error) error {
+	s, err := p.get(false)
+	if err != nil {
+		return err
+	}
+	err = fn(p.ctx, s.handle)
+	p.put(s, err)
+	return err
+}
+
+// HealthCheck checks a session of the pool, opening a new one if needed,
+// and returns an error if the token cannot be used
+func (p *SessionPool) HealthCheck() error {
+	s, err := p.get(true)
+	if err != nil {
+		return err
+	}
+	p.put(s, nil)
+	return nil
+}
+
+// Close closes the idle sessions of the pool; sessions in use are closed when they are done.
+// The PKCS11 library is not finalized, since it is shared with the BCCSP.
+func (p *SessionPool) Close() {
+	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		return
+	}
+	for {
+		select {
+		case s := <-p.idle:
+			p.ctx.CloseSession(s.handle)
+		default:
+			return
+		}
+	}
+}
+
+func (p *SessionPool) get(check bool) (*session, error) {
+	<-p.available
+	if atomic.LoadInt32(&p.closed) == 1 {
+		p.available <- struct{}{}
+		return nil, errors.New("PKCS11 session pool is closed")
+	}
+
+	select {
+	case s := <-p.idle:
+		err := p.check(s, check)
+		if err == nil {
+			return s, nil
+		}
+		logger.Warnf("Replacing PKCS11 session that failed the health check: %s", err)
+		p.ctx.CloseSession(s.handle)
+	default:
+	}
+
+	s, err := p.open()
+	if err != nil {
+		p.available <- struct{}{}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *SessionPool) put(s *session, err error) {
+	defer func() { p.available <- struct{}{} }()
+
+	if atomic.LoadInt32(&p.closed) == 1 || isSessionFailure(err) {
+		p.ctx.CloseSession(s.handle)
+		return
+	}
+	s.lastUsed = time.Now()
+	p.idle <- s
+}
+
+// check returns an error if the session is no longer logged in with the token.
+// Sessions are checked only when idle for longer than the health check interval, unless forced.
+func (p *SessionPool) check(s *session, force bool) error {
+	if !force && p.healthCheckInterval > 0 && time.Since(s.lastUsed) < p.healthCheckInterval {
+		return nil
+	}
+	info, err := p.ctx.GetSessionInfo(s.handle)
+	if err != nil {
+		return errors.Wrap(err, "getting session info failed")
+	}
+	if info.State != pkcs11.CKS_RO_USER_FUNCTIONS && info.State != pkcs11.CKS_RW_USER_FUNCTIONS {
+		return errors.Errorf("session is not logged in, state %d", info.State)
+	}
+	return nil
+}
+
+func (p *SessionPool) open() (*session, error) {
+	handle, err := p.ctx.OpenSession(p.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening PKCS11 session failed")
+	}
+	// Login applies to all sessions of the application, which may be logged in already
+	if err := p.ctx.Login(handle, pkcs11.CKU_USER, p.pin); err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		p.ctx.CloseSession(handle)
+		return nil, errors.Wrap(err, "PKCS11 login failed")
+	}
+	logger.Debugf("Opened PKCS11 session %d on slot %d", handle, p.slot)
+
+	return &session{handle: handle}, nil
+}
+
+func findSlot(ctx *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, errors.Wrap(err, "getting PKCS11 slot list failed")
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == label {
+			return slot, nil
+		}
+	}
+	return 0, errors.Errorf("could not find PKCS11 token with label %s", label)
+}
+
+// isSessionFailure returns true if the error means that the session or the token can no longer be used
+func isSessionFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	p11Err, ok := errors.Cause(err).(pkcs11.Error)
+	if !ok {
+		return false
+	}
+	switch p11Err {
+	case pkcs11.CKR_SESSION_HANDLE_INVALID, pkcs11.CKR_SESSION_CLOSED, pkcs11.CKR_USER_NOT_LOGGED_IN,
+		pkcs11.CKR_DEVICE_ERROR, pkcs11.CKR_DEVICE_REMOVED, pkcs11.CKR_TOKEN_NOT_PRESENT:
+		return true
+	}
+	return false
+}
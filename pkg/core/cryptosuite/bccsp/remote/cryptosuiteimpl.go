@@ -0,0 +1,222 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remote
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+// ProviderName is the name of the security provider of the remote signer crypto suite
+const ProviderName = "REMOTE"
+
+func init() {
+	if err := cryptosuite.RegisterProvider(ProviderName, GetSuiteByConfig); err != nil {
+		logger.Panicf("Could not register %s security provider: %v", ProviderName, err)
+	}
+}
+
+// KeyRef references a private key held by a signing service, by SKI or by label
+type KeyRef struct {
+	SKI   []byte
+	Label string
+}
+
+// SignerClient is a client of a signing service that holds private keys on behalf of the SDK
+type SignerClient interface {
+	// PublicKey returns the PKIX, DER-encoded public key of the referenced private key
+	PublicKey(ref KeyRef) ([]byte, error)
+	// Sign signs the digest with the referenced private key and returns a DER-encoded ECDSA signature
+	Sign(ref KeyRef, digest []byte) ([]byte, error)
+}
+
+//GetSuiteByConfig returns cryptosuite adaptor for the signing service of the given config
+func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
+	if config.SecurityProvider() != ProviderName {
+		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", config.SecurityProvider())
+	}
+
+	clientConfig, err := config.Client()
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to retrieve client config")
+	}
+	client, err := NewGRPCSignerClient(&clientConfig.BCCSP.Security.Remote)
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating signer client failed")
+	}
+	local, err := sw.GetSuite(config.SecurityLevel(), config.SecurityAlgorithm(), bccspSw.NewDummyKeyStore())
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating local cryptosuite failed")
+	}
+	logger.Debug("Initialized REMOTE cryptosuite")
+
+	return NewCryptoSuite(client, local), nil
+}
+
+// CryptoSuite is a cryptosuite whose private keys are held by a signing service.
+// Keys are obtained and digests are signed by the signing service,
+// while hashing, verification and public key imports are performed by the local cryptosuite.
+type CryptoSuite struct {
+	client SignerClient
+	local  core.CryptoSuite
+	// keys caches the keys obtained from the signing service by SKI
+	keys      sync.Map
+	closeOnce sync.Once
+}
+
+// NewCryptoSuite returns a cryptosuite using the signer client for private keys and the local cryptosuite otherwise
+func NewCryptoSuite(client SignerClient, local core.CryptoSuite) *CryptoSuite {
+	return &CryptoSuite{client: client, local: local}
+}
+
+// Close closes the connection to the signing service if the signer client has one, such as
+// GRPCSignerClient. The SDK closes its cryptosuite when it is closed.
+func (c *CryptoSuite) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if closer, ok := c.client.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return err
+}
+
+// KeyGen is not supported, since private keys are created by the signing service
+func (c *CryptoSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	return nil, errors.New("key generation is not supported by the REMOTE security provider")
+}
+
+// KeyImport imports a key in the local cryptosuite
+func (c *CryptoSuite) KeyImport(raw interface{}, opts core.KeyImportOpts) (core.Key, error) {
+	return c.local.KeyImport(raw, opts)
+}
+
+// GetKey returns the key of the signing service with the given SKI
+func (c *CryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	if k, ok := c.keys.Load(string(ski)); ok {
+		return k.(*key), nil
+	}
+	k, err := c.getKey(KeyRef{SKI: ski})
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(k.SKI(), ski) {
+		return nil, errors.Errorf("signing service returned key %x for SKI %x", k.SKI(), ski)
+	}
+	c.keys.Store(string(ski), k)
+	return k, nil
+}
+
+// GetKeyByLabel returns the key of the signing service with the given label
+func (c *CryptoSuite) GetKeyByLabel(label string) (core.Key, error) {
+	if label == "" {
+		return nil, errors.New("key label is required")
+	}
+	return c.getKey(KeyRef{Label: label})
+}
+
+func (c *CryptoSuite) getKey(ref KeyRef) (*key, error) {
+	der, err := c.client.PublicKey(ref)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting public key from signing service failed")
+	}
+	pubKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing public key from signing service failed")
+	}
+	ecdsaPubKey, ok := pubKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported public key type %T, only ECDSA keys are supported", pubKey)
+	}
+	pub, err := c.local.KeyImport(der, &bccsp.ECDSAPKIXPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		return nil, errors.WithMessage(err, "importing public key failed")
+	}
+	// Reference the key by SKI from now on, unless the signing service only knows its label
+	if ref.Label == "" {
+		ref.SKI = pub.SKI()
+	}
+	return &key{ref: ref, pub: pub, ecdsaPubKey: ecdsaPubKey}, nil
+}
+
+// Hash hashes the message with the local cryptosuite
+func (c *CryptoSuite) Hash(msg []byte, opts core.HashOpts) ([]byte, error) {
+	return c.local.Hash(msg, opts)
+}
+
+// GetHash returns a hash function of the local cryptosuite
+func (c *CryptoSuite) GetHash(opts core.HashOpts) (hash.Hash, error) {
+	return c.local.GetHash(opts)
+}
+
+// Sign signs the digest with the signing service, or with the local cryptosuite for local keys
+func (c *CryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	rk, ok := k.(*key)
+	if !ok {
+		return c.local.Sign(k, digest, opts)
+	}
+	if len(digest) == 0 {
+		return nil, errors.New("invalid digest, cannot be empty")
+	}
+	signature, err := c.client.Sign(rk.ref, digest)
+	if err != nil {
+		return nil, errors.WithMessage(err, "signing with signing service failed")
+	}
+	// Fabric only accepts signatures with low S values
+	return utils.SignatureToLowS(rk.ecdsaPubKey, signature)
+}
+
+// Verify verifies the signature with the local cryptosuite
+func (c *CryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.SignerOpts) (bool, error) {
+	if rk, ok := k.(*key); ok {
+		k = rk.pub
+	}
+	return c.local.Verify(k, signature, digest, opts)
+}
+
+// key is a private key held by the signing service
+type key struct {
+	ref         KeyRef
+	pub         core.Key
+	ecdsaPubKey *ecdsa.PublicKey
+}
+
+// Bytes is not supported, since the private key never leaves the signing service
+func (k *key) Bytes() ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func (k *key) SKI() []byte {
+	return k.pub.SKI()
+}
+
+func (k *key) Symmetric() bool {
+	return false
+}
+
+func (k *key) Private() bool {
+	return true
+}
+
+func (k *key) PublicKey() (core.Key, error) {
+	return k.pub, nil
+}
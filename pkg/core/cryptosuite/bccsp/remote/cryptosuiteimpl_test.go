@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remote
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/remote/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCryptoSuite(t *testing.T) {
+	server := mocks.NewMockSignerServer()
+	address, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Starting signer server failed: %v", err)
+	}
+	defer server.Stop()
+
+	ski, err := server.GenerateKey("signing-key")
+	if err != nil {
+		t.Fatalf("Generating key failed: %v", err)
+	}
+
+	client, err := NewGRPCSignerClient(&core.RemoteSignerConfig{URL: "grpc://" + address})
+	if err != nil {
+		t.Fatalf("Creating signer client failed: %v", err)
+	}
+	local, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Creating local cryptosuite failed: %v", err)
+	}
+	cs := NewCryptoSuite(client, local)
+
+	key, err := cs.GetKey(ski)
+	if err != nil {
+		t.Fatalf("GetKey failed: %v", err)
+	}
+	assert.Equal(t, ski, key.SKI())
+	assert.True(t, key.Private())
+	_, err = key.Bytes()
+	assert.Error(t, err, "private key must not be exportable")
+
+	byLabel, err := cs.GetKeyByLabel("signing-key")
+	if err != nil {
+		t.Fatalf("GetKeyByLabel failed: %v", err)
+	}
+	assert.Equal(t, ski, byLabel.SKI())
+
+	digest, err := cs.Hash([]byte("message"), &bccsp.SHA256Opts{})
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	for _, k := range []core.Key{key, byLabel} {
+		for i := 0; i < 10; i++ {
+			signature, err := cs.Sign(k, digest, nil)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			assert.True(t, isLowS(t, signature), "expected signature with low S")
+
+			valid, err := cs.Verify(k, signature, digest, nil)
+			assert.NoError(t, err)
+			assert.True(t, valid)
+
+			pubKey, err := k.PublicKey()
+			assert.NoError(t, err)
+			valid, err = local.Verify(pubKey, signature, digest, nil)
+			assert.NoError(t, err)
+			assert.True(t, valid)
+		}
+	}
+	assert.Equal(t, 20, server.SignCount())
+
+	// Local keys are used locally
+	localKey, err := local.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %v", err)
+	}
+	signature, err := cs.Sign(localKey, digest, nil)
+	assert.NoError(t, err)
+	valid, err := cs.Verify(localKey, signature, digest, nil)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, 20, server.SignCount())
+
+	// Closing the cryptosuite closes the connection to the signing service
+	assert.NoError(t, cs.Close())
+	assert.NoError(t, cs.Close(), "expected no error when closing twice")
+	_, err = cs.Sign(key, digest, nil)
+	assert.Error(t, err, "expected error after close")
+}
+
+func TestCryptoSuiteErrors(t *testing.T) {
+	server := mocks.NewMockSignerServer()
+	address, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Starting signer server failed: %v", err)
+	}
+	defer server.Stop()
+
+	client, err := NewGRPCSignerClient(&core.RemoteSignerConfig{URL: "grpc://" + address})
+	if err != nil {
+		t.Fatalf("Creating signer client failed: %v", err)
+	}
+	defer client.Close()
+	local, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Creating local cryptosuite failed: %v", err)
+	}
+	cs := NewCryptoSuite(client, local)
+
+	_, err = cs.GetKey([]byte{1, 2, 3})
+	assert.Error(t, err, "expected error for unknown key")
+	_, err = cs.GetKeyByLabel("unknown")
+	assert.Error(t, err, "expected error for unknown label")
+	_, err = cs.GetKeyByLabel("")
+	assert.Error(t, err, "expected error without label")
+	_, err = cs.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	assert.Error(t, err, "expected error for key generation")
+
+	ski, err := server.GenerateKey("")
+	if err != nil {
+		t.Fatalf("Generating key failed: %v", err)
+	}
+	key, err := cs.GetKey(ski)
+	if err != nil {
+		t.Fatalf("GetKey failed: %v", err)
+	}
+	_, err = cs.Sign(key, nil, nil)
+	assert.Error(t, err, "expected error for empty digest")
+
+	// The signing service is unavailable
+	server.Stop()
+	_, err = cs.Sign(key, []byte("digest"), nil)
+	assert.Error(t, err, "expected error for unavailable signing service")
+}
+
+func TestGetSuiteByConfig(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("SW").AnyTimes()
+	_, err := GetSuiteByConfig(mockConfig)
+	assert.Error(t, err, "expected error for another provider")
+
+	mockConfig = mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return(ProviderName).AnyTimes()
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
+	_, err = GetSuiteByConfig(mockConfig)
+	assert.Error(t, err, "expected error without signing service URL")
+}
+
+func TestNewGRPCSignerClientTLS(t *testing.T) {
+	_, err := NewGRPCSignerClient(&core.RemoteSignerConfig{
+		URL:        "grpcs://localhost:7070",
+		TLSCACerts: endpoint.TLSConfig{Path: "/nonexistent/ca.pem"},
+	})
+	assert.Error(t, err, "expected error for missing CA certs")
+
+	_, err = NewGRPCSignerClient(&core.RemoteSignerConfig{
+		URL:        "grpcs://localhost:7070",
+		TLSCACerts: endpoint.TLSConfig{Pem: "invalid"},
+	})
+	assert.Error(t, err, "expected error for invalid CA certs")
+
+	_, err = NewGRPCSignerClient(&core.RemoteSignerConfig{
+		URL:    "grpcs://localhost:7070",
+		Client: core.TLSKeyPair{Key: endpoint.TLSConfig{Pem: "invalid"}, Cert: endpoint.TLSConfig{Pem: "invalid"}},
+	})
+	assert.Error(t, err, "expected error for invalid client key pair")
+
+	client, err := NewGRPCSignerClient(&core.RemoteSignerConfig{URL: "grpcs://localhost:7070"})
+	assert.NoError(t, err)
+	client.Close()
+}
+
+func isLowS(t *testing.T, signature []byte) bool {
+	sig := struct{ R, S *big.Int }{}
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		t.Fatalf("Unmarshalling signature failed: %v", err)
+	}
+	halfOrder := new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+	return sig.S.Cmp(halfOrder) <= 0
+}
@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remote
+
+import (
+	reqContext "context"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/remote/signer"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultTimeout is the timeout of requests to the signing service, if none is configured
+const DefaultTimeout = 10 * time.Second
+
+// GRPCSignerClient is a signer client for signing services implementing the Signer gRPC service
+// defined in signer/signer.proto
+type GRPCSignerClient struct {
+	conn    *grpc.ClientConn
+	client  signer.SignerClient
+	timeout time.Duration
+}
+
+// NewGRPCSignerClient returns a client of the signing service at the configured URL.
+// The connection is established lazily. It is not made through the connector of the SDK, the crypto suite
+// being created before the infra provider, so the interceptors registered with the SDK are not applied to it.
+func NewGRPCSignerClient(config *core.RemoteSignerConfig) (*GRPCSignerClient, error) {
+	if config.URL == "" {
+		return nil, errors.New("signing service URL is required")
+	}
+
+	var opts []grpc.DialOption
+	if endpoint.IsTLSEnabled(config.URL) {
+		tlsConfig, err := signerTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(endpoint.ToAddress(config.URL), opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to signing service %s failed", config.URL)
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &GRPCSignerClient{conn: conn, client: signer.NewSignerClient(conn), timeout: timeout}, nil
+}
+
+// PublicKey returns the PKIX, DER-encoded public key of the referenced private key
+func (c *GRPCSignerClient) PublicKey(ref KeyRef) ([]byte, error) {
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.GetPublicKey(ctx, &signer.GetPublicKeyRequest{Key: keyRef(ref)})
+	if err != nil {
+		return nil, errors.Wrap(err, "GetPublicKey failed")
+	}
+	return resp.PublicKey, nil
+}
+
+// Sign signs the digest with the referenced private key
+func (c *GRPCSignerClient) Sign(ref KeyRef, digest []byte) ([]byte, error) {
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Sign(ctx, &signer.SignRequest{Key: keyRef(ref), Digest: digest})
+	if err != nil {
+		return nil, errors.Wrap(err, "Sign failed")
+	}
+	return resp.Signature, nil
+}
+
+// Close closes the connection to the signing service
+func (c *GRPCSignerClient) Close() error {
+	return c.conn.Close()
+}
+
+func keyRef(ref KeyRef) *signer.KeyRef {
+	return &signer.KeyRef{Ski: ref.SKI, Label: ref.Label}
+}
+
+func signerTLSConfig(config *core.RemoteSignerConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	caCerts, err := config.TLSCACerts.Bytes()
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading signing service TLS CA certs failed")
+	}
+	if len(caCerts) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCerts) {
+			return nil, errors.New("no signing service TLS CA certs found")
+		}
+	}
+
+	clientKey, err := config.Client.Key.Bytes()
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading signing service TLS client key failed")
+	}
+	clientCert, err := config.Client.Cert.Bytes()
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading signing service TLS client cert failed")
+	}
+	if len(clientKey) > 0 || len(clientCert) > 0 {
+		cert, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading signing service TLS client key pair failed")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
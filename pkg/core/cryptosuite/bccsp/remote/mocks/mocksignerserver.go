@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/remote/signer"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockSignerServer is a stand-in signing service that holds ECDSA keys in memory
+type MockSignerServer struct {
+	mutex      sync.RWMutex
+	keys       map[string]*ecdsa.PrivateKey
+	labels     map[string]string
+	signCount  int
+	grpcServer *grpc.Server
+}
+
+// NewMockSignerServer returns a signing service without keys
+func NewMockSignerServer() *MockSignerServer {
+	return &MockSignerServer{
+		keys:   make(map[string]*ecdsa.PrivateKey),
+		labels: make(map[string]string),
+	}
+}
+
+// Start starts serving on the given address, e.g. "127.0.0.1:0", and returns the address of the server
+func (s *MockSignerServer) Start(address string) (string, error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", errors.Wrap(err, "starting signer server failed")
+	}
+	s.grpcServer = grpc.NewServer()
+	signer.RegisterSignerServer(s.grpcServer, s)
+	go s.grpcServer.Serve(lis)
+
+	return lis.Addr().String(), nil
+}
+
+// Stop stops the server
+func (s *MockSignerServer) Stop() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+}
+
+// GenerateKey generates an ECDSA P-256 key with the given label, which may be empty, and returns its SKI
+func (s *MockSignerServer) GenerateKey(label string) ([]byte, error) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating key failed")
+	}
+	return s.AddKey(label, privKey), nil
+}
+
+// AddKey adds a key with the given label, which may be empty, and returns its SKI
+func (s *MockSignerServer) AddKey(label string, privKey *ecdsa.PrivateKey) []byte {
+	// The SKI is computed as by the SDK's software cryptosuite
+	hash := sha256.Sum256(elliptic.Marshal(privKey.Curve, privKey.X, privKey.Y))
+	ski := hash[:]
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[hex.EncodeToString(ski)] = privKey
+	if label != "" {
+		s.labels[label] = hex.EncodeToString(ski)
+	}
+	return ski
+}
+
+// SignCount returns the number of digests signed by the server
+func (s *MockSignerServer) SignCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.signCount
+}
+
+// GetPublicKey returns the public key of a key
+func (s *MockSignerServer) GetPublicKey(ctx context.Context, req *signer.GetPublicKeyRequest) (*signer.GetPublicKeyResponse, error) {
+	privKey, err := s.key(req.Key)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &signer.GetPublicKeyResponse{PublicKey: der}, nil
+}
+
+// Sign signs a digest with a key
+func (s *MockSignerServer) Sign(ctx context.Context, req *signer.SignRequest) (*signer.SignResponse, error) {
+	privKey, err := s.key(req.Key)
+	if err != nil {
+		return nil, err
+	}
+	r, sig, err := ecdsa.Sign(rand.Reader, privKey, req.Digest)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{r, sig})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.mutex.Lock()
+	s.signCount++
+	s.mutex.Unlock()
+
+	return &signer.SignResponse{Signature: der}, nil
+}
+
+func (s *MockSignerServer) key(ref *signer.KeyRef) (*ecdsa.PrivateKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	ski := hex.EncodeToString(ref.GetSki())
+	if ref.GetLabel() != "" {
+		ski = s.labels[ref.GetLabel()]
+	}
+	privKey, ok := s.keys[ski]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "key not found")
+	}
+	return privKey, nil
+}
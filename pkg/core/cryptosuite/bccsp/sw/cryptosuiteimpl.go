@@ -12,6 +12,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/wallet"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"github.com/pkg/errors"
 )
@@ -25,6 +26,19 @@ func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
 		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", config.SecurityProvider())
 	}
 
+	keyStore, err := walletKeyStore(config)
+	if err != nil {
+		return nil, err
+	}
+	if keyStore != nil {
+		suite, err := GetSuite(config.SecurityLevel(), config.SecurityAlgorithm(), keyStore)
+		if err != nil {
+			keyStore.Close()
+			return nil, err
+		}
+		return &walletCryptoSuite{CryptoSuite: suite, keyStore: keyStore}, nil
+	}
+
 	opts := getOptsByConfig(config)
 	bccsp, err := getBCCSPFromOpts(opts)
 	if err != nil {
@@ -64,6 +78,34 @@ func GetSuite(securityLevel int, hashFamily string, keyStore bccsp.KeyStore) (co
 	return wrapper.NewCryptoSuite(bccsp), nil
 }
 
+// walletCryptoSuite is a crypto suite whose keys are stored in a wallet
+type walletCryptoSuite struct {
+	core.CryptoSuite
+	keyStore *wallet.KeyStore
+}
+
+// Close closes the wallet of the key store
+func (c *walletCryptoSuite) Close() error {
+	return c.keyStore.Close()
+}
+
+// walletKeyStore returns the key store of the wallet if the credential store is an encrypted wallet
+func walletKeyStore(config core.Config) (*wallet.KeyStore, error) {
+	clientConfig, err := config.Client()
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to retrieve client config")
+	}
+	if clientConfig.CredentialStore.Type != wallet.CredentialStoreType {
+		return nil, nil
+	}
+	w, err := wallet.FromConfig(config)
+	if err != nil {
+		return nil, errors.WithMessage(err, "opening wallet failed")
+	}
+	logger.Debug("Initialized SW cryptosuite with wallet key store")
+	return w.KeyStore(), nil
+}
+
 //GetOptsByConfig Returns Factory opts for given SDK config
 func getOptsByConfig(c core.Config) *bccspSw.SwOpts {
 	opts := &bccspSw.SwOpts{
@@ -9,12 +9,17 @@ package sw
 import (
 	"bytes"
 	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/wallet"
 )
 
 func TestBadConfig(t *testing.T) {
@@ -42,6 +47,7 @@ func TestCryptoSuiteByConfigSW(t *testing.T) {
 	mockConfig.EXPECT().SecurityLevel().Return(256)
 	mockConfig.EXPECT().KeyStorePath().Return("")
 	mockConfig.EXPECT().Ephemeral().Return(true)
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
 
 	//Get cryptosuite using config
 	c, err := GetSuiteByConfig(mockConfig)
@@ -62,6 +68,7 @@ func TestCryptoSuiteByBadConfigSW(t *testing.T) {
 	mockConfig.EXPECT().SecurityLevel().Return(256)
 	mockConfig.EXPECT().KeyStorePath().Return("")
 	mockConfig.EXPECT().Ephemeral().Return(true)
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
 
 	//Get cryptosuite using config
 	_, err := GetSuiteByConfig(mockConfig)
@@ -70,6 +77,60 @@ func TestCryptoSuiteByBadConfigSW(t *testing.T) {
 	}
 }
 
+func TestCryptoSuiteByConfigWallet(t *testing.T) {
+	walletPath, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatalf("Failed to create wallet directory: %v", err)
+	}
+	defer os.RemoveAll(walletPath)
+	os.Setenv("TEST_WALLET_PASSPHRASE", "passphrase")
+	defer os.Unsetenv("TEST_WALLET_PASSPHRASE")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	clientConfig := &core.ClientConfig{}
+	clientConfig.CredentialStore.Type = wallet.CredentialStoreType
+	clientConfig.CredentialStore.PassphraseEnv = "TEST_WALLET_PASSPHRASE"
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("SW")
+	mockConfig.EXPECT().SecurityAlgorithm().Return("SHA2")
+	mockConfig.EXPECT().SecurityLevel().Return(256)
+	mockConfig.EXPECT().Client().Return(clientConfig, nil).AnyTimes()
+	mockConfig.EXPECT().CredentialStorePath().Return(walletPath)
+
+	//Get cryptosuite using config
+	c, err := GetSuiteByConfig(mockConfig)
+	if err != nil {
+		t.Fatalf("Not supposed to get error, but got: %v", err)
+	}
+
+	// Keys are persisted in the wallet
+	key, err := c.KeyGen(&bccsp.ECDSAP256KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %v", err)
+	}
+	if _, err := c.GetKey(key.SKI()); err != nil {
+		t.Fatalf("GetKey failed: %v", err)
+	}
+	names, err := ioutil.ReadDir(filepath.Join(walletPath, "keys"))
+	if err != nil || len(names) != 1 {
+		t.Fatalf("Expected the key in the wallet, got %v, %v", names, err)
+	}
+
+	// The wallet is closed with the crypto suite
+	closer, ok := c.(io.Closer)
+	if !ok {
+		t.Fatalf("Expected crypto suite to be closable, got %T", c)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := c.GetKey(key.SKI()); err == nil {
+		t.Fatal("Expected error for closed wallet")
+	}
+}
+
 func TestCryptoSuiteDefaultEphemeral(t *testing.T) {
 	c, err := GetSuiteWithDefaultEphemeral()
 	if err != nil {
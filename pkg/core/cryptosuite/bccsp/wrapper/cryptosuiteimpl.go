@@ -28,6 +28,15 @@ func GetKey(newkey bccsp.Key) core.Key {
 	return &key{newkey}
 }
 
+//GetBCCSPKey returns the bccsp.Key wrapped by a key of this cryptosuite adaptor
+func GetBCCSPKey(k core.Key) (bccsp.Key, bool) {
+	wrapped, ok := k.(*key)
+	if !ok {
+		return nil, false
+	}
+	return wrapped.key, true
+}
+
 // CryptoSuite provides a wrapper of BCCSP
 type CryptoSuite struct {
 	BCCSP bccsp.BCCSP
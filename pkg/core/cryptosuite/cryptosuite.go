@@ -9,14 +9,13 @@ package cryptosuite
 import (
 	"sync/atomic"
 
-	"errors"
-
 	"sync"
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	"github.com/pkg/errors"
 )
 
 var logger = logging.NewLogger("fabsdk/core")
@@ -77,6 +76,22 @@ func GetSHAOpts() core.HashOpts {
 	return &bccsp.SHAOpts{}
 }
 
+//GetHashOpts returns options for computing a hash of the given family (SHA2 or SHA3)
+//and security level (256 or 384), as configured by SecurityAlgorithm and SecurityLevel.
+func GetHashOpts(family string, level int) (core.HashOpts, error) {
+	switch {
+	case family == bccsp.SHA2 && level == 256:
+		return &bccsp.SHA256Opts{}, nil
+	case family == bccsp.SHA2 && level == 384:
+		return &bccsp.SHA384Opts{}, nil
+	case family == bccsp.SHA3 && level == 256:
+		return &bccsp.SHA3_256Opts{}, nil
+	case family == bccsp.SHA3 && level == 384:
+		return &bccsp.SHA3_384Opts{}, nil
+	}
+	return nil, errors.Errorf("hash family %s with security level %d is not supported", family, level)
+}
+
 //GetECDSAP256KeyGenOpts returns options for ECDSA key generation with curve P-256.
 func GetECDSAP256KeyGenOpts(ephemeral bool) core.KeyGenOpts {
 	return &bccsp.ECDSAP256KeyGenOpts{Temporary: ephemeral}
@@ -86,6 +86,30 @@ func TestHashOpts(t *testing.T) {
 
 }
 
+func TestHashOptsByFamilyAndLevel(t *testing.T) {
+	tests := []struct {
+		family    string
+		level     int
+		algorithm string
+	}{
+		{"SHA2", 256, "SHA256"},
+		{"SHA2", 384, "SHA384"},
+		{"SHA3", 256, "SHA3_256"},
+		{"SHA3", 384, "SHA3_384"},
+	}
+
+	for _, test := range tests {
+		hashOpts, err := GetHashOpts(test.family, test.level)
+		assert.NoError(t, err)
+		assert.Equal(t, test.algorithm, hashOpts.Algorithm())
+	}
+
+	_, err := GetHashOpts("SHA2", 512)
+	assert.Error(t, err, "expected error for unsupported security level")
+	_, err = GetHashOpts("MD5", 256)
+	assert.Error(t, err, "expected error for unsupported hash family")
+}
+
 func TestKeyGenOpts(t *testing.T) {
 
 	keygenOpts := GetECDSAP256KeyGenOpts(true)
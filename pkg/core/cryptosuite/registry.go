@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptosuite
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/pkg/errors"
+)
+
+// ProviderFactory creates the cryptosuite of a security provider according to the given config
+type ProviderFactory func(config core.Config) (core.CryptoSuite, error)
+
+var providersMutex sync.RWMutex
+
+// providers are the registered security providers by name; the software provider is always available
+var providers = map[string]ProviderFactory{
+	"SW": sw.GetSuiteByConfig,
+}
+
+// RegisterProvider registers the factory of a security provider, which can then be selected
+// with the client.BCCSP.security.default.provider config value.
+// Providers usually register themselves in an init function of their package.
+func RegisterProvider(name string, factory ProviderFactory) error {
+	if name == "" {
+		return errors.New("security provider name is required")
+	}
+	if factory == nil {
+		return errors.New("security provider factory is required")
+	}
+
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+
+	if _, ok := providers[name]; ok {
+		return errors.Errorf("security provider %s is already registered", name)
+	}
+	providers[name] = factory
+	return nil
+}
+
+// Providers returns the names of the registered security providers
+func Providers() []string {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+
+	var names []string
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetSuiteByConfig returns the cryptosuite of the registered security provider selected by the config
+func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
+	providersMutex.RLock()
+	factory, ok := providers[config.SecurityProvider()]
+	providersMutex.RUnlock()
+
+	if !ok {
+		return nil, errors.Errorf("Unsupported security provider requested: %s", config.SecurityProvider())
+	}
+	return factory(config)
+}
+
+// ProviderOptions returns the settings of the client.BCCSP.security.options config section,
+// through which security providers can be configured. Option names are lower case.
+func ProviderOptions(config core.Config) (map[string]interface{}, error) {
+	clientConfig, err := config.Client()
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to retrieve client config")
+	}
+	options := clientConfig.BCCSP.Security.Options
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+	return options, nil
+}
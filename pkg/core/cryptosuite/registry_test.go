@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptosuite
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterProvider(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	clientConfig := &core.ClientConfig{}
+	clientConfig.BCCSP.Security.Options = map[string]interface{}{"keyid": "alias/fabric"}
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("TESTKMS").AnyTimes()
+	mockConfig.EXPECT().Client().Return(clientConfig, nil).AnyTimes()
+
+	_, err := GetSuiteByConfig(mockConfig)
+	assert.Error(t, err, "expected error for unregistered provider")
+
+	var keyID interface{}
+	factory := func(config core.Config) (core.CryptoSuite, error) {
+		options, err := ProviderOptions(config)
+		if err != nil {
+			return nil, err
+		}
+		keyID = options["keyid"]
+		return sw.GetSuiteWithDefaultEphemeral()
+	}
+	assert.NoError(t, RegisterProvider("TESTKMS", factory))
+	defer unregisterProvider("TESTKMS")
+	assert.Error(t, RegisterProvider("TESTKMS", factory), "expected error for duplicate provider")
+	assert.Error(t, RegisterProvider("", factory), "expected error without name")
+	assert.Error(t, RegisterProvider("OTHER", nil), "expected error without factory")
+	assert.Contains(t, Providers(), "SW")
+	assert.Contains(t, Providers(), "TESTKMS")
+
+	suite, err := GetSuiteByConfig(mockConfig)
+	assert.NoError(t, err)
+	assert.NotNil(t, suite)
+	assert.Equal(t, "alias/fabric", keyID)
+}
+
+func TestProviderOptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Client().Return(&core.ClientConfig{}, nil)
+	options, err := ProviderOptions(mockConfig)
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+
+	mockConfig.EXPECT().Client().Return(nil, errors.New("no client config"))
+	_, err = ProviderOptions(mockConfig)
+	assert.Error(t, err, "expected error without client config")
+}
+
+func unregisterProvider(name string) {
+	providersMutex.Lock()
+	delete(providers, name)
+	providersMutex.Unlock()
+}
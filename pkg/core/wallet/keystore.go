@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wallet
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/pkg/errors"
+)
+
+// keysPrefix is the prefix of the names of key entries; as in the file key store, entries are named
+// keys/<ski>_sk for private keys, keys/<ski>_pk for public keys and keys/<ski>_key for AES keys
+const keysPrefix = "keys/"
+
+const (
+	privateKeySuffix = "_sk"
+	publicKeySuffix  = "_pk"
+	aesKeySuffix     = "_key"
+)
+
+// KeyStore is a BCCSP key store for software keys backed by a wallet.
+// Private keys are stored in PKCS#8 and public keys in PKIX form.
+type KeyStore struct {
+	wallet *Wallet
+}
+
+// NewKeyStore creates a key store backed by the wallet
+func NewKeyStore(wallet *Wallet) *KeyStore {
+	return &KeyStore{wallet: wallet}
+}
+
+// KeyStore returns a key store backed by the wallet
+func (w *Wallet) KeyStore() *KeyStore {
+	return NewKeyStore(w)
+}
+
+// ReadOnly returns always false
+func (s *KeyStore) ReadOnly() bool {
+	return false
+}
+
+// GetKey returns the key with the given SKI
+func (s *KeyStore) GetKey(ski []byte) (bccsp.Key, error) {
+	if len(ski) == 0 {
+		return nil, errors.New("invalid SKI, cannot be of zero length")
+	}
+	alias := keysPrefix + hex.EncodeToString(ski)
+
+	for _, suffix := range []string{privateKeySuffix, publicKeySuffix, aesKeySuffix} {
+		der, err := s.wallet.Get(alias + suffix)
+		if err == core.ErrKeyValueNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading key failed")
+		}
+		raw, err := parseKey(suffix, der)
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading key failed")
+		}
+		return sw.ImportKey(raw)
+	}
+	return nil, errors.Errorf("key with SKI %x not found in wallet %s", ski, s.wallet.Path())
+}
+
+// StoreKey stores the key
+func (s *KeyStore) StoreKey(k bccsp.Key) error {
+	if k == nil {
+		return errors.New("invalid key, it must be different from nil")
+	}
+	raw, err := sw.ExportKey(k)
+	if err != nil {
+		return errors.WithMessage(err, "storing key failed")
+	}
+
+	var suffix string
+	var der []byte
+	switch {
+	case k.Symmetric():
+		suffix = aesKeySuffix
+		der, err = raw.([]byte), nil
+	case k.Private():
+		suffix = privateKeySuffix
+		der, err = x509.MarshalPKCS8PrivateKey(raw)
+	default:
+		suffix = publicKeySuffix
+		der, err = x509.MarshalPKIXPublicKey(raw)
+	}
+	if err != nil {
+		return errors.Wrap(err, "marshalling key failed")
+	}
+	return s.wallet.Put(keysPrefix+hex.EncodeToString(k.SKI())+suffix, der)
+}
+
+// DeleteKey removes the private key with the given SKI
+func (s *KeyStore) DeleteKey(ski []byte) error {
+	if len(ski) == 0 {
+		return errors.New("invalid SKI, cannot be of zero length")
+	}
+	return s.wallet.Remove(keysPrefix + hex.EncodeToString(ski) + privateKeySuffix)
+}
+
+// Close closes the wallet of the key store
+func (s *KeyStore) Close() error {
+	return s.wallet.Close()
+}
+
+func parseKey(suffix string, der []byte) (interface{}, error) {
+	switch suffix {
+	case privateKeySuffix:
+		key, err := x509.ParsePKCS8PrivateKey(der)
+		return key, errors.Wrap(err, "parsing private key failed")
+	case publicKeySuffix:
+		key, err := x509.ParsePKIXPublicKey(der)
+		return key, errors.Wrap(err, "parsing public key failed")
+	default:
+		return der, nil
+	}
+}
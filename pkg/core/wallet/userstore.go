@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wallet
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/pkg/errors"
+)
+
+// usersPrefix is the prefix of the names of user entries; entries are named users/<user>@<org>
+const usersPrefix = "users/"
+
+// metadataPrefix is the prefix of the names of the entries holding the metadata of users, in JSON format
+const metadataPrefix = "metadata/"
+
+// UserStore stores the enrollment certificates of users in a wallet
+type UserStore struct {
+	wallet *Wallet
+}
+
+// NewUserStore creates a user store backed by the wallet
+func NewUserStore(wallet *Wallet) *UserStore {
+	return &UserStore{wallet: wallet}
+}
+
+// UserStore returns a user store backed by the wallet
+func (w *Wallet) UserStore() *UserStore {
+	return NewUserStore(w)
+}
+
+func userEntryName(id msp.UserIdentifier) string {
+	return usersPrefix + id.Name + "@" + id.MspID
+}
+
+func metadataEntryName(id msp.UserIdentifier) string {
+	return metadataPrefix + id.Name + "@" + id.MspID
+}
+
+// userIdentifierFromEntryName parses an entry name; user names may contain '@' but MSP IDs may not
+func userIdentifierFromEntryName(name string) (msp.UserIdentifier, bool) {
+	name = strings.TrimPrefix(name, usersPrefix)
+	i := strings.LastIndex(name, "@")
+	if i <= 0 || i == len(name)-1 {
+		return msp.UserIdentifier{}, false
+	}
+	return msp.UserIdentifier{Name: name[:i], MspID: name[i+1:]}, true
+}
+
+// Load returns the user with the given identifier
+func (s *UserStore) Load(id msp.UserIdentifier) (*msp.UserData, error) {
+	cert, err := s.wallet.Get(userEntryName(id))
+	if err != nil {
+		if err == core.ErrKeyValueNotFound {
+			return nil, msp.ErrUserNotFound
+		}
+		return nil, err
+	}
+	metadata, err := s.loadMetadata(id)
+	if err != nil {
+		return nil, err
+	}
+	return &msp.UserData{
+		MspID: id.MspID,
+		Name:  id.Name,
+		EnrollmentCertificate: cert,
+		Metadata:              metadata,
+	}, nil
+}
+
+func (s *UserStore) loadMetadata(id msp.UserIdentifier) (map[string]string, error) {
+	data, err := s.wallet.Get(metadataEntryName(id))
+	if err != nil {
+		if err == core.ErrKeyValueNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling user metadata failed")
+	}
+	return metadata, nil
+}
+
+// Store stores the enrollment certificate and the metadata of the user
+func (s *UserStore) Store(user *msp.UserData) error {
+	if user == nil {
+		return errors.New("user is nil")
+	}
+	id := msp.UserIdentifier{MspID: user.MspID, Name: user.Name}
+	if err := s.wallet.Put(userEntryName(id), user.EnrollmentCertificate); err != nil {
+		return err
+	}
+	if len(user.Metadata) == 0 {
+		return s.wallet.Remove(metadataEntryName(id))
+	}
+	metadata, err := json.Marshal(user.Metadata)
+	if err != nil {
+		return errors.Wrap(err, "marshalling user metadata failed")
+	}
+	return s.wallet.Put(metadataEntryName(id), metadata)
+}
+
+// Delete deletes the user with the given identifier
+func (s *UserStore) Delete(id msp.UserIdentifier) error {
+	if err := s.wallet.Remove(userEntryName(id)); err != nil {
+		return err
+	}
+	return s.wallet.Remove(metadataEntryName(id))
+}
+
+// List returns all users in the wallet
+func (s *UserStore) List() ([]*msp.UserData, error) {
+	names, err := s.wallet.Names(usersPrefix)
+	if err != nil {
+		return nil, errors.WithMessage(err, "listing users failed")
+	}
+
+	var users []*msp.UserData
+	for _, name := range names {
+		id, ok := userIdentifierFromEntryName(name)
+		if !ok {
+			continue
+		}
+		user, err := s.Load(id)
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading user failed")
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// Close closes the wallet of the user store
+func (s *UserStore) Close() error {
+	return s.wallet.Close()
+}
@@ -0,0 +1,577 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package wallet stores user enrollment certificates and private keys encrypted at rest.
+//
+// Each entry of a wallet is a file encrypted with AES-256-GCM. The encryption key is derived
+// from the passphrase of the wallet with scrypt; the salt and the scrypt parameters are stored
+// with each entry. A wallet provides a user store (see UserStore) and a key store for the
+// software crypto suite (see KeyStore).
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+const (
+	// CredentialStoreType is the type of the credential store in the client config that selects the wallet
+	CredentialStoreType = "wallet"
+	// DefaultPassphraseEnv is the environment variable that holds the passphrase of the wallet,
+	// unless another variable is configured
+	DefaultPassphraseEnv = "FABRIC_SDK_WALLET_PASSPHRASE"
+
+	// checkEntry is encrypted with the passphrase, to verify the passphrase when the wallet is opened
+	checkEntry = ".wallet"
+	// pendingEntry holds the new passphrase of a passphrase change that is not complete, encrypted with the
+	// old passphrase, so that the entries already encrypted with the new passphrase can be read
+	pendingEntry = ".wallet-pending"
+
+	envelopeVersion = 1
+	kdfScrypt       = "scrypt"
+	saltSize        = 32
+	keySize         = 32
+
+	dirMode  = 0700
+	fileMode = 0600
+
+	// Limits of the scrypt parameters read from entries, so that a tampered entry can not exhaust memory or CPU
+	maxScryptN      = 1 << 20
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30 // scrypt uses 128*N*r bytes
+)
+
+// Default scrypt parameters, see https://godoc.org/golang.org/x/crypto/scrypt
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	walletsMutex sync.Mutex
+	wallets      = make(map[string]*Wallet)
+)
+
+// envelope is the encrypted form of an entry
+type envelope struct {
+	Version int    `json:"version"`
+	KDF     kdf    `json:"kdf"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// kdf are the parameters of the key derivation
+type kdf struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// Wallet is a directory of entries encrypted with a passphrase
+type Wallet struct {
+	path string
+	// refs is the number of times the wallet is open, guarded by walletsMutex
+	refs int
+
+	mutex      sync.RWMutex
+	passphrase []byte
+	// pending is the new passphrase of a passphrase change that is not complete, or nil
+	pending []byte
+	// kdf are the key derivation parameters of new entries; a salt is generated per passphrase
+	kdf kdf
+	// keys are the derived keys by passphrase and salt
+	keysMutex sync.Mutex
+	keys      map[derivedKeyID][]byte
+}
+
+type derivedKeyID struct {
+	passphrase string
+	salt       string
+}
+
+// Open opens the wallet in the given directory, or creates it if the directory does not contain a wallet.
+// The wallet is shared by all users in the process, so that a passphrase change is seen by the
+// user store and the key store of the wallet. Each Open must be paired with a Close.
+func Open(path string, passphrase []byte) (*Wallet, error) {
+	if path == "" {
+		return nil, errors.New("wallet path is empty")
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("wallet passphrase is empty")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid wallet path")
+	}
+
+	walletsMutex.Lock()
+	defer walletsMutex.Unlock()
+
+	if w, ok := wallets[absPath]; ok {
+		w.mutex.RLock()
+		defer w.mutex.RUnlock()
+		if !bytes.Equal(w.passphrase, passphrase) {
+			return nil, errors.New("wrong wallet passphrase")
+		}
+		w.refs++
+		return w, nil
+	}
+
+	w := &Wallet{
+		path: absPath,
+		keys: make(map[derivedKeyID][]byte),
+	}
+	if err := w.setPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if err := w.check(); err != nil {
+		return nil, err
+	}
+	if err := w.loadPending(); err != nil {
+		return nil, err
+	}
+	w.refs = 1
+	wallets[absPath] = w
+	return w, nil
+}
+
+// Close closes the wallet. When it is closed as many times as it was opened, the wallet is
+// removed from the wallets of the process and its passphrases and derived keys are cleared.
+func (w *Wallet) Close() error {
+	walletsMutex.Lock()
+	defer walletsMutex.Unlock()
+
+	if w.refs == 0 {
+		return errors.New("wallet is closed")
+	}
+	w.refs--
+	if w.refs > 0 {
+		return nil
+	}
+	if wallets[w.path] == w {
+		delete(wallets, w.path)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.keysMutex.Lock()
+	defer w.keysMutex.Unlock()
+
+	w.passphrase, w.pending = nil, nil
+	for id, key := range w.keys {
+		for i := range key {
+			key[i] = 0
+		}
+		delete(w.keys, id)
+	}
+	return nil
+}
+
+// checkOpen returns an error if the wallet is closed. The caller must hold the wallet mutex.
+func (w *Wallet) checkOpen() error {
+	if w.passphrase == nil {
+		return errors.New("wallet is closed")
+	}
+	return nil
+}
+
+// FromConfig opens the wallet of the credential store in the client config. The passphrase is taken
+// from the environment variable configured in the credential store (DefaultPassphraseEnv if not configured).
+func FromConfig(config core.Config) (*Wallet, error) {
+	clientConfig, err := config.Client()
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to retrieve client config")
+	}
+	credentialStore := clientConfig.CredentialStore
+	if credentialStore.Type != CredentialStoreType {
+		return nil, errors.Errorf("credential store type is '%s', not '%s'", credentialStore.Type, CredentialStoreType)
+	}
+
+	passphraseEnv := credentialStore.PassphraseEnv
+	if passphraseEnv == "" {
+		passphraseEnv = DefaultPassphraseEnv
+	}
+	passphrase := os.Getenv(passphraseEnv)
+	if passphrase == "" {
+		return nil, errors.Errorf("wallet passphrase not set in environment variable %s", passphraseEnv)
+	}
+	return Open(config.CredentialStorePath(), []byte(passphrase))
+}
+
+// Path returns the directory of the wallet
+func (w *Wallet) Path() string {
+	return w.path
+}
+
+// ChangePassphrase re-encrypts all entries of the wallet with the new passphrase.
+// If re-encryption fails or is interrupted, the old passphrase still opens the wallet and all
+// entries remain readable. The change can be resumed by calling ChangePassphrase again with the
+// same passphrases; entries already encrypted with the new passphrase are kept.
+func (w *Wallet) ChangePassphrase(oldPassphrase, newPassphrase []byte) error {
+	if len(newPassphrase) == 0 {
+		return errors.New("new wallet passphrase is empty")
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.checkOpen(); err != nil {
+		return err
+	}
+	if !bytes.Equal(w.passphrase, oldPassphrase) {
+		return errors.New("wrong wallet passphrase")
+	}
+	if w.pending != nil && !bytes.Equal(w.pending, newPassphrase) {
+		return errors.New("a previous passphrase change must be resumed with the same new passphrase")
+	}
+
+	names, err := w.names()
+	if err != nil {
+		return err
+	}
+
+	// The new passphrase is recorded before any entry is re-encrypted with it
+	if err := w.write(pendingEntry, newPassphrase); err != nil {
+		return errors.WithMessage(err, "re-encrypting wallet failed")
+	}
+	w.pending = newPassphrase
+
+	oldKDF := w.kdf
+	if err := w.setPassphrase(newPassphrase); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := w.reencrypt(name, oldPassphrase); err != nil {
+			w.passphrase, w.kdf = oldPassphrase, oldKDF
+			return errors.WithMessage(err, "re-encrypting wallet failed")
+		}
+	}
+
+	// The check entry is written last, so that the old passphrase opens the wallet until all entries are re-encrypted
+	if err := w.write(checkEntry, []byte(checkEntry)); err != nil {
+		w.passphrase, w.kdf = oldPassphrase, oldKDF
+		return errors.WithMessage(err, "re-encrypting wallet failed")
+	}
+	w.pending = nil
+	if err := w.removePending(); err != nil {
+		logger.Warnf("Removing pending passphrase of wallet [%s] failed: %s", w.path, err)
+	}
+	logger.Infof("Changed passphrase of wallet [%s]", w.path)
+	return nil
+}
+
+// reencrypt encrypts the entry with the current passphrase, unless it is encrypted with it already
+func (w *Wallet) reencrypt(name string, oldPassphrase []byte) error {
+	if _, err := w.read(name, w.passphrase); err == nil {
+		// Already re-encrypted by an interrupted passphrase change
+		return nil
+	}
+	data, err := w.read(name, oldPassphrase)
+	if err != nil {
+		return err
+	}
+	return w.write(name, data)
+}
+
+// Get returns the decrypted entry with the given name, or core.ErrKeyValueNotFound
+func (w *Wallet) Get(name string) ([]byte, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	if err := w.checkOpen(); err != nil {
+		return nil, err
+	}
+	data, err := w.read(name, w.passphrase)
+	if err != nil && err != core.ErrKeyValueNotFound && w.pending != nil {
+		// The entry may have been re-encrypted by a passphrase change that is not complete
+		if data, pendingErr := w.read(name, w.pending); pendingErr == nil {
+			return data, nil
+		}
+	}
+	return data, err
+}
+
+// Put encrypts and stores the entry with the given name. Names are slash-separated paths relative to the wallet.
+func (w *Wallet) Put(name string, data []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.checkOpen(); err != nil {
+		return err
+	}
+	return w.write(name, data)
+}
+
+// Remove removes the entry with the given name
+func (w *Wallet) Remove(name string) error {
+	file, err := w.file(name)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.checkOpen(); err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "removing wallet entry %s failed", name)
+	}
+	return nil
+}
+
+// Names returns the names of the entries with the given prefix
+func (w *Wallet) Names(prefix string) ([]string, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	if err := w.checkOpen(); err != nil {
+		return nil, err
+	}
+	names, err := w.names()
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// check verifies the passphrase with the check entry, or creates the check entry for a new wallet
+func (w *Wallet) check() error {
+	_, err := w.read(checkEntry, w.passphrase)
+	if err == core.ErrKeyValueNotFound {
+		return w.write(checkEntry, []byte(checkEntry))
+	}
+	if err != nil {
+		return errors.New("wrong wallet passphrase")
+	}
+	return nil
+}
+
+// loadPending loads the new passphrase of a passphrase change that is not complete
+func (w *Wallet) loadPending() error {
+	pending, err := w.read(pendingEntry, w.passphrase)
+	if err == core.ErrKeyValueNotFound {
+		return nil
+	}
+	if err != nil {
+		// The check entry is written with the new passphrase before the pending entry is removed:
+		// a pending entry that is not encrypted with the passphrase is left from a completed change
+		logger.Warnf("Removing pending passphrase of a completed passphrase change of wallet [%s]", w.path)
+		return w.removePending()
+	}
+	w.pending = pending
+	return nil
+}
+
+func (w *Wallet) removePending() error {
+	if err := os.Remove(filepath.Join(w.path, pendingEntry)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing pending wallet passphrase failed")
+	}
+	return nil
+}
+
+// setPassphrase sets the passphrase and a new salt for new entries
+func (w *Wallet) setPassphrase(passphrase []byte) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return errors.Wrap(err, "generating salt failed")
+	}
+	w.passphrase = passphrase
+	w.kdf = kdf{Name: kdfScrypt, Salt: salt, N: scryptN, R: scryptR, P: scryptP}
+	return nil
+}
+
+// names returns the names of all entries except the check and pending entries
+func (w *Wallet) names() ([]string, error) {
+	var names []string
+	err := filepath.Walk(w.path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || strings.HasSuffix(file, ".tmp") {
+			return nil
+		}
+		name, err := filepath.Rel(w.path, file)
+		if err != nil {
+			return err
+		}
+		if name != checkEntry && name != pendingEntry {
+			names = append(names, filepath.ToSlash(name))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "listing wallet entries failed")
+	}
+	return names, nil
+}
+
+func (w *Wallet) file(name string) (string, error) {
+	file := filepath.Join(w.path, filepath.FromSlash(name))
+	if name == "" || !strings.HasPrefix(file, w.path+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid wallet entry name '%s'", name)
+	}
+	return file, nil
+}
+
+func (w *Wallet) read(name string, passphrase []byte) ([]byte, error) {
+	file, err := w.file(name)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, core.ErrKeyValueNotFound
+		}
+		return nil, errors.Wrapf(err, "reading wallet entry %s failed", name)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, errors.Wrapf(err, "invalid wallet entry %s", name)
+	}
+	if env.Version != envelopeVersion || env.KDF.Name != kdfScrypt {
+		return nil, errors.Errorf("unsupported wallet entry %s", name)
+	}
+	if err := validateKDF(env.KDF); err != nil {
+		return nil, errors.WithMessage(err, "invalid wallet entry "+name)
+	}
+	key, err := w.deriveKey(passphrase, env.KDF)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, errors.Errorf("invalid wallet entry %s", name)
+	}
+	data, err := gcm.Open(nil, env.Nonce, env.Data, []byte(name))
+	if err != nil {
+		return nil, errors.Errorf("decrypting wallet entry %s failed: wrong passphrase or corrupted entry", name)
+	}
+	return data, nil
+}
+
+// write encrypts the entry with the current passphrase and replaces the file atomically
+func (w *Wallet) write(name string, data []byte) error {
+	file, err := w.file(name)
+	if err != nil {
+		return err
+	}
+	key, err := w.deriveKey(w.passphrase, w.kdf)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return errors.Wrap(err, "generating nonce failed")
+	}
+	raw, err := json.Marshal(&envelope{
+		Version: envelopeVersion,
+		KDF:     w.kdf,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, data, []byte(name)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshalling wallet entry failed")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), dirMode); err != nil {
+		return errors.Wrap(err, "creating wallet directory failed")
+	}
+	tmpFile := file + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, raw, fileMode); err != nil {
+		return errors.Wrapf(err, "writing wallet entry %s failed", name)
+	}
+	if err := os.Rename(tmpFile, file); err != nil {
+		os.Remove(tmpFile)
+		return errors.Wrapf(err, "writing wallet entry %s failed", name)
+	}
+	return nil
+}
+
+// deriveKey derives the encryption key; keys are cached since scrypt is deliberately slow
+func (w *Wallet) deriveKey(passphrase []byte, params kdf) ([]byte, error) {
+	id := derivedKeyID{passphrase: string(passphrase), salt: string(params.Salt)}
+
+	w.keysMutex.Lock()
+	key, ok := w.keys[id]
+	w.keysMutex.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := scrypt.Key(passphrase, params.Salt, params.N, params.R, params.P, keySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving wallet key failed")
+	}
+
+	w.keysMutex.Lock()
+	w.keys[id] = key
+	w.keysMutex.Unlock()
+	return key, nil
+}
+
+// validateKDF verifies that the scrypt parameters of an entry are within limits
+func validateKDF(params kdf) error {
+	if len(params.Salt) != saltSize {
+		return errors.Errorf("invalid salt size %d", len(params.Salt))
+	}
+	if params.N <= 1 || params.N&(params.N-1) != 0 || params.N > maxScryptN {
+		return errors.Errorf("scrypt N %d is not a power of 2 in range [2, %d]", params.N, maxScryptN)
+	}
+	if params.R <= 0 || params.P <= 0 || params.P > maxScryptP {
+		return errors.Errorf("scrypt r %d or p %d is out of range", params.R, params.P)
+	}
+	if params.R > maxScryptMemory/128/params.N {
+		return errors.Errorf("scrypt N %d and r %d exceed the memory limit of %d bytes", params.N, params.R, maxScryptMemory)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher failed")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher failed")
+	}
+	return gcm, nil
+}
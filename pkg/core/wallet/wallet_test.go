@@ -0,0 +1,408 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// Cheaper key derivation for tests
+	scryptN = 1 << 10
+}
+
+func TestWallet(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := w.Put("users/user1@Org1MSP", []byte("secret certificate")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	data, err := w.Get("users/user1@Org1MSP")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret certificate"), data)
+
+	_, err = w.Get("users/user2@Org1MSP")
+	assert.Equal(t, core.ErrKeyValueNotFound, err)
+	_, err = w.Get("../outside")
+	assert.Error(t, err, "expected error for entry outside of the wallet")
+
+	// Entries are encrypted at rest
+	raw, err := ioutil.ReadFile(filepath.Join(path, "users", "user1@Org1MSP"))
+	if err != nil {
+		t.Fatalf("Reading entry failed: %v", err)
+	}
+	assert.False(t, bytes.Contains(raw, []byte("secret certificate")), "entry is not encrypted")
+
+	// Entries are bound to their name
+	if err := ioutil.WriteFile(filepath.Join(path, "users", "user2@Org1MSP"), raw, fileMode); err != nil {
+		t.Fatalf("Writing entry failed: %v", err)
+	}
+	_, err = w.Get("users/user2@Org1MSP")
+	assert.Error(t, err, "expected error for renamed entry")
+	assert.NoError(t, w.Remove("users/user2@Org1MSP"))
+
+	names, err := w.Names(usersPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users/user1@Org1MSP"}, names)
+
+	// The wallet is shared in the process
+	w2, err := Open(path, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.True(t, w == w2, "expected the same wallet")
+	_, err = Open(path, []byte("wrong"))
+	assert.Error(t, err, "expected error for wrong passphrase")
+
+	// The passphrase is verified when the wallet is opened
+	forget(path)
+	_, err = Open(path, []byte("wrong"))
+	assert.Error(t, err, "expected error for wrong passphrase")
+
+	_, err = Open("", []byte("passphrase"))
+	assert.Error(t, err, "expected error without path")
+	_, err = Open(path, nil)
+	assert.Error(t, err, "expected error without passphrase")
+}
+
+func TestClose(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	assert.NoError(t, w.Put("users/user1@Org1MSP", []byte("secret certificate")))
+	w2, err := Open(path, []byte("passphrase"))
+	assert.NoError(t, err)
+
+	// The wallet stays open until it is closed as many times as it was opened
+	assert.NoError(t, w2.Close())
+	_, err = w.Get("users/user1@Org1MSP")
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Close())
+	assert.Empty(t, w.keys, "expected derived keys to be cleared")
+	_, err = w.Get("users/user1@Org1MSP")
+	assert.Error(t, err, "expected error for closed wallet")
+	assert.Error(t, w.Put("users/user2@Org1MSP", []byte("data")), "expected error for closed wallet")
+	assert.Error(t, w.Close(), "expected error for closed wallet")
+
+	// The wallet is opened again from its directory
+	w3, err := Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	assert.False(t, w == w3, "expected a new wallet")
+	data, err := w3.Get("users/user1@Org1MSP")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret certificate"), data)
+	assert.NoError(t, w3.UserStore().Close())
+}
+
+func TestChangePassphrase(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("old"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, name := range []string{"users/user1@Org1MSP", "users/user2@Org1MSP", "keys/01_sk"} {
+		if err := w.Put(name, []byte(name)); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	// Simulate a passphrase change that was interrupted after re-encrypting an entry
+	interrupted := &Wallet{path: w.path, keys: make(map[derivedKeyID][]byte)}
+	if err := interrupted.setPassphrase([]byte("new")); err != nil {
+		t.Fatalf("setPassphrase failed: %v", err)
+	}
+	if err := interrupted.write("keys/01_sk", []byte("keys/01_sk")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	assert.Error(t, w.ChangePassphrase([]byte("wrong"), []byte("new")), "expected error for wrong passphrase")
+	assert.Error(t, w.ChangePassphrase([]byte("old"), nil), "expected error for empty passphrase")
+	if err := w.ChangePassphrase([]byte("old"), []byte("new")); err != nil {
+		t.Fatalf("ChangePassphrase failed: %v", err)
+	}
+
+	forget(path)
+	_, err = Open(path, []byte("old"))
+	assert.Error(t, err, "expected error for old passphrase")
+	w, err = Open(path, []byte("new"))
+	if err != nil {
+		t.Fatalf("Open with new passphrase failed: %v", err)
+	}
+	for _, name := range []string{"users/user1@Org1MSP", "users/user2@Org1MSP", "keys/01_sk"} {
+		data, err := w.Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(name), data)
+	}
+}
+
+func TestChangePassphraseFailure(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("old"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	names := []string{"keys/01_sk", "users/user1@Org1MSP", "users/user2@Org1MSP"}
+	for _, name := range names {
+		if err := w.Put(name, []byte(name)); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	// Re-encryption fails at the last entry, after the other entries are encrypted with the new passphrase
+	corrupted := filepath.Join(path, "users", "user2@Org1MSP")
+	raw, err := ioutil.ReadFile(corrupted)
+	if err != nil {
+		t.Fatalf("Reading entry failed: %v", err)
+	}
+	if err := ioutil.WriteFile(corrupted, []byte("corrupted"), fileMode); err != nil {
+		t.Fatalf("Writing entry failed: %v", err)
+	}
+	assert.Error(t, w.ChangePassphrase([]byte("old"), []byte("new")), "expected error for corrupted entry")
+
+	// All entries remain readable, in the process and after a restart with the old passphrase
+	for _, name := range names[:2] {
+		data, err := w.Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(name), data)
+	}
+	forget(path)
+	_, err = Open(path, []byte("new"))
+	assert.Error(t, err, "expected error for new passphrase of an incomplete change")
+	w, err = Open(path, []byte("old"))
+	if err != nil {
+		t.Fatalf("Open with old passphrase failed: %v", err)
+	}
+	for _, name := range names[:2] {
+		data, err := w.Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(name), data)
+	}
+	names, err = w.Names("")
+	assert.NoError(t, err)
+	assert.Len(t, names, 3, "expected the pending passphrase not to be listed")
+
+	// The change is resumed with the same passphrases
+	assert.Error(t, w.ChangePassphrase([]byte("old"), []byte("other")), "expected error for another new passphrase")
+	if err := ioutil.WriteFile(corrupted, raw, fileMode); err != nil {
+		t.Fatalf("Writing entry failed: %v", err)
+	}
+	if err := w.ChangePassphrase([]byte("old"), []byte("new")); err != nil {
+		t.Fatalf("ChangePassphrase failed: %v", err)
+	}
+	_, err = os.Stat(filepath.Join(path, pendingEntry))
+	assert.True(t, os.IsNotExist(err), "expected the pending passphrase to be removed")
+
+	forget(path)
+	w, err = Open(path, []byte("new"))
+	if err != nil {
+		t.Fatalf("Open with new passphrase failed: %v", err)
+	}
+	for _, name := range names {
+		data, err := w.Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(name), data)
+	}
+}
+
+func TestKDFLimits(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := w.Put("users/user1@Org1MSP", []byte("certificate")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	file := filepath.Join(path, "users", "user1@Org1MSP")
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Reading entry failed: %v", err)
+	}
+	for _, tamper := range []func(*kdf){
+		func(params *kdf) { params.N = 1 << 30 },
+		func(params *kdf) { params.N = 1000 },
+		func(params *kdf) { params.R = 1 << 20 },
+		func(params *kdf) { params.P = 0 },
+		func(params *kdf) { params.Salt = nil },
+	} {
+		var env envelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			t.Fatalf("Unmarshalling entry failed: %v", err)
+		}
+		tamper(&env.KDF)
+		tampered, err := json.Marshal(&env)
+		if err != nil {
+			t.Fatalf("Marshalling entry failed: %v", err)
+		}
+		if err := ioutil.WriteFile(file, tampered, fileMode); err != nil {
+			t.Fatalf("Writing entry failed: %v", err)
+		}
+		_, err = w.Get("users/user1@Org1MSP")
+		assert.Error(t, err, "expected error for scrypt parameters %v", env.KDF)
+	}
+}
+
+func TestUserStore(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	store := w.UserStore()
+
+	user := &msp.UserData{MspID: "Org1MSP", Name: "user@example.com", EnrollmentCertificate: []byte("cert"), Metadata: map[string]string{"origin": "test"}}
+	assert.NoError(t, store.Store(user))
+	assert.Error(t, store.Store(nil))
+
+	loaded, err := store.Load(msp.UserIdentifier{MspID: "Org1MSP", Name: "user@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, user, loaded)
+	_, err = store.Load(msp.UserIdentifier{MspID: "Org2MSP", Name: "user@example.com"})
+	assert.Equal(t, msp.ErrUserNotFound, err)
+
+	users, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*msp.UserData{user}, users)
+
+	// Metadata is removed when the user is stored without metadata
+	user.Metadata = nil
+	assert.NoError(t, store.Store(user))
+	loaded, err = store.Load(msp.UserIdentifier{MspID: "Org1MSP", Name: "user@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, user, loaded)
+
+	assert.NoError(t, store.Delete(msp.UserIdentifier{MspID: "Org1MSP", Name: "user@example.com"}))
+	_, err = store.Load(msp.UserIdentifier{MspID: "Org1MSP", Name: "user@example.com"})
+	assert.Equal(t, msp.ErrUserNotFound, err)
+	names, err := w.Names("")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestKeyStore(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	w, err := Open(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	csp, err := sw.New(256, "SHA2", w.KeyStore())
+	if err != nil {
+		t.Fatalf("Creating crypto suite failed: %v", err)
+	}
+
+	for _, opts := range []bccsp.KeyGenOpts{&bccsp.ECDSAP256KeyGenOpts{}, &bccsp.AES256KeyGenOpts{}} {
+		key, err := csp.KeyGen(opts)
+		if err != nil {
+			t.Fatalf("KeyGen failed: %v", err)
+		}
+		loaded, err := w.KeyStore().GetKey(key.SKI())
+		if err != nil {
+			t.Fatalf("GetKey failed: %v", err)
+		}
+		assert.Equal(t, key, loaded)
+	}
+
+	key, err := csp.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %v", err)
+	}
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey failed: %v", err)
+	}
+	assert.NoError(t, w.KeyStore().StoreKey(pubKey))
+	loaded, err := w.KeyStore().GetKey(pubKey.SKI())
+	assert.NoError(t, err)
+	assert.Equal(t, pubKey, loaded)
+
+	_, err = w.KeyStore().GetKey([]byte{1, 2, 3})
+	assert.Error(t, err, "expected error for unknown key")
+	assert.Error(t, w.KeyStore().StoreKey(nil))
+}
+
+func TestFromConfig(t *testing.T) {
+	path, cleanup := tempWallet(t)
+	defer cleanup()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	clientConfig := &core.ClientConfig{}
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Client().Return(clientConfig, nil).AnyTimes()
+	mockConfig.EXPECT().CredentialStorePath().Return(path).AnyTimes()
+
+	_, err := FromConfig(mockConfig)
+	assert.Error(t, err, "expected error for file credential store")
+
+	clientConfig.CredentialStore.Type = CredentialStoreType
+	os.Unsetenv(DefaultPassphraseEnv)
+	_, err = FromConfig(mockConfig)
+	assert.Error(t, err, "expected error without passphrase")
+
+	os.Setenv(DefaultPassphraseEnv, "passphrase")
+	defer os.Unsetenv(DefaultPassphraseEnv)
+	w, err := FromConfig(mockConfig)
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	absPath, _ := filepath.Abs(path)
+	assert.Equal(t, absPath, w.Path())
+}
+
+func tempWallet(t *testing.T) (string, func()) {
+	path, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatalf("Creating wallet directory failed: %v", err)
+	}
+	return path, func() {
+		forget(path)
+		os.RemoveAll(path)
+	}
+}
+
+// forget removes the wallet from the wallets of the process, as if the process was restarted
+func forget(path string) {
+	absPath, _ := filepath.Abs(path)
+	walletsMutex.Lock()
+	delete(wallets, absPath)
+	walletsMutex.Unlock()
+}
@@ -271,7 +271,7 @@ func filterResponses(responses []*fab.TransactionProposalResponse, errs error) (
 		if response.Status == http.StatusOK {
 			filteredResponses = append(filteredResponses, response)
 		} else {
-			errs = multi.Append(errs, errors.Errorf("bad status from %s (%d)", response.Endorser, response.Status))
+			errs = multi.Append(errs, errors.Errorf("bad status from %s (%d): %s", response.Endorser, response.Status, response.ProposalResponse.GetResponse().GetMessage()))
 		}
 	}
 
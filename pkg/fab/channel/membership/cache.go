@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package membership
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/pkg/errors"
+)
+
+// DefaultCacheSize is the number of identities kept by a cache whose size is not positive
+const DefaultCacheSize = 1000
+
+type cacheKey [sha256.Size]byte
+
+// Cache is a bounded LRU cache of deserialized identities and their validation results,
+// keyed by the hash of the serialized identity. It is shared by the memberships of a channel
+// and invalidated when they are created with a channel config whose MSPs differ.
+type Cache struct {
+	size    int
+	mutex   sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+	// configHash identifies the MSPs of the channel config of the cached identities
+	configHash []byte
+	// generation changes whenever the cache is invalidated, so that memberships
+	// created with a previous channel config no longer use it
+	generation uint64
+}
+
+// cacheEntry holds an identity deserialized by the MSPs of a channel config
+type cacheEntry struct {
+	key   cacheKey
+	id    msp.Identity
+	cert  *x509.Certificate
+	mspID string
+	// validUntil is the time until which the identity is known to be valid; zero if not validated
+	validUntil time.Time
+}
+
+// NewCache returns a cache of up to size identities
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{
+		size:    size,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Len returns the number of cached identities
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
+
+// Purge removes all identities from the cache
+func (c *Cache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.purge()
+}
+
+func (c *Cache) purge() {
+	c.entries = make(map[cacheKey]*list.Element)
+	c.lru.Init()
+	c.generation++
+}
+
+// useConfig invalidates the cache if the MSPs of the channel config differ from those of the cached identities,
+// and returns the generation of the cache for the channel config
+func (c *Cache) useConfig(cfg fab.ChannelCfg) (uint64, error) {
+	configHash, err := mspsHash(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if string(configHash) != string(c.configHash) {
+		if c.configHash != nil {
+			logger.Debugf("MSPs of channel [%s] have changed, invalidating identity cache", cfg.ID())
+		}
+		c.purge()
+		c.configHash = configHash
+	}
+	return c.generation, nil
+}
+
+// get returns a copy of the entry of the serialized identity, if cached for the given generation
+func (c *Cache) get(generation uint64, key cacheKey) (cacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return cacheEntry{}, false
+	}
+	elem, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return *elem.Value.(*cacheEntry), true
+}
+
+// put caches the entry for the given generation, evicting the least recently used entry if the cache is full
+func (c *Cache) put(generation uint64, entry cacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if elem, ok := c.entries[entry.key]; ok {
+		*elem.Value.(*cacheEntry) = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(&entry)
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// mspsHash returns the hash of the MSP configs of the channel config
+func mspsHash(cfg fab.ChannelCfg) ([]byte, error) {
+	h := sha256.New()
+	for _, mspConfig := range cfg.MSPs() {
+		b, err := proto.Marshal(mspConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal MSP config failed")
+		}
+		h.Write(b)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package membership
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheEviction(t *testing.T) {
+	cache := NewCache(2)
+	cfg := mocks.NewMockChannelCfg("")
+	generation, err := cache.useConfig(cfg)
+	assert.Nil(t, err)
+
+	keys := []cacheKey{sha256.Sum256([]byte("a")), sha256.Sum256([]byte("b")), sha256.Sum256([]byte("c"))}
+	cache.put(generation, cacheEntry{key: keys[0], mspID: "a"})
+	cache.put(generation, cacheEntry{key: keys[1], mspID: "b"})
+
+	// Using the first entry makes the second one the least recently used
+	_, ok := cache.get(generation, keys[0])
+	assert.True(t, ok)
+	cache.put(generation, cacheEntry{key: keys[2], mspID: "c"})
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.get(generation, keys[1])
+	assert.False(t, ok, "expected least recently used entry to be evicted")
+	e, ok := cache.get(generation, keys[0])
+	assert.True(t, ok)
+	assert.Equal(t, "a", e.mspID)
+
+	// Entries of a previous generation are neither returned nor stored
+	cache.Purge()
+	assert.Equal(t, 0, cache.Len())
+	cache.put(generation, cacheEntry{key: keys[0]})
+	assert.Equal(t, 0, cache.Len())
+	_, ok = cache.get(generation, keys[0])
+	assert.False(t, ok)
+}
+
+func TestCacheInvalidatedOnConfigChange(t *testing.T) {
+	ctx := mocks.NewMockProviderContext()
+	cfg := mocks.NewMockChannelCfg("mychannel")
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig("GoodMSP", []byte(validRootCA))}
+	endorser := serializedEndorser(t, "GoodMSP")
+
+	cache := NewCache(10)
+	m, err := New(Context{Providers: ctx}, cfg, WithCache(cache))
+	assert.Nil(t, err)
+	assert.Nil(t, m.Validate(endorser))
+	assert.Nil(t, m.Validate(endorser), "expected cached validation result")
+	assert.Equal(t, 1, cache.Len())
+
+	// A membership with the same MSPs shares the cached identities
+	m2, err := New(Context{Providers: ctx}, cfg, WithCache(cache))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cache.Len())
+	assert.Nil(t, m2.Validate(endorser))
+
+	// Changing the MSPs invalidates the cache, so that the identity is no longer valid
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig("OtherMSP", []byte(validRootCA))}
+	m3, err := New(Context{Providers: ctx}, cfg, WithCache(cache))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, cache.Len())
+	assert.NotNil(t, m3.Validate(endorser))
+	assert.NotNil(t, m3.Verify(endorser, []byte("test"), []byte("test1")))
+
+	// The membership of the previous channel config does not store its identities in the cache anymore
+	assert.Nil(t, m.Validate(endorser))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCacheValidUntilChainExpiry(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issueUntil(t, 2, ca.cert.NotAfter.Add(24*time.Hour))
+	serializedID := serializeIdentity(t, cert)
+
+	ctx := mocks.NewMockProviderContext()
+	cfg := mocks.NewMockChannelCfg("")
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig(testMSPID, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))}
+	cache := NewCache(10)
+	m, err := New(Context{Providers: ctx}, cfg, WithCache(cache))
+	if err != nil {
+		t.Fatalf("Failed to create membership: %s", err)
+	}
+	assert.Nil(t, m.Validate(serializedID))
+
+	// The validation result is cached until the CA certificate, which expires first, expires
+	e, ok := cache.get(m.(*identityImpl).generation, sha256.Sum256(serializedID))
+	assert.True(t, ok, "expected cached identity")
+	assert.True(t, e.validUntil.Equal(ca.cert.NotAfter), "expected identity valid until the CA certificate expires, got %s", e.validUntil)
+}
+
+func TestVerifyWithCache(t *testing.T) {
+	m, endorser, msg, signature := newBenchmarkMembership(t, WithCache(NewCache(10)))
+	for n := 0; n < 2; n++ {
+		assert.Nil(t, m.Verify(endorser, msg, signature), "expected valid signature")
+		assert.NotNil(t, m.Verify(endorser, []byte("other"), signature), "expected invalid signature")
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	benchmarkValidate(b)
+}
+
+func BenchmarkValidateWithCache(b *testing.B) {
+	benchmarkValidate(b, WithCache(NewCache(DefaultCacheSize)))
+}
+
+func BenchmarkVerify(b *testing.B) {
+	benchmarkVerify(b)
+}
+
+func BenchmarkVerifyWithCache(b *testing.B) {
+	benchmarkVerify(b, WithCache(NewCache(DefaultCacheSize)))
+}
+
+func benchmarkValidate(b *testing.B, opts ...Option) {
+	m, endorser, _, _ := newBenchmarkMembership(b, opts...)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := m.Validate(endorser); err != nil {
+			b.Fatalf("Validation failed: %s", err)
+		}
+	}
+}
+
+func benchmarkVerify(b *testing.B, opts ...Option) {
+	m, endorser, msg, signature := newBenchmarkMembership(b, opts...)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := m.Verify(endorser, msg, signature); err != nil {
+			b.Fatalf("Verification failed: %s", err)
+		}
+	}
+}
+
+// newBenchmarkMembership returns a membership, a serialized endorser and its signature over a message
+func newBenchmarkMembership(t testing.TB, opts ...Option) (fab.ChannelMembership, []byte, []byte, []byte) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to get cryptosuite: %s", err)
+	}
+	ctx := mocks.NewMockProviderContextCustom(mocks.NewMockConfig(), cs, nil, nil, nil)
+	cfg := mocks.NewMockChannelCfg("mychannel")
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig("GoodMSP", []byte(validRootCA))}
+	m, err := New(Context{Providers: ctx}, cfg, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create membership: %s", err)
+	}
+
+	msg := []byte("proposal response payload")
+	return m, serializedEndorser(t, "GoodMSP"), msg, sign(t, cs, msg)
+}
+
+func sign(t testing.TB, cs core.CryptoSuite, msg []byte) []byte {
+	block, _ := pem.Decode([]byte(keyPem))
+	key, err := cs.KeyImport(block.Bytes, &bccsp.ECDSAPrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed to import key: %s", err)
+	}
+	digest, err := cs.Hash(msg, &bccsp.SHA256Opts{})
+	if err != nil {
+		t.Fatalf("Failed to hash message: %s", err)
+	}
+	signature, err := cs.Sign(key, digest, nil)
+	if err != nil {
+		t.Fatalf("Failed to sign digest: %s", err)
+	}
+	return signature
+}
+
+func serializedEndorser(t testing.TB, mspID string) []byte {
+	endorser, err := proto.Marshal(&mb.SerializedIdentity{Mspid: mspID, IdBytes: []byte(certPem)})
+	if err != nil {
+		t.Fatalf("Failed to marshal identity: %s", err)
+	}
+	return endorser
+}
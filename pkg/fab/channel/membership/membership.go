@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package membership
 
 import (
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/msp"
@@ -23,6 +25,15 @@ var logger = logging.NewLogger("fabsdk/fab")
 
 type identityImpl struct {
 	mspManager msp.MSPManager
+	revocation *RevocationSource
+	caCerts    []*x509.Certificate // CA certificates of the MSPs, which must sign the CRLs of the revocation source
+	cache      *Cache
+	// roots and intermediates are the CA certificates of the MSPs, which build the certification chains
+	// of the identities whose validation results are cached
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+	// generation is the generation of the cache for the channel config of the membership
+	generation uint64
 }
 
 // Context holds the providers
@@ -30,31 +41,223 @@ type Context struct {
 	core.Providers
 }
 
+// Option describes a functional parameter for the New constructor
+type Option func(*identityImpl)
+
+// WithRevocationSource rejects identities whose certificate is revoked by a current CRL of the source,
+// in addition to the CRLs of the channel config
+func WithRevocationSource(source *RevocationSource) Option {
+	return func(i *identityImpl) {
+		i.revocation = source
+	}
+}
+
+// WithCache keeps deserialized identities and their validation results in the cache, which is shared
+// by the memberships of the channel. The cache is invalidated if the MSPs of the channel config change.
+func WithCache(cache *Cache) Option {
+	return func(i *identityImpl) {
+		i.cache = cache
+	}
+}
+
 // New member identity
-func New(ctx Context, cfg fab.ChannelCfg) (fab.ChannelMembership, error) {
+func New(ctx Context, cfg fab.ChannelCfg, opts ...Option) (fab.ChannelMembership, error) {
 	m, err := createMSPManager(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &identityImpl{mspManager: m}, nil
+	i := &identityImpl{mspManager: m}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if i.revocation != nil || i.cache != nil {
+		roots, intermediates, err := caCertificates(cfg.MSPs())
+		if err != nil {
+			return nil, err
+		}
+		if i.revocation != nil {
+			i.caCerts = append(roots, intermediates...)
+		}
+		if i.cache != nil {
+			i.roots, i.intermediates = certPool(roots), certPool(intermediates)
+		}
+	}
+	if i.cache != nil {
+		i.generation, err = i.cache.useConfig(cfg)
+		if err != nil {
+			return nil, errors.WithMessage(err, "identity cache failed")
+		}
+	}
+	return i, nil
 }
 
 func (i *identityImpl) Validate(serializedID []byte) error {
-	id, err := i.mspManager.DeserializeIdentity(serializedID)
+	e, err := i.deserialize(serializedID)
 	if err != nil {
 		return err
 	}
 
-	return id.Validate()
+	if err := i.checkRevocation(e, serializedID); err != nil {
+		return err
+	}
+
+	if i.cache == nil {
+		return e.id.Validate()
+	}
+	if time.Now().Before(e.validUntil) {
+		return nil
+	}
+	if err := e.id.Validate(); err != nil {
+		return err
+	}
+	// The identity remains valid until a certificate of its chain expires, unless the channel config changes
+	cert, err := e.certificate(serializedID)
+	if err != nil {
+		return err
+	}
+	e.validUntil = i.validUntil(cert)
+	i.cache.put(i.generation, *e)
+	return nil
+}
+
+// validUntil returns the earliest expiry of the certificate and the CA certificates of its chains,
+// or the current time if the chains cannot be built, so that the identity is validated again
+func (i *identityImpl) validUntil(cert *x509.Certificate) time.Time {
+	now := time.Now()
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Roots:         i.roots,
+		Intermediates: i.intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		logger.Debugf("Building certification chain of identity failed, its validation result is not cached: %s", err)
+		return now
+	}
+
+	validUntil := cert.NotAfter
+	for _, chain := range chains {
+		for _, c := range chain {
+			if c.NotAfter.Before(validUntil) {
+				validUntil = c.NotAfter
+			}
+		}
+	}
+	return validUntil
 }
 
 func (i *identityImpl) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	e, err := i.deserialize(serializedID)
+	if err != nil {
+		return err
+	}
+
+	if err := i.checkRevocation(e, serializedID); err != nil {
+		return err
+	}
+
+	return e.id.Verify(msg, sig)
+}
+
+// deserialize returns the cached entry of the serialized identity, or deserializes the identity
+// with the MSPs of the channel config and caches it
+func (i *identityImpl) deserialize(serializedID []byte) (*cacheEntry, error) {
+	var key cacheKey
+	if i.cache != nil {
+		key = sha256.Sum256(serializedID)
+		if e, ok := i.cache.get(i.generation, key); ok {
+			return &e, nil
+		}
+	}
+
 	id, err := i.mspManager.DeserializeIdentity(serializedID)
+	if err != nil {
+		return nil, err
+	}
+	e := &cacheEntry{key: key, id: id}
+	if i.cache != nil {
+		if _, err := e.certificate(serializedID); err != nil {
+			return nil, err
+		}
+		i.cache.put(i.generation, *e)
+	}
+	return e, nil
+}
+
+// checkRevocation returns an error if the certificate of the identity is revoked by the revocation source
+func (i *identityImpl) checkRevocation(e *cacheEntry, serializedID []byte) error {
+	if i.revocation == nil {
+		return nil
+	}
+
+	cert, err := e.certificate(serializedID)
 	if err != nil {
 		return err
 	}
 
-	return id.Verify(msg, sig)
+	revoked, err := i.revocation.Revoked(cert, i.caCerts)
+	if err != nil {
+		return errors.WithMessage(err, "checking revocation of identity certificate failed")
+	}
+	if revoked {
+		return errors.Errorf("the certificate with serial number %s of identity from MSP [%s] has been revoked", cert.SerialNumber, e.mspID)
+	}
+	return nil
+}
+
+// certificate returns the certificate of the serialized identity of the entry, which is parsed once
+func (e *cacheEntry) certificate(serializedID []byte) (*x509.Certificate, error) {
+	if e.cert == nil {
+		cert, mspID, err := certificate(serializedID)
+		if err != nil {
+			return nil, err
+		}
+		e.cert, e.mspID = cert, mspID
+	}
+	return e.cert, nil
+}
+
+// certificate returns the certificate and MSP ID of a serialized identity
+func certificate(serializedID []byte) (*x509.Certificate, string, error) {
+	sID := &mb.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedID, sID); err != nil {
+		return nil, "", errors.Wrap(err, "unmarshal serialized identity failed")
+	}
+	block, _ := pem.Decode(sID.IdBytes)
+	if block == nil {
+		return nil, "", errors.New("decoding identity certificate failed")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "parsing identity certificate failed")
+	}
+	return cert, sID.Mspid, nil
+}
+
+// caCertificates returns the root and intermediate CA certificates of the MSP configs
+func caCertificates(mspConfigs []*mb.MSPConfig) ([]*x509.Certificate, []*x509.Certificate, error) {
+	var roots, intermediates []*x509.Certificate
+	for _, config := range mspConfigs {
+		fabricConfig := &mb.FabricMSPConfig{}
+		if err := proto.Unmarshal(config.Config, fabricConfig); err != nil {
+			return nil, nil, errors.Wrap(err, "unmarshal FabricMSPConfig from config failed")
+		}
+		for _, pemCerts := range fabricConfig.RootCerts {
+			roots = append(roots, parseCertificates(pemCerts)...)
+		}
+		for _, pemCerts := range fabricConfig.IntermediateCerts {
+			intermediates = append(intermediates, parseCertificates(pemCerts)...)
+		}
+	}
+	return roots, intermediates, nil
+}
+
+func certPool(certs []*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool
 }
 
 func createMSPManager(ctx Context, cfg fab.ChannelCfg) (msp.MSPManager, error) {
@@ -144,6 +347,14 @@ func loadMSPs(mspConfigs []*mb.MSPConfig, cs core.CryptoSuite) ([]msp.MSP, error
 
 //addCertsToConfig adds cert bytes to config TLSCACertPool
 func addCertsToConfig(config core.Config, pemCerts []byte) {
+	for _, cert := range parseCertificates(pemCerts) {
+		config.TLSCACertPool(cert)
+	}
+}
+
+// parseCertificates returns the certificates of the PEM blocks, skipping invalid certificates
+func parseCertificates(pemCerts []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
 	for len(pemCerts) > 0 {
 		var block *pem.Block
 		block, pemCerts = pem.Decode(pemCerts)
@@ -158,6 +369,7 @@ func addCertsToConfig(config core.Config, pemCerts []byte) {
 		if err != nil {
 			continue
 		}
-		config.TLSCACertPool(cert)
+		certs = append(certs, cert)
 	}
+	return certs
 }
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
@@ -50,6 +51,24 @@ func TestNewMembership(t *testing.T) {
 	assert.NotNil(t, m.Verify(badEndorser, []byte("test"), []byte("test1")))
 }
 
+func TestVerifyWithMSPIdentity(t *testing.T) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to get cryptosuite: %s", err)
+	}
+	ctx := mocks.NewMockProviderContextCustom(mocks.NewMockConfig(), cs, nil, nil, nil)
+	endorser := serializedEndorser(t, "GoodMSP")
+	signature := sign(t, cs, []byte("test"))
+
+	// Signatures are verified by the MSP identity
+	cfg := mocks.NewMockChannelCfg("")
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig("GoodMSP", []byte(validRootCA))}
+	m, err := New(Context{Providers: ctx}, cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, m.Verify(endorser, []byte("test"), signature), "expected valid signature")
+	assert.NotNil(t, m.Verify(endorser, []byte("other"), signature), "expected invalid signature")
+}
+
 func buildMSPConfig(name string, root []byte) *mb.MSPConfig {
 	return &mb.MSPConfig{
 		Type:   0,
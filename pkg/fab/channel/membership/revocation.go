@@ -0,0 +1,243 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package membership
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	mspapi "github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+// CRLProvider returns the current CRLs, PEM or DER encoded
+type CRLProvider func() ([][]byte, error)
+
+// CRLGenerator generates CRLs, see the MSP client
+type CRLGenerator interface {
+	GenCRL(request *mspapi.GenCRLRequest) (*mspapi.GenCRLResponse, error)
+}
+
+// CACRLs returns a CRLProvider that fetches the current CRL from the Fabric CA with the given name
+func CACRLs(generator CRLGenerator, caName string) CRLProvider {
+	return func() ([][]byte, error) {
+		resp, err := generator.GenCRL(&mspapi.GenCRLRequest{CAName: caName})
+		if err != nil {
+			return nil, errors.WithMessage(err, "generating CRL failed")
+		}
+		return [][]byte{resp.CRL}, nil
+	}
+}
+
+// FileCRLs returns a CRLProvider that reads the CRLs from the given files.
+// A file may contain several PEM-encoded CRLs.
+func FileCRLs(paths ...string) CRLProvider {
+	return func() ([][]byte, error) {
+		var crls [][]byte
+		for _, path := range paths {
+			crl, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading CRL file %s failed", path)
+			}
+			crls = append(crls, crl)
+		}
+		return crls, nil
+	}
+}
+
+// RevocationSource keeps the current CRLs of the given providers and refreshes them at an interval.
+// A CRL is only used if it is signed by one of the CA certificates of the MSP of the identity.
+// The CRLs are fetched again if one of them has passed its next update; identities of an issuer
+// whose CRL has passed its next update are rejected until a newer CRL is loaded.
+type RevocationSource struct {
+	providers []CRLProvider
+	mutex     sync.RWMutex
+	crls      map[string][]*crl
+	loaded    time.Time
+	now       func() time.Time
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// crl is a parsed CRL and the serial numbers of the certificates it revokes
+type crl struct {
+	list    *pkix.CertificateList
+	revoked map[string]bool
+	// verified holds whether the CRL is signed by a CA certificate, by SHA-256 hash of the certificate
+	verified sync.Map
+}
+
+// NewRevocationSource loads the CRLs of the given providers and refreshes them at the given interval
+// until Close is called. The CRLs are not refreshed if the interval is not positive.
+func NewRevocationSource(refreshInterval time.Duration, providers ...CRLProvider) (*RevocationSource, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("at least one CRL provider is required")
+	}
+
+	s := &RevocationSource{
+		providers: providers,
+		now:       time.Now,
+		closed:    make(chan struct{}),
+	}
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+	if refreshInterval > 0 {
+		go s.refresh(refreshInterval)
+	}
+	return s, nil
+}
+
+// Refresh replaces the CRLs with the current CRLs of the providers.
+// The previous CRLs are kept if a provider fails.
+func (s *RevocationSource) Refresh() error {
+	loaded := s.now()
+	crls := make(map[string][]*crl)
+	for _, provider := range s.providers {
+		crlBytes, err := provider()
+		if err != nil {
+			return errors.WithMessage(err, "loading CRLs failed")
+		}
+		for _, b := range crlBytes {
+			if err := addCRLs(crls, b); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.mutex.Lock()
+	s.crls = crls
+	s.loaded = loaded
+	s.mutex.Unlock()
+	return nil
+}
+
+// Revoked returns whether the certificate appears in a current CRL of its issuer that is signed by one
+// of the given CA certificates. CRLs that are not signed by one of the CA certificates are ignored.
+// An error is returned if a CRL of the issuer has passed its next update and no newer CRL can be loaded.
+func (s *RevocationSource) Revoked(cert *x509.Certificate, caCerts []*x509.Certificate) (bool, error) {
+	issuer := cert.Issuer.String()
+	crls, stale := s.issuerCRLs(issuer)
+	if stale {
+		logger.Debugf("Refreshing CRLs of issuer [%s] that passed their next update", issuer)
+		if err := s.Refresh(); err != nil {
+			logger.Warnf("Refreshing CRLs failed: %s", err)
+		}
+		crls, _ = s.issuerCRLs(issuer)
+	}
+
+	now := s.now()
+	for _, c := range crls {
+		if !c.signedBy(caCerts) {
+			logger.Warnf("Ignoring CRL of issuer [%s] that is not signed by a CA of the MSP", issuer)
+			continue
+		}
+		if nextUpdate := c.list.TBSCertList.NextUpdate; !nextUpdate.IsZero() && now.After(nextUpdate) {
+			return false, errors.Errorf("CRL of issuer [%s] passed its next update at %s", issuer, nextUpdate)
+		}
+		if c.revoked[cert.SerialNumber.String()] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Close stops refreshing the CRLs
+func (s *RevocationSource) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
+
+// issuerCRLs returns the CRLs of the issuer and whether one of them passed its next update
+// after the CRLs were loaded, in which case a newer CRL may be available
+func (s *RevocationSource) issuerCRLs(issuer string) ([]*crl, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := s.now()
+	crls := s.crls[issuer]
+	for _, c := range crls {
+		nextUpdate := c.list.TBSCertList.NextUpdate
+		if !nextUpdate.IsZero() && now.After(nextUpdate) && s.loaded.Before(nextUpdate) {
+			return crls, true
+		}
+	}
+	return crls, false
+}
+
+func (s *RevocationSource) refresh(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				logger.Warnf("Refreshing CRLs failed, keeping previous CRLs: %s", err)
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// signedBy returns whether the CRL is signed by one of the CA certificates
+func (c *crl) signedBy(caCerts []*x509.Certificate) bool {
+	for _, caCert := range caCerts {
+		key := sha256.Sum256(caCert.Raw)
+		verified, ok := c.verified.Load(key)
+		if !ok {
+			verified = caCert.CheckCRLSignature(c.list) == nil
+			c.verified.Store(key, verified)
+		}
+		if verified.(bool) {
+			return true
+		}
+	}
+	return false
+}
+
+// addCRLs adds the PEM or DER encoded CRLs, by issuer
+func addCRLs(crls map[string][]*crl, crlBytes []byte) error {
+	var ders [][]byte
+	rest := crlBytes
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "X509 CRL" {
+			ders = append(ders, block.Bytes)
+		}
+	}
+	if len(ders) == 0 {
+		ders = append(ders, crlBytes)
+	}
+
+	for _, der := range ders {
+		list, err := x509.ParseDERCRL(der)
+		if err != nil {
+			return errors.Wrap(err, "parsing CRL failed")
+		}
+
+		var issuer pkix.Name
+		issuer.FillFromRDNSequence(&list.TBSCertList.Issuer)
+		c := &crl{list: list, revoked: make(map[string]bool)}
+		for _, cert := range list.TBSCertList.RevokedCertificates {
+			c.revoked[cert.SerialNumber.String()] = true
+		}
+		crls[issuer.String()] = append(crls[issuer.String()], c)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package membership
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspapi "github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMSPID = "RevocationMSP"
+
+type testCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func TestRevocationSource(t *testing.T) {
+	ca := newTestCA(t)
+	revokedCert := ca.issue(t, 2)
+	validCert := ca.issue(t, 3)
+
+	otherCA := newTestCA(t)
+	otherCert := otherCA.issue(t, 2)
+
+	source, err := NewRevocationSource(0, func() ([][]byte, error) {
+		return [][]byte{ca.crl(t, revokedCert), otherCA.crlDER(t)}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer source.Close()
+
+	cas := []*x509.Certificate{ca.cert, otherCA.cert}
+	assertRevoked(t, true, source, revokedCert, cas)
+	assertRevoked(t, false, source, validCert, cas)
+	assertRevoked(t, false, source, otherCert, cas, "serial is only revoked by its issuer")
+	assertRevoked(t, false, source, revokedCert, []*x509.Certificate{otherCA.cert}, "CRL must be signed by a CA of the MSP")
+
+	_, err = NewRevocationSource(0)
+	assert.Error(t, err, "expected error without CRL providers")
+
+	_, err = NewRevocationSource(0, func() ([][]byte, error) { return [][]byte{[]byte("invalid")}, nil })
+	assert.Error(t, err, "expected error for invalid CRL")
+}
+
+func TestMembershipWithRevocationSource(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 2)
+	serializedID := serializeIdentity(t, cert)
+
+	dir, err := ioutil.TempDir("", "crl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	crlFile := filepath.Join(dir, "crl.pem")
+	if err := ioutil.WriteFile(crlFile, ca.crl(t), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source, err := NewRevocationSource(20*time.Millisecond, FileCRLs(crlFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer source.Close()
+
+	ctx := mocks.NewMockProviderContext()
+	cfg := mocks.NewMockChannelCfg("")
+	cfg.MockMSPs = []*mb.MSPConfig{buildMSPConfig(testMSPID, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))}
+	m, err := New(Context{Providers: ctx}, cfg, WithRevocationSource(source))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.NoError(t, m.Validate(serializedID))
+	assert.NoError(t, m.Verify(serializedID, []byte("test"), []byte("test1")))
+
+	// The identity is rejected once the refreshed CRL revokes its certificate
+	if err := ioutil.WriteFile(crlFile, ca.crl(t, cert), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !revoked(source, cert, ca.cert) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Error(t, m.Validate(serializedID))
+	assert.Error(t, m.Verify(serializedID, []byte("test"), []byte("test1")))
+
+	// The previous CRLs are kept if the CRLs cannot be loaded
+	if err := os.Remove(crlFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Error(t, source.Refresh())
+	assert.True(t, revoked(source, cert, ca.cert))
+}
+
+func TestRevocationSourceCRLSignature(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 2)
+
+	// CRL of the issuer of the identity that is signed by another key
+	forger := newTestCA(t)
+	forger.cert = ca.cert
+	source, err := NewRevocationSource(0, func() ([][]byte, error) {
+		return [][]byte{forger.crl(t, cert)}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRevoked(t, false, source, cert, []*x509.Certificate{ca.cert}, "expected CRL with invalid signature to be ignored")
+}
+
+func TestRevocationSourceNextUpdate(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 2)
+
+	fetches := 0
+	nextUpdate := time.Now().Add(time.Minute)
+	source, err := NewRevocationSource(0, func() ([][]byte, error) {
+		fetches++
+		return [][]byte{ca.crlWithNextUpdate(t, nextUpdate)}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cas := []*x509.Certificate{ca.cert}
+	assertRevoked(t, false, source, cert, cas)
+	assert.Equal(t, 1, fetches)
+
+	// The CRLs are fetched again once the next update has passed
+	now := nextUpdate.Add(time.Second)
+	source.now = func() time.Time { return now }
+	nextUpdate = now.Add(time.Minute)
+	assertRevoked(t, false, source, cert, cas)
+	assert.Equal(t, 2, fetches)
+
+	// The identity is rejected if no newer CRL is available
+	now = nextUpdate.Add(time.Second)
+	_, err = source.Revoked(cert, cas)
+	assert.Error(t, err, "expected error for CRL that passed its next update")
+	assert.Equal(t, 3, fetches)
+	_, err = source.Revoked(cert, cas)
+	assert.Error(t, err, "expected error for CRL that passed its next update")
+	assert.Equal(t, 3, fetches, "expected CRLs loaded after their next update not to be fetched again")
+}
+
+func assertRevoked(t *testing.T, expected bool, source *RevocationSource, cert *x509.Certificate, caCerts []*x509.Certificate, msgAndArgs ...interface{}) {
+	revoked, err := source.Revoked(cert, caCerts)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, revoked, msgAndArgs...)
+}
+
+func revoked(source *RevocationSource, cert *x509.Certificate, caCert *x509.Certificate) bool {
+	revoked, err := source.Revoked(cert, []*x509.Certificate{caCert})
+	return err == nil && revoked
+}
+
+type testCRLGenerator struct {
+	crl []byte
+	err error
+}
+
+func (g *testCRLGenerator) GenCRL(request *mspapi.GenCRLRequest) (*mspapi.GenCRLResponse, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &mspapi.GenCRLResponse{CRL: g.crl}, nil
+}
+
+func TestCACRLs(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 2)
+
+	generator := &testCRLGenerator{crl: ca.crl(t, cert)}
+	source, err := NewRevocationSource(0, CACRLs(generator, "ca.org1.example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertRevoked(t, true, source, cert, []*x509.Certificate{ca.cert})
+
+	generator.err = errors.New("CA unavailable")
+	_, err = NewRevocationSource(0, CACRLs(generator, ""))
+	assert.Error(t, err)
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "ca" + serial.String(), Organization: []string{"Revocation Org"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &testCA{key: key, cert: cert}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64) *x509.Certificate {
+	return ca.issueUntil(t, serial, time.Now().Add(time.Hour))
+}
+
+func (ca *testCA) issueUntil(t *testing.T, serial int64, notAfter time.Time) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cert
+}
+
+func (ca *testCA) crlDER(t *testing.T, revoked ...*x509.Certificate) []byte {
+	return ca.crlDERWithNextUpdate(t, time.Now().Add(time.Hour), revoked...)
+}
+
+func (ca *testCA) crlWithNextUpdate(t *testing.T, nextUpdate time.Time, revoked ...*x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: ca.crlDERWithNextUpdate(t, nextUpdate, revoked...)})
+}
+
+func (ca *testCA) crlDERWithNextUpdate(t *testing.T, nextUpdate time.Time, revoked ...*x509.Certificate) []byte {
+	var revokedCerts []pkix.RevokedCertificate
+	for _, cert := range revoked {
+		revokedCerts = append(revokedCerts, pkix.RevokedCertificate{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()})
+	}
+	crl, err := ca.cert.CreateCRL(rand.Reader, ca.key, revokedCerts, time.Now(), nextUpdate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return crl
+}
+
+func (ca *testCA) crl(t *testing.T, revoked ...*x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: ca.crlDER(t, revoked...)})
+}
+
+func serializeIdentity(t *testing.T, cert *x509.Certificate) []byte {
+	sID := &mb.SerializedIdentity{Mspid: testMSPID, IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})}
+	serializedID, err := proto.Marshal(sID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return serializedID
+}
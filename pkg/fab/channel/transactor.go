@@ -17,20 +17,36 @@ import (
 
 	reqContext "context"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/orderer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 )
 
 // Transactor enables sending transactions and transaction proposals on the channel.
 type Transactor struct {
-	reqCtx    reqContext.Context
-	ChannelID string
-	orderers  []fab.Orderer
+	reqCtx        reqContext.Context
+	ChannelID     string
+	orderers      []fab.Orderer
+	selector      fab.OrdererSelector
+	retryAttempts int
+}
+
+// TransactorOption describes a functional parameter for the NewTransactor constructor
+type TransactorOption func(*Transactor)
+
+// WithOrdererSelector sets the selector that orders the orderers transactions are broadcast to.
+// The selector should be shared by the transactors of the channel so that it can keep track of failed orderers.
+func WithOrdererSelector(selector fab.OrdererSelector) TransactorOption {
+	return func(t *Transactor) {
+		t.selector = selector
+	}
 }
 
 // NewTransactor returns a Transactor for the current context and channel config.
-func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg) (*Transactor, error) {
+func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg, opts ...TransactorOption) (*Transactor, error) {
 
 	ctx, ok := contextImpl.RequestClientContext(reqCtx)
 	if !ok {
@@ -46,14 +62,39 @@ func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg) (*Transactor,
 	//	return nil, errors.New("orderers are not configured")
 	//}
 
+	policies, err := ChannelPolicies(ctx.Config(), cfg.ID())
+	if err != nil {
+		return nil, err
+	}
+
 	t := Transactor{
-		reqCtx:    reqCtx,
-		ChannelID: cfg.ID(),
-		orderers:  orderers,
+		reqCtx:        reqCtx,
+		ChannelID:     cfg.ID(),
+		orderers:      orderers,
+		retryAttempts: policies.OrdererSelection.RetryAttempts,
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	if t.selector == nil {
+		breaker := circuitbreaker.NewFromPolicy(policies.CircuitBreaker, ctx.Config().TimeoutOrDefault(core.DiscoveryGreylistExpiry))
+		t.selector = orderer.NewSelector(policies.OrdererSelection, breaker)
 	}
 	return &t, nil
 }
 
+// ChannelPolicies returns the client side policies of the channel from the network config
+func ChannelPolicies(config core.Config, channelID string) (core.ChannelPolicies, error) {
+	chConfig, err := config.ChannelConfig(channelID)
+	if err != nil {
+		return core.ChannelPolicies{}, errors.WithMessage(err, "reading channel config failed")
+	}
+	if chConfig == nil {
+		return core.ChannelPolicies{}, nil
+	}
+	return chConfig.Policies, nil
+}
+
 func orderersFromChannelCfg(ctx context.Client, cfg fab.ChannelCfg) ([]fab.Orderer, error) {
 	orderers := []fab.Orderer{}
 	ordererDict, err := orderersByTarget(ctx)
@@ -89,6 +130,32 @@ func orderersFromChannelCfg(ctx context.Client, cfg fab.ChannelCfg) ([]fab.Order
 		orderers = append(orderers, o)
 
 	}
+
+	// Fall back to the orderers of the channel in the network config
+	if len(orderers) == 0 {
+		return orderersFromNetworkConfig(ctx, cfg.ID())
+	}
+	return orderers, nil
+}
+
+func orderersFromNetworkConfig(ctx context.Client, channelID string) ([]fab.Orderer, error) {
+	orderers := []fab.Orderer{}
+	chConfig, err := ctx.Config().ChannelConfig(channelID)
+	if err != nil || chConfig == nil || len(chConfig.Orderers) == 0 {
+		return orderers, nil
+	}
+
+	orderersConfig, err := ctx.Config().ChannelOrderers(channelID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orderersConfig {
+		o, err := ctx.InfraProvider().CreateOrdererFromConfig(&orderersConfig[i])
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to create orderer from config")
+		}
+		orderers = append(orderers, o)
+	}
 	return orderers, nil
 }
 
@@ -107,14 +174,14 @@ func orderersByTarget(ctx context.Client) (map[string]core.OrdererConfig, error)
 }
 
 // CreateTransactionHeader creates a Transaction Header based on the current context.
-func (t *Transactor) CreateTransactionHeader() (fab.TransactionHeader, error) {
+func (t *Transactor) CreateTransactionHeader(opts ...fab.TxnHeaderOpt) (fab.TransactionHeader, error) {
 
 	ctx, ok := contextImpl.RequestClientContext(t.reqCtx)
 	if !ok {
 		return nil, errors.New("failed get client context from reqContext for txn Header")
 	}
 
-	txh, err := txn.NewHeader(ctx, t.ChannelID)
+	txh, err := txn.NewHeader(ctx, t.ChannelID, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "new transaction ID failed")
 	}
@@ -151,5 +218,34 @@ func (t *Transactor) SendTransaction(tx *fab.Transaction) (*fab.TransactionRespo
 	reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(core.OrdererResponse), contextImpl.WithReqContext(t.reqCtx))
 	defer cancel()
 
-	return txn.Send(reqCtx, tx, t.orderers)
+	return txn.Send(reqCtx, tx, t.orderers, t.broadcastOpts()...)
+}
+
+// CreateTransactionEnvelope creates the signed envelope of a transaction without sending it.
+func (t *Transactor) CreateTransactionEnvelope(tx *fab.Transaction) (*fab.SignedEnvelope, error) {
+	return txn.CreateSignedEnvelope(t.reqCtx, tx)
+}
+
+// SendEnvelope sends a signed transaction envelope to the chain’s orderer service.
+func (t *Transactor) SendEnvelope(envelope *fab.SignedEnvelope) (*fab.TransactionResponse, error) {
+	ctx, ok := contextImpl.RequestClientContext(t.reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for SendEnvelope")
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(core.OrdererResponse), contextImpl.WithReqContext(t.reqCtx))
+	defer cancel()
+
+	return txn.BroadcastEnvelope(reqCtx, envelope, t.orderers, t.broadcastOpts()...)
+}
+
+func (t *Transactor) broadcastOpts() []txn.BroadcastOpt {
+	retryOpts := retry.DefaultOpts
+	retryOpts.RetryableCodes = nil
+	if t.retryAttempts > 0 {
+		retryOpts.Attempts = t.retryAttempts
+	} else if t.retryAttempts < 0 {
+		retryOpts.Attempts = 0
+	}
+	return []txn.BroadcastOpt{txn.WithOrdererSelector(t.selector), txn.WithBroadcastRetry(retryOpts)}
 }
@@ -11,9 +11,12 @@ import (
 
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/orderer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	"github.com/stretchr/testify/assert"
 )
@@ -135,3 +138,20 @@ func TestOrderersFromChannelCfgBadTLS(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEmpty(t, o)
 }
+
+func TestTransactorOrdererSelector(t *testing.T) {
+	user := mocks.NewMockUser("test")
+	ctx := mocks.NewMockContext(user)
+	chConfig := mocks.NewMockChannelCfg("testChannel")
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	transactor, err := NewTransactor(reqCtx, chConfig)
+	assert.Nil(t, err)
+	assert.NotNil(t, transactor.selector, "expected default orderer selector")
+
+	selector := orderer.NewSelector(core.OrdererSelectionPolicy{Strategy: core.FirstAvailable}, circuitbreaker.New(1, time.Minute))
+	transactor, err = NewTransactor(reqCtx, chConfig, WithOrdererSelector(selector))
+	assert.Nil(t, err)
+	assert.Equal(t, selector, transactor.selector, "expected supplied orderer selector")
+}
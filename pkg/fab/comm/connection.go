@@ -20,17 +20,10 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 var logger = logging.NewLogger("fabsdk/fab")
 
-const (
-	// GRPC max message size (same as Fabric)
-	maxCallRecvMsgSize = 100 * 1024 * 1024
-	maxCallSendMsgSize = 100 * 1024 * 1024
-)
-
 // StreamProvider creates a GRPC stream
 type StreamProvider func(conn *grpc.ClientConn) (grpc.ClientStream, error)
 
@@ -54,13 +47,19 @@ func NewConnection(ctx fabcontext.Client, chConfig fab.ChannelCfg, streamProvide
 	params := defaultParams()
 	options.Apply(params, opts)
 
-	dialOpts, err := newDialOpts(ctx.Config(), url, params)
+	clientCerts, credential, err := comm.ClientCerts(ctx.Config(), params.tlsClientCert, ctx, ctx.CryptoSuite())
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get TLS client certificate")
+	}
+
+	dialOpts, err := newDialOpts(ctx.Config(), url, params, clientCerts)
 	if err != nil {
 		return nil, err
 	}
 
 	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(params.connectTimeout))
 	defer cancel()
+	reqCtx = context.RequestWithCredential(reqCtx, credential)
 
 	commManager, ok := context.RequestCommManager(reqCtx)
 	if !ok {
@@ -88,7 +87,7 @@ func NewConnection(ctx fabcontext.Client, chConfig fab.ChannelCfg, streamProvide
 		commManager: commManager,
 		conn:        grpcconn,
 		stream:      stream,
-		tlsCertHash: comm.TLSCertHash(ctx.Config()),
+		tlsCertHash: tlsCertHash(clientCerts),
 	}, nil
 }
 
@@ -139,7 +138,7 @@ func (c *GRPCConnection) Context() fabcontext.Client {
 	return c.context
 }
 
-func newDialOpts(config core.Config, url string, params *params) ([]grpc.DialOption, error) {
+func newDialOpts(config core.Config, url string, params *params, clientCerts comm.ClientCertsProvider) ([]grpc.DialOption, error) {
 	var dialOpts []grpc.DialOption
 
 	if params.keepAliveParams.Time > 0 || params.keepAliveParams.Timeout > 0 {
@@ -148,20 +147,36 @@ func newDialOpts(config core.Config, url string, params *params) ([]grpc.DialOpt
 
 	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.FailFast(params.failFast)))
 
+	if params.proxyURL != nil {
+		dialOpts = append(dialOpts, comm.ProxyDialOption(params.proxyURL))
+	}
+
 	if endpoint.AttemptSecured(url, params.insecure) {
-		tlsConfig, err := comm.TLSConfig(params.certificate, params.hostOverride, config)
+		creds, err := comm.TransportCredentialsWithClientCerts(params.certificate, params.hostOverride, config, clientCerts)
 		if err != nil {
 			return nil, err
 		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
 		logger.Debugf("Creating a secure connection to [%s] with TLS HostOverride [%s]", url, params.hostOverride)
 	} else {
 		logger.Debugf("Creating an insecure connection [%s]", url)
 		dialOpts = append(dialOpts, grpc.WithInsecure())
 	}
 
-	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
-		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
+	grpcOpts, err := comm.GRPCDialOptions(params.grpcOptions)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts = append(dialOpts, grpcOpts...)
 
 	return dialOpts, nil
 }
+
+// tlsCertHash returns the hash of the TLS client certificate of the connection (for usage in channel headers)
+func tlsCertHash(clientCerts comm.ClientCertsProvider) []byte {
+	certs, err := clientCerts()
+	if err != nil {
+		return nil
+	}
+	return comm.ClientCertHash(certs)
+}
@@ -8,9 +8,11 @@ package comm
 
 import (
 	"crypto/x509"
+	"net/url"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"google.golang.org/grpc/keepalive"
 )
 
@@ -21,6 +23,9 @@ type params struct {
 	failFast        bool
 	insecure        bool
 	connectTimeout  time.Duration
+	tlsClientCert   core.TLSClientCertConfig
+	proxyURL        *url.URL
+	grpcOptions     map[string]interface{}
 }
 
 func defaultParams() *params {
@@ -48,6 +53,34 @@ func WithCertificate(value *x509.Certificate) options.Opt {
 	}
 }
 
+// WithTLSClientCert sets the config of the TLS client certificate used for the TLS connection
+func WithTLSClientCert(value core.TLSClientCertConfig) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(tlsClientCertSetter); ok {
+			setter.SetTLSClientCert(value)
+		}
+	}
+}
+
+// WithProxy sets the URL of the proxy through which the connection is established
+func WithProxy(value *url.URL) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(proxySetter); ok {
+			setter.SetProxy(value)
+		}
+	}
+}
+
+// WithGRPCOptions sets the grpcOptions (message sizes, compression, window sizes, backoff and user agent)
+// of the connection
+func WithGRPCOptions(value map[string]interface{}) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(grpcOptionsSetter); ok {
+			setter.SetGRPCOptions(value)
+		}
+	}
+}
+
 // WithKeepAliveParams sets the GRPC keep-alive parameters
 func WithKeepAliveParams(value keepalive.ClientParameters) options.Opt {
 	return func(p options.Params) {
@@ -95,6 +128,23 @@ func (p *params) SetCertificate(value *x509.Certificate) {
 	p.certificate = value
 }
 
+func (p *params) SetTLSClientCert(value core.TLSClientCertConfig) {
+	logger.Debugf("TLSClientCert: %t", value.IsSet())
+	p.tlsClientCert = value
+}
+
+func (p *params) SetProxy(value *url.URL) {
+	if value != nil {
+		logger.Debugf("Proxy: %s", value.Host)
+	}
+	p.proxyURL = value
+}
+
+func (p *params) SetGRPCOptions(value map[string]interface{}) {
+	logger.Debugf("GRPCOptions: %v", value)
+	p.grpcOptions = value
+}
+
 func (p *params) SetKeepAliveParams(value keepalive.ClientParameters) {
 	logger.Debugf("KeepAliveParams: %#v", value)
 	p.keepAliveParams = value
@@ -123,6 +173,18 @@ type certificateSetter interface {
 	SetCertificate(value *x509.Certificate)
 }
 
+type tlsClientCertSetter interface {
+	SetTLSClientCert(value core.TLSClientCertConfig)
+}
+
+type proxySetter interface {
+	SetProxy(value *url.URL)
+}
+
+type grpcOptionsSetter interface {
+	SetGRPCOptions(value map[string]interface{})
+}
+
 type keepAliveParamsSetter interface {
 	SetKeepAliveParams(value keepalive.ClientParameters)
 }
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
@@ -26,7 +27,15 @@ const (
 // When connections has its usages closed for longer than "idleTime", the connection is closed and removed
 // from the connection cache. Callers must release connections by calling the "ReleaseConn" method.
 // The Close method will flush all remaining open connections. This component should be considered
-// unusable after calling Close.
+// unusable after calling Close. The Drain method removes the cached connections, so that connections are
+// dialed anew, for example after the TLS credentials have changed.
+//
+// Connections are cached per target and per credential: a request context carrying the key of the credential
+// with which connections are established (see context.RequestWithCredential) does not share the connections
+// established with other credentials.
+//
+// The calls on the connections carry the SDK operation in their metadata (see OperationMetadataKey)
+// and go through the interceptors added with AddInterceptors.
 //
 // This component has been designed to be safe for concurrency.
 type CachingConnector struct {
@@ -39,14 +48,19 @@ type CachingConnector struct {
 	janitorChan   chan *cachedConn
 	janitorDone   chan bool
 	janitorClosed chan bool
+	interceptors  Interceptors
 }
 
 type cachedConn struct {
+	// key is the key of the connection in the cache: the target, qualified by the credential if any
+	key       string
 	target    string
 	conn      *grpc.ClientConn
 	open      int
 	lastOpen  time.Time
 	lastClose time.Time
+	// drained connections are no longer cached and are closed once released
+	drained bool
 }
 
 // NewCachingConnector creates a GRPC connection cache. The cache is governed by
@@ -88,6 +102,13 @@ func (cc *CachingConnector) Close() {
 			cc.waitgroup.Wait()
 		}
 
+		// Drained connections are not known by the janitor
+		for conn, cconn := range cc.index {
+			if cconn.drained {
+				closeConn(conn)
+			}
+		}
+
 		close(cc.janitorChan)
 		close(cc.janitorClosed)
 		close(cc.janitorDone)
@@ -99,9 +120,10 @@ func (cc *CachingConnector) Close() {
 func (cc *CachingConnector) DialContext(ctx context.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	logger.Debugf("DialContext: %s", target)
 
-	c, ok := cc.loadConn(target)
+	key := connKey(ctx, target)
+	c, ok := cc.loadConn(key)
 	if !ok {
-		createdConn, err := cc.createConn(ctx, target, opts...)
+		createdConn, err := cc.createConn(ctx, key, target, opts...)
 		if err != nil {
 			return nil, errors.WithMessage(err, "connection creation failed")
 		}
@@ -114,6 +136,41 @@ func (cc *CachingConnector) DialContext(ctx context.Context, target string, opts
 	return c.conn, nil
 }
 
+// AddInterceptors adds GRPC client interceptors to the connections dialed from now on.
+// Interceptors should be added before the first connection is dialed.
+func (cc *CachingConnector) AddInterceptors(interceptors Interceptors) {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	cc.interceptors.Unary = append(cc.interceptors.Unary, interceptors.Unary...)
+	cc.interceptors.Stream = append(cc.interceptors.Stream, interceptors.Stream...)
+}
+
+// Drain removes the cached connections, so that subsequent calls to DialContext dial new connections.
+// Connections that are in use are closed gracefully once they have been released; the others are closed right away.
+func (cc *CachingConnector) Drain() {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	if cc.janitorDone == nil {
+		return
+	}
+
+	logger.Debugf("draining cached connections")
+	for conn, cconn := range cc.index {
+		if cconn.drained {
+			continue
+		}
+		cc.conns.Delete(cconn.key)
+		cconn.drained = true
+		if cconn.open == 0 {
+			delete(cc.index, conn)
+			go closeConn(conn)
+		}
+		cc.updateJanitor(cconn)
+	}
+}
+
 // ReleaseConn notifies the cache that the connection is no longer in use.
 func (cc *CachingConnector) ReleaseConn(conn *grpc.ClientConn) {
 	cc.lock.Lock()
@@ -131,16 +188,25 @@ func (cc *CachingConnector) ReleaseConn(conn *grpc.ClientConn) {
 		cconn.open--
 	}
 
+	if cconn.drained {
+		if cconn.open == 0 {
+			logger.Debugf("closing drained connection [%s]", cconn.target)
+			delete(cc.index, conn)
+			go closeConn(conn)
+		}
+		return
+	}
+
 	cc.updateJanitor(cconn)
 }
 
-func (cc *CachingConnector) loadConn(target string) (*cachedConn, bool) {
-	connRaw, ok := cc.conns.Load(target)
+func (cc *CachingConnector) loadConn(key string) (*cachedConn, bool) {
+	connRaw, ok := cc.conns.Load(key)
 	if ok {
 		c, ok := connRaw.(*cachedConn)
 		if ok {
 			if c.conn.GetState() != connectivity.Shutdown {
-				logger.Debugf("using cached connection [%s: %p]", target, c)
+				logger.Debugf("using cached connection [%s: %p]", c.target, c)
 				return c, true
 			}
 			cc.shutdownConn(c)
@@ -149,16 +215,17 @@ func (cc *CachingConnector) loadConn(target string) (*cachedConn, bool) {
 	return nil, false
 }
 
-func (cc *CachingConnector) createConn(ctx context.Context, target string, opts ...grpc.DialOption) (*cachedConn, error) {
+func (cc *CachingConnector) createConn(ctx context.Context, key string, target string, opts ...grpc.DialOption) (*cachedConn, error) {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
 
-	cconn, ok := cc.loadConn(target)
+	cconn, ok := cc.loadConn(key)
 	if ok {
 		return cconn, nil
 	}
 
 	logger.Debugf("creating connection [%s]", target)
+	opts = append(opts, cc.interceptors.dialOpts()...)
 	conn, err := grpc.DialContext(ctx, target, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "dialing peer failed")
@@ -166,10 +233,11 @@ func (cc *CachingConnector) createConn(ctx context.Context, target string, opts
 
 	logger.Debugf("storing connection [%s]", target)
 	cconn = &cachedConn{
+		key:    key,
 		target: target,
 		conn:   conn,
 	}
-	cc.conns.Store(target, cconn)
+	cc.conns.Store(key, cconn)
 	cc.index[conn] = cconn
 
 	return cconn, nil
@@ -210,7 +278,7 @@ func (cc *CachingConnector) shutdownConn(cconn *cachedConn) {
 	defer cc.lock.Unlock()
 
 	logger.Debugf("connection was shutdown [%s]", cconn.target)
-	cc.conns.Delete(cconn.target)
+	cc.conns.Delete(cconn.key)
 	delete(cc.index, cconn.conn)
 
 	cconn.open = 0
@@ -219,17 +287,17 @@ func (cc *CachingConnector) shutdownConn(cconn *cachedConn) {
 	cc.updateJanitor(cconn)
 }
 
-func (cc *CachingConnector) removeConn(target string) {
+func (cc *CachingConnector) removeConn(key string) {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
 
-	logger.Debugf("removing connection [%s]", target)
-	connRaw, ok := cc.conns.Load(target)
+	logger.Debugf("removing connection [%s]", key)
+	connRaw, ok := cc.conns.Load(key)
 	if ok {
 		c, ok := connRaw.(*cachedConn)
 		if ok {
 			delete(cc.index, c.conn)
-			cc.conns.Delete(target)
+			cc.conns.Delete(key)
 			if err := c.conn.Close(); err != nil {
 				logger.Debugf("unable to close connection [%s]", err)
 			}
@@ -266,7 +334,7 @@ func (cc *CachingConnector) updateJanitor(c *cachedConn) {
 //    decrements the "wg" waitgroup when exiting.
 //    writes to the "done" go channel when closing due to becoming empty.
 
-type connRemoveNotifier func(target string)
+type connRemoveNotifier func(key string)
 
 func janitor(sweepTime time.Duration, idleTime time.Duration, wg *sync.WaitGroup, conn chan *cachedConn, close chan bool, done chan bool, connRemove connRemoveNotifier) {
 	logger.Debugf("starting connection janitor")
@@ -288,9 +356,9 @@ func janitor(sweepTime time.Duration, idleTime time.Duration, wg *sync.WaitGroup
 			cache(conns, c)
 		case <-ticker.C:
 			rm := sweep(conns, idleTime)
-			for _, target := range rm {
-				connRemove(target)
-				delete(conns, target)
+			for _, key := range rm {
+				connRemove(key)
+				delete(conns, key)
 			}
 
 			if len(conns) == 0 {
@@ -304,13 +372,22 @@ func janitor(sweepTime time.Duration, idleTime time.Duration, wg *sync.WaitGroup
 
 func cache(conns map[string]*cachedConn, updateConn *cachedConn) {
 
-	c, ok := conns[updateConn.target]
+	c, ok := conns[updateConn.key]
+	if updateConn.drained {
+		// The connector closes drained connections once they are released
+		if ok && c.conn == updateConn.conn {
+			logger.Debugf("connection drained in connection janitor")
+			delete(conns, updateConn.key)
+		}
+		return
+	}
+
 	if ok && updateConn.lastClose.IsZero() && updateConn.conn.GetState() == connectivity.Shutdown {
 		logger.Debugf("connection shutdown detected in connection janitor")
 		// We need to remove the connection from sweep consideration immediately
 		// since the connector has already removed it. Otherwise we can have a race
 		// between shutdown and creating a connection concurrently.
-		delete(conns, updateConn.target)
+		delete(conns, updateConn.key)
 		return
 	}
 
@@ -327,7 +404,7 @@ func cache(conns map[string]*cachedConn, updateConn *cachedConn) {
 		logger.Debugf("updating existing connection in connection janitor")
 	}
 
-	conns[updateConn.target] = updateConn
+	conns[updateConn.key] = updateConn
 }
 
 func flush(conns map[string]*cachedConn) {
@@ -343,10 +420,10 @@ func sweep(conns map[string]*cachedConn, idleTime time.Duration) []string {
 	for _, c := range conns {
 		if c.open == 0 && now.After(c.lastClose.Add(idleTime)) {
 			logger.Debugf("connection janitor closing connection [%s]", c.target)
-			rm = append(rm, c.target)
+			rm = append(rm, c.key)
 		} else if c.conn.GetState() == connectivity.Shutdown {
 			logger.Debugf("connection already closed [%s]", c.target)
-			rm = append(rm, c.target)
+			rm = append(rm, c.key)
 		}
 	}
 	return rm
@@ -363,3 +440,11 @@ func closeConn(conn *grpc.ClientConn) {
 	}
 	cancel()
 }
+
+// connKey returns the key of the connections to the target established with the credential of the request context
+func connKey(ctx context.Context, target string) string {
+	if credential, ok := contextImpl.RequestCredential(ctx); ok && credential != "" {
+		return target + "#" + credential
+	}
+	return target
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 	"unsafe"
 
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -150,6 +151,70 @@ func TestConnectorShouldSweep(t *testing.T) {
 	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn4), "connections should be different due to disconnect")
 }
 
+func TestConnectorDrain(t *testing.T) {
+	connector := NewCachingConnector(normalSweepTime, normalIdleTime)
+	defer connector.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+	conn1, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	assert.Nil(t, err, "DialContext should have succeeded")
+
+	ctx, cancel = context.WithTimeout(context.Background(), normalTimeout)
+	conn2, err := connector.DialContext(ctx, endorserAddr[1], grpc.WithInsecure())
+	cancel()
+	assert.Nil(t, err, "DialContext should have succeeded")
+	connector.ReleaseConn(conn2)
+
+	connector.Drain()
+	time.Sleep(connShutdownTimeout * 2)
+	assert.NotEqual(t, connectivity.Shutdown, conn1.GetState(), "connection in use should not be shutdown")
+	assert.Equal(t, connectivity.Shutdown, conn2.GetState(), "released connection should be shutdown")
+
+	ctx, cancel = context.WithTimeout(context.Background(), normalTimeout)
+	conn3, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	assert.Nil(t, err, "DialContext should have succeeded")
+	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn3), "connections should be different due to drain")
+
+	// The drained connection remains usable until it is released
+	_, err = pb.NewEndorserClient(conn1).ProcessProposal(context.Background(), &pb.SignedProposal{})
+	assert.Nil(t, err, "drained connection should be usable until released")
+	connector.ReleaseConn(conn1)
+	time.Sleep(connShutdownTimeout * 2)
+	assert.Equal(t, connectivity.Shutdown, conn1.GetState(), "drained connection should be shutdown once released")
+	assert.NotEqual(t, connectivity.Shutdown, conn3.GetState(), "new connection should not be shutdown")
+}
+
+func TestConnectorCachesPerCredential(t *testing.T) {
+	connector := NewCachingConnector(normalSweepTime, normalIdleTime)
+	defer connector.Close()
+
+	dial := func(credential string) *grpc.ClientConn {
+		ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+		defer cancel()
+		conn, err := connector.DialContext(contextImpl.RequestWithCredential(ctx, credential), endorserAddr[0], grpc.WithInsecure())
+		if err != nil {
+			t.Fatalf("DialContext failed: %s", err)
+		}
+		return conn
+	}
+
+	conn1 := dial("org1")
+	conn2 := dial("org2")
+	conn3 := dial("org1")
+	conn4 := dial("")
+	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn2), "connections with different credentials should not match")
+	assert.Equal(t, unsafe.Pointer(conn1), unsafe.Pointer(conn3), "connections with the same credential should match")
+	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn4), "connections with and without credential should not match")
+
+	ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+	conn5, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	assert.Nil(t, err, "DialContext should have succeeded")
+	assert.Equal(t, unsafe.Pointer(conn4), unsafe.Pointer(conn5), "connections without credential should match")
+}
+
 func TestConnectorConcurrent(t *testing.T) {
 	const goroutines = 50
 
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"context"
+
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// OperationMetadataKey is the key of the metadata identifying the SDK operation of a GRPC call
+const OperationMetadataKey = "fabric-sdk-operation"
+
+// operations are the SDK operations of the GRPC methods called by the SDK,
+// used if the request context does not identify the operation (see context.RequestWithOperation)
+var operations = map[string]string{
+	"/protos.Endorser/ProcessProposal":   "endorse",
+	"/orderer.AtomicBroadcast/Broadcast": "broadcast",
+	"/orderer.AtomicBroadcast/Deliver":   "deliver",
+	"/protos.Deliver/Deliver":            "deliver-events",
+	"/protos.Deliver/DeliverFiltered":    "deliver-filtered-events",
+	"/protos.Events/Chat":                "eventhub",
+}
+
+// Interceptors holds the GRPC client interceptors applied to the calls on connections.
+// Interceptors are called in order: the first interceptor is the outermost.
+type Interceptors struct {
+	Unary  []grpc.UnaryClientInterceptor
+	Stream []grpc.StreamClientInterceptor
+}
+
+// dialOpts returns the dial options of the interceptors, preceded by the interceptor
+// adding the SDK operation to the metadata of the calls
+func (i *Interceptors) dialOpts() []grpc.DialOption {
+	unary := append([]grpc.UnaryClientInterceptor{operationUnaryInterceptor}, i.Unary...)
+	stream := append([]grpc.StreamClientInterceptor{operationStreamInterceptor}, i.Stream...)
+
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(chainUnaryInterceptors(unary)),
+		grpc.WithStreamInterceptor(chainStreamInterceptors(stream)),
+	}
+}
+
+// chainUnaryInterceptors combines the interceptors into one interceptor, GRPC accepting a single interceptor
+func chainUnaryInterceptors(interceptors []grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		chained := invoker
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return interceptor(ctx, method, req, reply, cc, next, opts...)
+			}
+		}
+		return chained(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// chainStreamInterceptors combines the interceptors into one interceptor, GRPC accepting a single interceptor
+func chainStreamInterceptors(interceptors []grpc.StreamClientInterceptor) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		chained := streamer
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return interceptor(ctx, desc, cc, method, next, opts...)
+			}
+		}
+		return chained(ctx, desc, cc, method, opts...)
+	}
+}
+
+func operationUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withOperation(ctx, method), method, req, reply, cc, opts...)
+}
+
+func operationStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withOperation(ctx, method), desc, cc, method, opts...)
+}
+
+// withOperation adds the SDK operation of the call to the outgoing metadata of the context
+func withOperation(ctx context.Context, method string) context.Context {
+	operation, ok := contextImpl.RequestOperation(ctx)
+	if !ok {
+		if operation, ok = operations[method]; !ok {
+			operation = method
+		}
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewOutgoingContext(ctx, metadata.Join(md, metadata.Pairs(OperationMetadataKey, operation)))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"context"
+	"testing"
+
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestConnectorInterceptors(t *testing.T) {
+	connector := NewCachingConnector(normalSweepTime, normalIdleTime)
+	defer connector.Close()
+
+	var calls []string
+	var operations []string
+	recordingInterceptor := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, name)
+			md, _ := metadata.FromOutgoingContext(ctx)
+			operations = append(operations, md[OperationMetadataKey]...)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+	connector.AddInterceptors(Interceptors{Unary: []grpc.UnaryClientInterceptor{recordingInterceptor("first")}})
+	connector.AddInterceptors(Interceptors{Unary: []grpc.UnaryClientInterceptor{recordingInterceptor("second")}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+	defer cancel()
+	conn, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("DialContext failed: %s", err)
+	}
+	defer connector.ReleaseConn(conn)
+
+	_, err = pb.NewEndorserClient(conn).ProcessProposal(ctx, &pb.SignedProposal{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls, "expected the interceptors to be called in order")
+	assert.Equal(t, []string{"endorse", "endorse"}, operations, "expected the operation of the method in the metadata")
+
+	for _, operation := range []string{"channel.Query", "resmgmt.QueryChannels", "ledger.QueryInfo"} {
+		operations = nil
+		_, err = pb.NewEndorserClient(conn).ProcessProposal(contextImpl.RequestWithOperation(ctx, operation), &pb.SignedProposal{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{operation, operation}, operations, "expected the operation of the request context in the metadata")
+	}
+}
+
+func TestChainStreamInterceptors(t *testing.T) {
+	var calls []string
+	recordingInterceptor := func(name string) grpc.StreamClientInterceptor {
+		return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			calls = append(calls, name)
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+	}
+	chained := chainStreamInterceptors([]grpc.StreamClientInterceptor{operationStreamInterceptor, recordingInterceptor("first"), recordingInterceptor("second")})
+
+	var operation []string
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls = append(calls, "streamer")
+		md, _ := metadata.FromOutgoingContext(ctx)
+		operation = md[OperationMetadataKey]
+		return nil, nil
+	}
+	_, err := chained(context.Background(), &grpc.StreamDesc{}, nil, "/protos.Deliver/DeliverFiltered", streamer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "streamer"}, calls, "expected the interceptors to be called in order")
+	assert.Equal(t, []string{"deliver-filtered-events"}, operation)
+}
@@ -8,6 +8,7 @@ package endpoint
 
 import (
 	"crypto/x509"
+	"net/url"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	configComm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/spf13/cast"
 	"google.golang.org/grpc/keepalive"
 )
@@ -28,6 +30,9 @@ type EventEndpoint struct {
 	EvtURL          string
 	HostOverride    string
 	Certificate     *x509.Certificate
+	TLSClientCert   core.TLSClientCertConfig
+	Proxy           *url.URL
+	GRPCOptions     map[string]interface{}
 	KeepAliveParams keepalive.ClientParameters
 	FailFast        bool
 	ConnectTimeout  time.Duration
@@ -46,6 +51,9 @@ func (e *EventEndpoint) Opts() []options.Opt {
 		comm.WithFailFast(e.FailFast),
 		comm.WithKeepAliveParams(e.KeepAliveParams),
 		comm.WithCertificate(e.Certificate),
+		comm.WithTLSClientCert(e.TLSClientCert),
+		comm.WithProxy(e.Proxy),
+		comm.WithGRPCOptions(e.GRPCOptions),
 		comm.WithConnectTimeout(e.ConnectTimeout),
 	}
 	if e.AllowInsecure {
@@ -65,11 +73,24 @@ func FromPeerConfig(config core.Config, peer fab.Peer, peerCfg *core.PeerConfig)
 		}
 	}
 
+	tlsClientCert, err := configComm.PeerTLSClientCert(config, peerCfg, peer.MSPID())
+	if err != nil {
+		return nil, err
+	}
+
+	proxyURL, err := configComm.ProxyURL(config, peerCfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EventEndpoint{
 		Peer:            peer,
 		EvtURL:          peerCfg.EventURL,
 		HostOverride:    getServerNameOverride(peerCfg),
 		Certificate:     certificate,
+		TLSClientCert:   tlsClientCert,
+		Proxy:           proxyURL,
+		GRPCOptions:     peerCfg.GRPCOptions,
 		KeepAliveParams: getKeepAliveOptions(peerCfg),
 		FailFast:        getFailFast(peerCfg),
 		ConnectTimeout:  config.TimeoutOrDefault(core.EventHubConnection),
@@ -22,7 +22,7 @@ func TestEndpoint(t *testing.T) {
 	expectedKeepAliveTime := time.Second
 	expectedKeepAliveTimeout := time.Second
 	expectedKeepAlivePermit := true
-	expectedNumOpts := 6
+	expectedNumOpts := 9
 
 	config := fabmocks.NewMockConfig()
 	peer := fabmocks.NewMockPeer("p1", "localhost:7051")
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
@@ -77,6 +78,32 @@ func (fkvs *FileKeyValueStore) GetPath() string {
 	return fkvs.path
 }
 
+// Keys returns the paths of the files in the store, relative to the store path.
+// They are the keys of the stored values if the default key serializer is used.
+func (fkvs *FileKeyValueStore) Keys() ([]string, error) {
+	var keys []string
+	err := filepath.Walk(fkvs.path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			key, err := filepath.Rel(fkvs.path, file)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, filepath.ToSlash(key))
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "listing store files failed")
+	}
+	return keys, nil
+}
+
 // New creates a new instance of FileKeyValueStore using provided options
 func New(opts *FileKeyValueStoreOptions) (*FileKeyValueStore, error) {
 	if opts == nil {
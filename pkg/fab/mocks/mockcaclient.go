@@ -23,12 +23,12 @@ func NewMockCAClient(orgName string, cryptoProvider core.CryptoSuite, config cor
 }
 
 // Enroll enrolls a user with a Fabric network
-func (mgr *MockCAClient) Enroll(enrollmentID string, enrollmentSecret string) error {
+func (mgr *MockCAClient) Enroll(enrollmentID string, enrollmentSecret string, opts ...api.EnrollmentOption) error {
 	return errors.New("not implemented")
 }
 
 // Reenroll re-enrolls a user
-func (mgr *MockCAClient) Reenroll(enrollmentID string) error {
+func (mgr *MockCAClient) Reenroll(enrollmentID string, opts ...api.EnrollmentOption) error {
 	return errors.New("not implemented")
 }
 
@@ -41,3 +41,68 @@ func (mgr *MockCAClient) Register(request *api.RegistrationRequest) (string, err
 func (mgr *MockCAClient) Revoke(request *api.RevocationRequest) (*api.RevocationResponse, error) {
 	return nil, errors.New("not implemented")
 }
+
+// GetAllIdentities returns all identities
+func (mgr *MockCAClient) GetAllIdentities(caname string) ([]*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetIdentity returns an identity
+func (mgr *MockCAClient) GetIdentity(id, caname string) (*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// CreateIdentity creates an identity
+func (mgr *MockCAClient) CreateIdentity(request *api.IdentityRequest) (*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// ModifyIdentity modifies an identity
+func (mgr *MockCAClient) ModifyIdentity(request *api.IdentityRequest) (*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// RemoveIdentity removes an identity
+func (mgr *MockCAClient) RemoveIdentity(request *api.RemoveIdentityRequest) (*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetAllAffiliations returns all affiliations
+func (mgr *MockCAClient) GetAllAffiliations(caname string) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetAffiliation returns an affiliation
+func (mgr *MockCAClient) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// AddAffiliation adds an affiliation
+func (mgr *MockCAClient) AddAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// ModifyAffiliation renames an affiliation
+func (mgr *MockCAClient) ModifyAffiliation(request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// RemoveAffiliation removes an affiliation
+func (mgr *MockCAClient) RemoveAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetCAInfo returns generic CA information
+func (mgr *MockCAClient) GetCAInfo(caname string) (*api.GetCAInfoResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetCertificates returns certificates
+func (mgr *MockCAClient) GetCertificates(request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GenCRL generates a CRL
+func (mgr *MockCAClient) GenCRL(request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	return nil, errors.New("not implemented")
+}
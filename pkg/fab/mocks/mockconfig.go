@@ -75,6 +75,15 @@ func (c *MockConfig) CAConfig(org string) (*config.CAConfig, error) {
 	return &caConfig, nil
 }
 
+// CAConfigs returns the mock CA config of the organization as its only CA
+func (c *MockConfig) CAConfigs(org string) ([]config.CAConfig, error) {
+	caConfig, err := c.CAConfig(org)
+	if err != nil {
+		return nil, err
+	}
+	return []config.CAConfig{*caConfig}, nil
+}
+
 //CAServerCertPems Read configuration option for the server certificate embedded pems
 func (c *MockConfig) CAServerCertPems(org string) ([]string, error) {
 	return nil, nil
@@ -9,12 +9,12 @@ package orderer
 import (
 	reqContext "context"
 	"crypto/x509"
+	"net/url"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	grpcstatus "google.golang.org/grpc/status"
 
@@ -31,12 +31,6 @@ import (
 
 var logger = logging.NewLogger("fabsdk/fab")
 
-const (
-	// GRPC max message size (same as Fabric)
-	maxCallRecvMsgSize = 100 * 1024 * 1024
-	maxCallSendMsgSize = 100 * 1024 * 1024
-)
-
 // Orderer allows a client to broadcast a transaction.
 type Orderer struct {
 	config         core.Config
@@ -48,6 +42,12 @@ type Orderer struct {
 	dialTimeout    time.Duration
 	failFast       bool
 	allowInsecure  bool
+	secured        bool
+	tlsClientCert  core.TLSClientCertConfig
+	clientCerts    comm.ClientCertsProvider
+	credential     string
+	proxyURL       *url.URL
+	grpcOptions    map[string]interface{}
 	commManager    fab.CommManager
 }
 
@@ -68,30 +68,53 @@ func New(config core.Config, opts ...Option) (*Orderer, error) {
 			return nil, err
 		}
 	}
+	orderer.secured = endpoint.AttemptSecured(orderer.url, orderer.allowInsecure)
+	if !orderer.tlsClientCert.FromIdentity {
+		clientCerts, credential, err := comm.ClientCerts(config, orderer.tlsClientCert, nil, nil)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to get TLS client certificate")
+		}
+		orderer.grpcDialOption, err = orderer.dialOpts(clientCerts)
+		if err != nil {
+			return nil, err
+		}
+		orderer.clientCerts, orderer.credential = clientCerts, credential
+	}
+
+	orderer.dialTimeout = config.TimeoutOrDefault(core.OrdererConnection)
+	orderer.url = endpoint.ToAddress(orderer.url)
+
+	return orderer, nil
+}
+
+// dialOpts returns the dial options of connections with the TLS client certificates of the provider
+func (o *Orderer) dialOpts(clientCerts comm.ClientCertsProvider) ([]grpc.DialOption, error) {
 	var grpcOpts []grpc.DialOption
-	if orderer.kap.Time > 0 {
-		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(orderer.kap))
+	if o.kap.Time > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(o.kap))
 	}
-	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(orderer.failFast)))
-	if endpoint.AttemptSecured(orderer.url, orderer.allowInsecure) {
+	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(o.failFast)))
+	if o.proxyURL != nil {
+		grpcOpts = append(grpcOpts, comm.ProxyDialOption(o.proxyURL))
+	}
+	if o.secured {
 		//tls config
-		tlsConfig, err := comm.TLSConfig(orderer.tlsCACert, orderer.serverName, config)
+		creds, err := comm.TransportCredentialsWithClientCerts(o.tlsCACert, o.serverName, o.config, clientCerts)
 		if err != nil {
 			return nil, err
 		}
-		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(creds))
 	} else {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
 
-	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
-		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
-
-	orderer.dialTimeout = config.TimeoutOrDefault(core.OrdererConnection)
-	orderer.url = endpoint.ToAddress(orderer.url)
-	orderer.grpcDialOption = grpcOpts
+	dialOpts, err := comm.GRPCDialOptions(o.grpcOptions)
+	if err != nil {
+		return nil, err
+	}
+	grpcOpts = append(grpcOpts, dialOpts...)
 
-	return orderer, nil
+	return grpcOpts, nil
 }
 
 // WithURL is a functional option for the orderer.New constructor that configures the orderer's URL.
@@ -130,6 +153,34 @@ func WithInsecure() Option {
 	}
 }
 
+// WithTLSClientCert is a functional option for the orderer.New constructor that configures the orderer's TLS client certificate
+func WithTLSClientCert(tlsClientCert core.TLSClientCertConfig) Option {
+	return func(o *Orderer) error {
+		o.tlsClientCert = tlsClientCert
+
+		return nil
+	}
+}
+
+// WithProxy is a functional option for the orderer.New constructor that configures the proxy of the orderer's connections
+func WithProxy(proxyURL *url.URL) Option {
+	return func(o *Orderer) error {
+		o.proxyURL = proxyURL
+
+		return nil
+	}
+}
+
+// WithGRPCOptions is a functional option for the orderer.New constructor that configures the grpcOptions
+// (message sizes, compression, window sizes, backoff and user agent) of the orderer's connections
+func WithGRPCOptions(grpcOptions map[string]interface{}) Option {
+	return func(o *Orderer) error {
+		o.grpcOptions = grpcOptions
+
+		return nil
+	}
+}
+
 // FromOrdererConfig is a functional option for the orderer.New constructor that configures a new orderer
 // from a apiconfig.OrdererConfig struct
 func FromOrdererConfig(ordererCfg *core.OrdererConfig) Option {
@@ -152,8 +203,11 @@ func FromOrdererConfig(ordererCfg *core.OrdererConfig) Option {
 		o.kap = getKeepAliveOptions(ordererCfg)
 		o.failFast = getFailFast(ordererCfg)
 		o.allowInsecure = isInsecureConnectionAllowed(ordererCfg)
+		o.tlsClientCert = ordererCfg.TLSClientCert
+		o.grpcOptions = ordererCfg.GRPCOptions
 
-		return nil
+		o.proxyURL, err = comm.ProxyURL(o.config, ordererCfg.Proxy)
+		return err
 	}
 }
 
@@ -221,7 +275,61 @@ func (o *Orderer) conn(ctx reqContext.Context) (*grpc.ClientConn, error) {
 		commManager = o.commManager
 	}
 
-	return commManager.DialContext(ctx, o.url, o.grpcDialOption...)
+	grpcOpts, credential := o.grpcDialOption, o.credential
+	if o.tlsClientCert.FromIdentity {
+		var err error
+		grpcOpts, credential, err = o.identityDialOpts(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return commManager.DialContext(context.RequestWithCredential(ctx, credential), o.url, grpcOpts...)
+}
+
+// TLSCertHash returns the hash of the TLS client certificate of the connections to the orderer for the request context
+// (for usage in channel headers), nil if the connections are not secured or the certificate cannot be loaded
+func (o *Orderer) TLSCertHash(ctx reqContext.Context) []byte {
+	if !o.secured {
+		return nil
+	}
+
+	clientCerts := o.clientCerts
+	if o.tlsClientCert.FromIdentity {
+		client, ok := context.RequestClientContext(ctx)
+		if !ok {
+			return nil
+		}
+		var err error
+		clientCerts, _, err = comm.ClientCerts(o.config, o.tlsClientCert, client, client.CryptoSuite())
+		if err != nil {
+			logger.Debugf("Failed to get TLS client certificate for the TLS cert hash: %s", err)
+			return nil
+		}
+	}
+	certs, err := clientCerts()
+	if err != nil {
+		return nil
+	}
+	return comm.ClientCertHash(certs)
+}
+
+// identityDialOpts returns the dial options of connections with the TLS client certificate derived from the identity
+// of the request context, and the key of the certificate
+func (o *Orderer) identityDialOpts(ctx reqContext.Context) ([]grpc.DialOption, string, error) {
+	client, ok := context.RequestClientContext(ctx)
+	if !ok {
+		return nil, "", errors.New("failed to get client context from request context")
+	}
+	clientCerts, credential, err := comm.ClientCerts(o.config, o.tlsClientCert, client, client.CryptoSuite())
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed to get TLS client certificate")
+	}
+	grpcOpts, err := o.dialOpts(clientCerts)
+	if err != nil {
+		return nil, "", err
+	}
+	return grpcOpts, credential, nil
 }
 
 func (o *Orderer) releaseConn(ctx reqContext.Context, conn *grpc.ClientConn) {
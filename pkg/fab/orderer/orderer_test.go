@@ -8,6 +8,8 @@ package orderer
 
 import (
 	reqContext "context"
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"net"
@@ -183,6 +185,35 @@ func TestNewOrdererWithMutualTLS(t *testing.T) {
 	}
 }
 
+func TestOrdererTLSCertHash(t *testing.T) {
+	tlsCert, err := endpoint.TLSConfig{Path: "../../../test/fixtures/fabricca/tls/ca/ca_root.pem"}.TLSCert()
+	if err != nil {
+		t.Fatalf("Failed to load TLS cert: %s", err)
+	}
+	tlsClientCert := core.TLSClientCertConfig{
+		Cert: endpoint.TLSConfig{Path: "../../../test/fixtures/config/mutual_tls/client_sdk_go.pem"},
+		Key:  endpoint.TLSConfig{Path: "../../../test/fixtures/config/mutual_tls/client_sdk_go-key.pem"},
+	}
+	clientCert, err := tls.LoadX509KeyPair(tlsClientCert.Cert.Path, tlsClientCert.Key.Path)
+	if err != nil {
+		t.Fatalf("Failed to load TLS client cert: %s", err)
+	}
+
+	// The hash is the hash of the TLS client certificate of the orderer rather than of the config
+	orderer, err := New(mocks.NewMockConfigCustomized(true, true, false), WithURL("grpcs://"), WithTLSCert(tlsCert), WithTLSClientCert(tlsClientCert))
+	if err != nil {
+		t.Fatalf("Failed to create orderer: %s", err)
+	}
+	expected := sha256.Sum256(clientCert.Certificate[0])
+	assert.Equal(t, expected[:], orderer.TLSCertHash(reqContext.Background()))
+
+	orderer, err = New(mocks.NewMockConfigCustomized(true, true, false), WithURL("grpc://"), WithInsecure(), WithTLSClientCert(tlsClientCert))
+	if err != nil {
+		t.Fatalf("Failed to create orderer: %s", err)
+	}
+	assert.Nil(t, orderer.TLSCertHash(reqContext.Background()), "expected no TLS cert hash without TLS")
+}
+
 func TestSendBroadcastHappy(t *testing.T) {
 
 	ordererConfig := getGRPCOpts(ordererAddr, true, false, true)
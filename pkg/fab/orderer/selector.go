@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+)
+
+// Selector orders the orderers of a channel according to the configured strategy.
+// Orderers whose circuit is open are only tried if the circuits of all other orderers are open as well.
+type Selector struct {
+	strategy core.OrdererSelectionStrategy
+	breaker  *circuitbreaker.Breaker
+	mutex    sync.Mutex
+	next     int
+}
+
+// NewSelector returns a Selector for the given policy that keeps track of failed orderers using the given circuit breaker
+func NewSelector(policy core.OrdererSelectionPolicy, breaker *circuitbreaker.Breaker) *Selector {
+	strategy := policy.Strategy
+	if strategy == "" {
+		strategy = core.Random
+	}
+	return &Selector{strategy: strategy, breaker: breaker}
+}
+
+// Select returns the orderers in the order they should be tried. Only the probe of the circuit of
+// the first orderer is claimed, the other orderers are only tried if broadcasting to it fails.
+func (s *Selector) Select(orderers []fab.Orderer) []fab.Orderer {
+	var allowed, rejected []fab.Orderer
+	for _, o := range orderers {
+		if s.breaker.Available(o.URL()) {
+			allowed = append(allowed, o)
+		} else {
+			rejected = append(rejected, o)
+		}
+	}
+	selected := append(s.order(allowed), s.order(rejected)...)
+	if len(selected) > 0 {
+		s.breaker.Allow(selected[0].URL())
+	}
+	return selected
+}
+
+// Failed records the failed broadcast in the circuit of the orderer
+func (s *Selector) Failed(orderer fab.Orderer, err error) {
+	s.breaker.Record(orderer.URL(), err)
+}
+
+// Succeeded records the successful broadcast in the circuit of the orderer
+func (s *Selector) Succeeded(orderer fab.Orderer) {
+	s.breaker.Record(orderer.URL(), nil)
+}
+
+// Endpoints returns the state of the circuits of the orderers that have failed
+func (s *Selector) Endpoints() []circuitbreaker.EndpointState {
+	return s.breaker.Endpoints()
+}
+
+func (s *Selector) order(orderers []fab.Orderer) []fab.Orderer {
+	if len(orderers) < 2 {
+		return orderers
+	}
+
+	ordered := make([]fab.Orderer, 0, len(orderers))
+	switch s.strategy {
+	case core.FirstAvailable:
+		ordered = append(ordered, orderers...)
+	case core.RoundRobin:
+		start := s.nextIndex()
+		for i := range orderers {
+			ordered = append(ordered, orderers[(start+i)%len(orderers)])
+		}
+	default:
+		for _, i := range rand.Perm(len(orderers)) {
+			ordered = append(ordered, orderers[i])
+		}
+	}
+	return ordered
+}
+
+func (s *Selector) nextIndex() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	next := s.next
+	s.next++
+	return next
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package orderer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorFirstAvailable(t *testing.T) {
+	orderers := testOrderers()
+	s := NewSelector(core.OrdererSelectionPolicy{Strategy: core.FirstAvailable}, circuitbreaker.New(1, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, []string{"o1", "o2", "o3"}, urls(s.Select(orderers)))
+	}
+}
+
+func TestSelectorRoundRobin(t *testing.T) {
+	orderers := testOrderers()
+	s := NewSelector(core.OrdererSelectionPolicy{Strategy: core.RoundRobin}, circuitbreaker.New(1, time.Minute))
+
+	assert.Equal(t, []string{"o1", "o2", "o3"}, urls(s.Select(orderers)))
+	assert.Equal(t, []string{"o2", "o3", "o1"}, urls(s.Select(orderers)))
+	assert.Equal(t, []string{"o3", "o1", "o2"}, urls(s.Select(orderers)))
+	assert.Equal(t, []string{"o1", "o2", "o3"}, urls(s.Select(orderers)))
+}
+
+func TestSelectorRandom(t *testing.T) {
+	orderers := testOrderers()
+	s := NewSelector(core.OrdererSelectionPolicy{}, circuitbreaker.New(1, time.Minute))
+
+	selected := s.Select(orderers)
+	assert.Len(t, selected, len(orderers))
+	assert.ElementsMatch(t, []string{"o1", "o2", "o3"}, urls(selected))
+}
+
+func TestSelectorCircuitBreaker(t *testing.T) {
+	orderers := testOrderers()
+	breaker := circuitbreaker.New(1, 100*time.Millisecond)
+	s := NewSelector(core.OrdererSelectionPolicy{Strategy: core.FirstAvailable}, breaker)
+
+	// Errors returned by a healthy orderer do not open its circuit
+	s.Failed(orderers[0], status.New(status.OrdererServerStatus, int32(common.Status_BAD_REQUEST), "bad request", nil))
+	assert.Equal(t, []string{"o1", "o2", "o3"}, urls(s.Select(orderers)))
+
+	// Orderers with an open circuit are tried last
+	s.Failed(orderers[0], status.New(status.OrdererClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil))
+	assert.Equal(t, []string{"o2", "o3", "o1"}, urls(s.Select(orderers)))
+	assert.Len(t, s.Endpoints(), 1)
+
+	// Orderer is probed after the open interval, the probe is only claimed for the first orderer
+	time.Sleep(150 * time.Millisecond)
+	s.Failed(orderers[1], status.New(status.OrdererClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil))
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, []string{"o1", "o2", "o3"}, urls(s.Select(orderers)))
+	assert.Equal(t, circuitbreaker.HalfOpen, breaker.State("o1"))
+	assert.Equal(t, circuitbreaker.Open, breaker.State("o2"))
+	assert.Equal(t, []string{"o2", "o3", "o1"}, urls(s.Select(orderers)))
+	assert.Equal(t, circuitbreaker.HalfOpen, breaker.State("o2"))
+
+	// A successful broadcast closes the circuit
+	s.Succeeded(orderers[0])
+	s.Succeeded(orderers[1])
+	assert.Equal(t, circuitbreaker.Closed, breaker.State("o1"))
+	assert.Empty(t, s.Endpoints())
+}
+
+func testOrderers() []fab.Orderer {
+	return []fab.Orderer{
+		mocks.NewMockOrderer("o1", nil),
+		mocks.NewMockOrderer("o2", nil),
+		mocks.NewMockOrderer("o3", nil),
+	}
+}
+
+func urls(orderers []fab.Orderer) []string {
+	var result []string
+	for _, o := range orderers {
+		result = append(result, o.URL())
+	}
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	reqContext "context"
 
 	"crypto/x509"
+	"net/url"
 
 	"github.com/spf13/cast"
 	"google.golang.org/grpc"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 )
@@ -36,6 +38,12 @@ type Peer struct {
 	failFast    bool
 	inSecure    bool
 	commManager fab.CommManager
+	// tlsClientCert is the TLS client certificate of the peer entry or of the peer's organization
+	tlsClientCert core.TLSClientCertConfig
+	// proxyURL is the URL of the proxy of the connections to the peer, nil for direct connections
+	proxyURL *url.URL
+	// grpcOptions are the grpcOptions of the peer entry applied to the GRPC dial options
+	grpcOptions map[string]interface{}
 }
 
 // Option describes a functional parameter for the New constructor
@@ -67,6 +75,9 @@ func New(config core.Config, opts ...Option) (*Peer, error) {
 			failFast:           peer.failFast,
 			allowInsecure:      peer.inSecure,
 			commManager:        peer.commManager,
+			tlsClientCert:      peer.tlsClientCert,
+			proxyURL:           peer.proxyURL,
+			grpcOptions:        peer.grpcOptions,
 		}
 		processor, err := newPeerEndorser(&endorseRequest)
 
@@ -124,6 +135,34 @@ func WithMSPID(mspID string) Option {
 	}
 }
 
+// WithTLSClientCert is a functional option for the peer.New constructor that configures the peer's TLS client certificate
+func WithTLSClientCert(tlsClientCert core.TLSClientCertConfig) Option {
+	return func(p *Peer) error {
+		p.tlsClientCert = tlsClientCert
+
+		return nil
+	}
+}
+
+// WithProxy is a functional option for the peer.New constructor that configures the proxy of the peer's connections
+func WithProxy(proxyURL *url.URL) Option {
+	return func(p *Peer) error {
+		p.proxyURL = proxyURL
+
+		return nil
+	}
+}
+
+// WithGRPCOptions is a functional option for the peer.New constructor that configures the grpcOptions
+// (message sizes, compression, window sizes, backoff and user agent) of the peer's connections
+func WithGRPCOptions(grpcOptions map[string]interface{}) Option {
+	return func(p *Peer) error {
+		p.grpcOptions = grpcOptions
+
+		return nil
+	}
+}
+
 // FromPeerConfig is a functional option for the peer.New constructor that configures a new peer
 // from a apiconfig.NetworkPeer struct
 func FromPeerConfig(peerCfg *core.NetworkPeer) Option {
@@ -148,7 +187,15 @@ func FromPeerConfig(peerCfg *core.NetworkPeer) Option {
 		p.mspID = peerCfg.MspID
 		p.kap = getKeepAliveOptions(peerCfg)
 		p.failFast = getFailFast(peerCfg)
-		return nil
+		p.grpcOptions = peerCfg.GRPCOptions
+
+		p.tlsClientCert, err = comm.PeerTLSClientCert(p.config, &peerCfg.PeerConfig, peerCfg.MspID)
+		if err != nil {
+			return err
+		}
+
+		p.proxyURL, err = comm.ProxyURL(p.config, peerCfg.Proxy)
+		return err
 	}
 }
 
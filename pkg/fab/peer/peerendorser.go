@@ -9,11 +9,11 @@ package peer
 import (
 	reqContext "context"
 	"crypto/x509"
+	"net/url"
 	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	grpcstatus "google.golang.org/grpc/status"
 
@@ -26,18 +26,17 @@ import (
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
-const (
-	// GRPC max message size (same as Fabric)
-	maxCallRecvMsgSize = 100 * 1024 * 1024
-	maxCallSendMsgSize = 100 * 1024 * 1024
-)
-
 // peerEndorser enables access to a GRPC-based endorser for running transaction proposal simulations
 type peerEndorser struct {
 	grpcDialOption []grpc.DialOption
 	target         string
 	dialTimeout    time.Duration
 	commManager    fab.CommManager
+	// credential identifies the TLS client certificate of the dial options
+	credential string
+	// request is kept to create the dial options of the calling identity
+	// if the TLS client certificate is derived from the identity
+	request *peerEndorserRequest
 }
 
 type peerEndorserRequest struct {
@@ -49,6 +48,9 @@ type peerEndorserRequest struct {
 	failFast           bool
 	allowInsecure      bool
 	commManager        fab.CommManager
+	tlsClientCert      core.TLSClientCertConfig
+	proxyURL           *url.URL
+	grpcOptions        map[string]interface{}
 }
 
 func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
@@ -56,36 +58,57 @@ func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
 		return nil, errors.New("target is required")
 	}
 
-	// Construct dialer options for the connection
+	pc := &peerEndorser{
+		target:      endpoint.ToAddress(endorseReq.target),
+		commManager: endorseReq.commManager,
+		request:     endorseReq,
+	}
+
+	if !endorseReq.tlsClientCert.FromIdentity {
+		clientCerts, credential, err := comm.ClientCerts(endorseReq.config, endorseReq.tlsClientCert, nil, nil)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to get TLS client certificate")
+		}
+		pc.grpcDialOption, err = newDialOpts(endorseReq, clientCerts)
+		if err != nil {
+			return nil, err
+		}
+		pc.credential = credential
+	}
+
+	pc.dialTimeout = endorseReq.config.TimeoutOrDefault(core.EndorserConnection)
+
+	return pc, nil
+}
+
+// newDialOpts returns the dialer options of connections with the TLS client certificates of the provider
+func newDialOpts(endorseReq *peerEndorserRequest, clientCerts comm.ClientCertsProvider) ([]grpc.DialOption, error) {
 	var grpcOpts []grpc.DialOption
 	if endorseReq.kap.Time > 0 {
 		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(endorseReq.kap))
 	}
 	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(endorseReq.failFast)))
+	if endorseReq.proxyURL != nil {
+		grpcOpts = append(grpcOpts, comm.ProxyDialOption(endorseReq.proxyURL))
+	}
 
 	if endpoint.AttemptSecured(endorseReq.target, endorseReq.allowInsecure) {
-		tlsConfig, err := comm.TLSConfig(endorseReq.certificate, endorseReq.serverHostOverride, endorseReq.config)
+		creds, err := comm.TransportCredentialsWithClientCerts(endorseReq.certificate, endorseReq.serverHostOverride, endorseReq.config, clientCerts)
 		if err != nil {
 			return nil, err
 		}
-		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(creds))
 	} else {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
 
-	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
-		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
-
-	timeout := endorseReq.config.TimeoutOrDefault(core.EndorserConnection)
-
-	pc := &peerEndorser{
-		grpcDialOption: grpcOpts,
-		target:         endpoint.ToAddress(endorseReq.target),
-		dialTimeout:    timeout,
-		commManager:    endorseReq.commManager,
+	dialOpts, err := comm.GRPCDialOptions(endorseReq.grpcOptions)
+	if err != nil {
+		return nil, err
 	}
+	grpcOpts = append(grpcOpts, dialOpts...)
 
-	return pc, nil
+	return grpcOpts, nil
 }
 
 // ProcessTransactionProposal sends the transaction proposal to a peer and returns the response.
@@ -115,7 +138,34 @@ func (p *peerEndorser) conn(ctx reqContext.Context) (*grpc.ClientConn, error) {
 	ctx, cancel := reqContext.WithTimeout(ctx, p.dialTimeout)
 	defer cancel()
 
-	return commManager.DialContext(ctx, p.target, p.grpcDialOption...)
+	grpcOpts, credential := p.grpcDialOption, p.credential
+	if p.request.tlsClientCert.FromIdentity {
+		var err error
+		grpcOpts, credential, err = p.identityDialOpts(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return commManager.DialContext(context.RequestWithCredential(ctx, credential), p.target, grpcOpts...)
+}
+
+// identityDialOpts returns the dialer options of connections with the TLS client certificate derived from
+// the identity of the request context, and the key of the certificate
+func (p *peerEndorser) identityDialOpts(ctx reqContext.Context) ([]grpc.DialOption, string, error) {
+	client, ok := context.RequestClientContext(ctx)
+	if !ok {
+		return nil, "", errors.New("failed to get client context from request context")
+	}
+	clientCerts, credential, err := comm.ClientCerts(p.request.config, p.request.tlsClientCert, client, client.CryptoSuite())
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "failed to get TLS client certificate")
+	}
+	grpcOpts, err := newDialOpts(p.request, clientCerts)
+	if err != nil {
+		return nil, "", err
+	}
+	return grpcOpts, credential, nil
 }
 
 func (p *peerEndorser) releaseConn(ctx reqContext.Context, conn *grpc.ClientConn) {
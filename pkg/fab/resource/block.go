@@ -12,6 +12,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	ab "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	ccomm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
@@ -21,7 +22,7 @@ import (
 )
 
 // block retrieves the block at the given position
-func retrieveBlock(reqCtx reqContext.Context, orderers []fab.Orderer, channel string, pos *ab.SeekPosition) (*common.Block, error) {
+func retrieveBlock(reqCtx reqContext.Context, orderer fab.Orderer, channel string, pos *ab.SeekPosition) (*common.Block, error) {
 	ctx, ok := contextImpl.RequestClientContext(reqCtx)
 	if !ok {
 		return nil, errors.New("failed get client context from reqContext for signPayload")
@@ -33,7 +34,7 @@ func retrieveBlock(reqCtx reqContext.Context, orderers []fab.Orderer, channel st
 
 	channelHeaderOpts := txn.ChannelHeaderOpts{
 		TxnHeader:   th,
-		TLSCertHash: ccomm.TLSCertHash(ctx.Config()),
+		TLSCertHash: tlsCertHash(reqCtx, ctx, orderer),
 	}
 	seekInfoHeader, err := txn.CreateChannelHeader(common.HeaderType_DELIVER_SEEK_INFO, channelHeaderOpts)
 	if err != nil {
@@ -76,7 +77,21 @@ func retrieveBlock(reqCtx reqContext.Context, orderers []fab.Orderer, channel st
 		Data:   seekInfoBytes,
 	}
 
-	return txn.SendPayload(reqCtx, &payload, orderers)
+	return txn.SendPayload(reqCtx, &payload, []fab.Orderer{orderer})
+}
+
+// tlsCertHasher is implemented by orderers that know the TLS client certificate of their connections
+type tlsCertHasher interface {
+	TLSCertHash(reqCtx reqContext.Context) []byte
+}
+
+// tlsCertHash returns the hash of the TLS client certificate with which the request is sent to the orderer
+// (for usage in channel headers), or else of the client certificates of the config
+func tlsCertHash(reqCtx reqContext.Context, ctx context.Client, orderer fab.Orderer) []byte {
+	if o, ok := orderer.(tlsCertHasher); ok {
+		return o.TLSCertHash(reqCtx)
+	}
+	return ccomm.TLSCertHash(ctx.Config())
 }
 
 // newNewestSeekPosition returns a SeekPosition that requests the newest block
@@ -18,7 +18,6 @@ import (
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
-	ccomm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
@@ -111,7 +110,7 @@ func createChannelFromEnvelope(reqCtx reqContext.Context, request api.CreateChan
 // GenesisBlockFromOrderer returns the genesis block from the defined orderer that may be
 // used in a join request
 func GenesisBlockFromOrderer(reqCtx reqContext.Context, channelName string, orderer fab.Orderer) (*common.Block, error) {
-	return retrieveBlock(reqCtx, []fab.Orderer{orderer}, channelName, newSpecificSeekPosition(0))
+	return retrieveBlock(reqCtx, orderer, channelName, newSpecificSeekPosition(0))
 }
 
 // LastConfigFromOrderer fetches the current configuration block for the specified channel
@@ -120,7 +119,7 @@ func LastConfigFromOrderer(reqCtx reqContext.Context, channelName string, ordere
 	logger.Debugf("channelConfig - start for channel %s", channelName)
 
 	// Get the newest block
-	block, err := retrieveBlock(reqCtx, []fab.Orderer{orderer}, channelName, newNewestSeekPosition())
+	block, err := retrieveBlock(reqCtx, orderer, channelName, newNewestSeekPosition())
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +133,7 @@ func LastConfigFromOrderer(reqCtx reqContext.Context, channelName string, ordere
 	logger.Debugf("channelConfig - Last config index: %d\n", lastConfig.Index)
 
 	// Get the last config block
-	block, err = retrieveBlock(reqCtx, []fab.Orderer{orderer}, channelName, newSpecificSeekPosition(lastConfig.Index))
+	block, err = retrieveBlock(reqCtx, orderer, channelName, newSpecificSeekPosition(lastConfig.Index))
 	if err != nil {
 		return nil, errors.WithMessage(err, "retrieve block failed")
 	}
@@ -196,7 +195,7 @@ func createOrUpdateChannel(reqCtx reqContext.Context, txh *txn.TransactionHeader
 	}
 	channelHeaderOpts := txn.ChannelHeaderOpts{
 		TxnHeader:   txh,
-		TLSCertHash: ccomm.TLSCertHash(ctx.Config()),
+		TLSCertHash: tlsCertHash(reqCtx, ctx, request.Orderer),
 	}
 	channelHeader, err := txn.CreateChannelHeader(common.HeaderType_CONFIG_UPDATE, channelHeaderOpts)
 	if err != nil {
@@ -24,8 +24,13 @@ type SigningManager struct {
 // @param {BCCSP} cryptoProvider - crypto provider
 // @param {Config} config - configuration provider
 // @returns {SigningManager} new signing manager
+// Objects are hashed with the hash family and security level of the config.
 func New(cryptoProvider core.CryptoSuite, config core.Config) (*SigningManager, error) {
-	return &SigningManager{cryptoProvider: cryptoProvider, hashOpts: cryptosuite.GetSHAOpts()}, nil
+	hashOpts, err := cryptosuite.GetHashOpts(config.SecurityAlgorithm(), config.SecurityLevel())
+	if err != nil {
+		return nil, errors.WithMessage(err, "signing manager hash options failed")
+	}
+	return &SigningManager{cryptoProvider: cryptoProvider, hashOpts: hashOpts}, nil
 }
 
 // Sign will sign the given object using provided key
@@ -10,9 +10,14 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	bccspwrapper "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSigningManager(t *testing.T) {
@@ -48,3 +53,56 @@ func TestSigningManager(t *testing.T) {
 	}
 
 }
+
+func TestSigningManagerHashFamilies(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to get cryptosuite: %s", err)
+	}
+	key, err := cs.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed to get public key: %s", err)
+	}
+
+	for _, family := range []string{"SHA2", "SHA3"} {
+		for _, level := range []int{256, 384} {
+			config := mock_core.NewMockConfig(mockCtrl)
+			config.EXPECT().SecurityAlgorithm().Return(family).AnyTimes()
+			config.EXPECT().SecurityLevel().Return(level).AnyTimes()
+
+			signingMgr, err := New(cs, config)
+			if err != nil {
+				t.Fatalf("Failed to create signing manager: %s", err)
+			}
+			signature, err := signingMgr.Sign([]byte("Hello"), key)
+			if err != nil {
+				t.Fatalf("Failed to sign object: %s", err)
+			}
+
+			hashOpts, err := cryptosuite.GetHashOpts(family, level)
+			if err != nil {
+				t.Fatalf("Failed to get hash options: %s", err)
+			}
+			digest, err := cs.Hash([]byte("Hello"), hashOpts)
+			if err != nil {
+				t.Fatalf("Failed to hash object: %s", err)
+			}
+			valid, err := cs.Verify(pubKey, signature, digest, nil)
+			assert.NoError(t, err)
+			assert.True(t, valid, "expected signature over %s-%d digest", family, level)
+		}
+	}
+
+	config := mock_core.NewMockConfig(mockCtrl)
+	config.EXPECT().SecurityAlgorithm().Return("SHA2").AnyTimes()
+	config.EXPECT().SecurityLevel().Return(512).AnyTimes()
+	_, err = New(cs, config)
+	assert.Error(t, err, "expected error for unsupported security level")
+}
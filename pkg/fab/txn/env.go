@@ -51,13 +51,31 @@ func (th *TransactionHeader) ChannelID() string {
 	return th.channelID
 }
 
+// WithNonce specifies the nonce to use when creating the transaction header.
+// Since the transaction ID is computed from the nonce and the creator, supplying
+// the nonce makes the transaction ID known before the proposal is created.
+func WithNonce(nonce []byte) fab.TxnHeaderOpt {
+	return func(options *fab.TxnHeaderOptions) {
+		options.Nonce = nonce
+	}
+}
+
 // NewHeader computes a TransactionID from the current user context and holds
 // metadata to create transaction proposals.
-func NewHeader(ctx contextApi.Client, channelID string) (*TransactionHeader, error) {
-	// generate a random nonce
-	nonce, err := crypto.GetRandomNonce()
-	if err != nil {
-		return nil, errors.WithMessage(err, "nonce creation failed")
+func NewHeader(ctx contextApi.Client, channelID string, opts ...fab.TxnHeaderOpt) (*TransactionHeader, error) {
+	var options fab.TxnHeaderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	nonce := options.Nonce
+	if nonce == nil {
+		// generate a random nonce
+		var err error
+		nonce, err = crypto.GetRandomNonce()
+		if err != nil {
+			return nil, errors.WithMessage(err, "nonce creation failed")
+		}
 	}
 
 	creator, err := ctx.SerializedIdentity()
@@ -65,7 +83,8 @@ func NewHeader(ctx contextApi.Client, channelID string) (*TransactionHeader, err
 		return nil, errors.WithMessage(err, "identity from context failed")
 	}
 
-	ho := cryptosuite.GetSHA256Opts() // TODO: make configurable
+	// Peers compute transaction IDs with SHA256, whatever the hash family of the config
+	ho := cryptosuite.GetSHA256Opts()
 	h, err := ctx.CryptoSuite().GetHash(ho)
 	if err != nil {
 		return nil, errors.WithMessage(err, "hash function creation failed")
@@ -87,7 +106,9 @@ func NewHeader(ctx contextApi.Client, channelID string) (*TransactionHeader, err
 }
 
 func computeTxnID(nonce, creator []byte, h hash.Hash) (string, error) {
-	b := append(nonce, creator...)
+	b := make([]byte, 0, len(nonce)+len(creator))
+	b = append(b, nonce...)
+	b = append(b, creator...)
 
 	_, err := h.Write(b)
 	if err != nil {
@@ -16,6 +16,8 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -102,11 +104,59 @@ func New(request fab.TransactionRequest) (*fab.Transaction, error) {
 	}, nil
 }
 
+// BroadcastOpt is an option for broadcasting to the orderers
+type BroadcastOpt func(*broadcastOptions)
+
+type broadcastOptions struct {
+	selector fab.OrdererSelector
+	retry    retry.Opts
+}
+
+// broadcastRetryableCodes are the broadcast errors after which the orderers are tried again
+var broadcastRetryableCodes = map[status.Group][]status.Code{
+	status.OrdererServerStatus: []status.Code{
+		status.Code(common.Status_SERVICE_UNAVAILABLE),
+	},
+}
+
+// WithOrdererSelector determines the order in which the orderers are tried and reports
+// failed orderers to the selector. Without a selector the orderers are tried in random order.
+func WithOrdererSelector(selector fab.OrdererSelector) BroadcastOpt {
+	return func(o *broadcastOptions) {
+		o.selector = selector
+	}
+}
+
+// WithBroadcastRetry sets the number of times the orderers are tried again, and the backoff
+// between attempts, when all of them fail and an orderer reported that the service is unavailable
+func WithBroadcastRetry(opts retry.Opts) BroadcastOpt {
+	return func(o *broadcastOptions) {
+		o.retry = opts
+	}
+}
+
 // Send send a transaction to the chain’s orderer service (one or more orderer endpoints) for consensus and committing to the ledger.
-func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer, opts ...BroadcastOpt) (*fab.TransactionResponse, error) {
 	if orderers == nil || len(orderers) == 0 {
 		return nil, errors.New("orderers is nil")
 	}
+
+	envelope, err := CreateSignedEnvelope(reqCtx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionResponse, err := broadcastEnvelope(reqCtx, envelope, orderers, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionResponse, nil
+}
+
+// CreateSignedEnvelope creates the envelope of a transaction, signed by the identity of the request context.
+// The envelope can be broadcast using BroadcastEnvelope.
+func CreateSignedEnvelope(reqCtx reqContext.Context, tx *fab.Transaction) (*fab.SignedEnvelope, error) {
 	if tx == nil {
 		return nil, errors.New("transaction is nil")
 	}
@@ -128,12 +178,20 @@ func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer
 	// create the payload
 	payload := common.Payload{Header: hdr, Data: txBytes}
 
-	transactionResponse, err := BroadcastPayload(reqCtx, &payload, orderers)
-	if err != nil {
-		return nil, err
+	ctx, ok := context.RequestClientContext(reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for signPayload")
 	}
+	return signPayload(ctx, &payload)
+}
 
-	return transactionResponse, nil
+// BroadcastEnvelope will send the given signed envelope to some orderer, trying the endpoints
+// in the order given by the orderer selector option until all are exhausted
+func BroadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer, opts ...BroadcastOpt) (*fab.TransactionResponse, error) {
+	if envelope == nil {
+		return nil, errors.New("envelope is nil")
+	}
+	return broadcastEnvelope(reqCtx, envelope, orderers, opts...)
 }
 
 // BroadcastPayload will send the given payload to some orderer, picking random endpoints
@@ -156,33 +214,80 @@ func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, ordere
 	return broadcastEnvelope(reqCtx, envelope, orderers)
 }
 
-// broadcastEnvelope will send the given envelope to some orderer, picking random endpoints
-// until all are exhausted
-func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+// broadcastEnvelope will send the given envelope to some orderer, trying the endpoints
+// in the order given by the orderer selector until all are exhausted
+func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer, opts ...BroadcastOpt) (*fab.TransactionResponse, error) {
 	// Check if orderers are defined
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers not set")
 	}
 
-	// Copy aside the ordering service endpoints
-	randOrderers := []fab.Orderer{}
-	for _, o := range orderers {
-		randOrderers = append(randOrderers, o)
+	options := broadcastOptions{}
+	for _, opt := range opts {
+		opt(&options)
 	}
+	if options.retry.RetryableCodes == nil {
+		options.retry.RetryableCodes = broadcastRetryableCodes
+	}
+	retryHandler := retry.New(options.retry)
 
-	// Iterate them in a random order and try broadcasting 1 by 1
-	var errResp error
-	for _, i := range rand.Perm(len(randOrderers)) {
-		resp, err := sendBroadcast(reqCtx, envelope, randOrderers[i])
-		if err != nil {
-			errResp = err
-		} else {
+	for {
+		resp, err := broadcastToOrderers(reqCtx, envelope, selectOrderers(options.selector, orderers), options.selector)
+		if err == nil {
 			return resp, nil
 		}
+		if !retryHandler.Required(err) {
+			return nil, err
+		}
+		logger.Debugf("Retrying broadcast after error: %s", err)
+	}
+}
+
+// broadcastToOrderers tries broadcasting to the orderers 1 by 1. If one of the orderers reported that
+// the service is unavailable, that error is returned so that the broadcast may be retried.
+func broadcastToOrderers(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer, selector fab.OrdererSelector) (*fab.TransactionResponse, error) {
+	var errResp, unavailableErr error
+	for _, o := range orderers {
+		resp, err := sendBroadcast(reqCtx, envelope, o)
+		if err == nil {
+			if selector != nil {
+				selector.Succeeded(o)
+			}
+			return resp, nil
+		}
+		if selector != nil {
+			selector.Failed(o, err)
+		}
+		if serviceUnavailable(err) {
+			unavailableErr = err
+		}
+		errResp = err
+	}
+	if unavailableErr != nil {
+		return nil, unavailableErr
 	}
 	return nil, errResp
 }
 
+// selectOrderers returns the orderers in the order they should be tried
+func selectOrderers(selector fab.OrdererSelector, orderers []fab.Orderer) []fab.Orderer {
+	if selector != nil {
+		return selector.Select(orderers)
+	}
+
+	// Iterate them in a random order
+	randOrderers := []fab.Orderer{}
+	for _, i := range rand.Perm(len(orderers)) {
+		randOrderers = append(randOrderers, orderers[i])
+	}
+	return randOrderers
+}
+
+func serviceUnavailable(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Group == status.OrdererServerStatus && s.Code == int32(common.Status_SERVICE_UNAVAILABLE)
+}
+
 func sendBroadcast(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderer fab.Orderer) (*fab.TransactionResponse, error) {
 	logger.Debugf("Broadcasting envelope to orderer :%s\n", orderer.URL())
 	// Send request
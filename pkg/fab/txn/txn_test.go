@@ -8,6 +8,7 @@ package txn
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -19,7 +20,12 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -216,6 +222,72 @@ func TestBroadcastEnvelope(t *testing.T) {
 	}
 }
 
+type testOrdererSelector struct {
+	failed    []string
+	succeeded []string
+}
+
+func (s *testOrdererSelector) Select(orderers []fab.Orderer) []fab.Orderer {
+	return orderers
+}
+
+func (s *testOrdererSelector) Failed(orderer fab.Orderer, err error) {
+	s.failed = append(s.failed, orderer.URL())
+}
+
+func (s *testOrdererSelector) Succeeded(orderer fab.Orderer) {
+	s.succeeded = append(s.succeeded, orderer.URL())
+}
+
+func TestBroadcastEnvelopeWithSelector(t *testing.T) {
+	user := mocks.NewMockUserWithMSPID("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	orderer1 := mocks.NewMockOrderer("1", nil)
+	orderer2 := mocks.NewMockOrderer("2", nil)
+	orderers := []fab.Orderer{orderer1, orderer2}
+
+	sigEnvelope := &fab.SignedEnvelope{
+		Signature: []byte(""),
+		Payload:   []byte(""),
+	}
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	// Orderers are tried in the order given by the selector
+	selector := &testOrdererSelector{}
+	orderer1.EnqueueSendBroadcastError(status.New(status.OrdererClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil))
+	res, err := BroadcastEnvelope(reqCtx, sigEnvelope, orderers, WithOrdererSelector(selector))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, "2", res.Orderer, "expected second orderer to be used")
+	assert.Equal(t, []string{"1"}, selector.failed)
+	assert.Equal(t, []string{"2"}, selector.succeeded)
+
+	// Broadcast is retried if the service is unavailable
+	unavailable := status.New(status.OrdererServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "service unavailable", nil)
+	orderer1.EnqueueSendBroadcastError(unavailable)
+	orderer2.EnqueueSendBroadcastError(unavailable)
+	retryOpts := retry.Opts{Attempts: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffFactor: 1}
+	res, err = BroadcastEnvelope(reqCtx, sigEnvelope, orderers, WithOrdererSelector(&testOrdererSelector{}), WithBroadcastRetry(retryOpts))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, "1", res.Orderer, "expected first orderer to be used on retry")
+
+	// Other errors are not retried
+	orderer1.EnqueueSendBroadcastError(errors.New("bad request"))
+	orderer2.EnqueueSendBroadcastError(errors.New("bad request"))
+	_, err = BroadcastEnvelope(reqCtx, sigEnvelope, orderers, WithOrdererSelector(&testOrdererSelector{}), WithBroadcastRetry(retryOpts))
+	assert.NotNil(t, err, "expected broadcast not to be retried")
+
+	// Service unavailable is reported in preference to other errors
+	orderer1.EnqueueSendBroadcastError(unavailable)
+	orderer2.EnqueueSendBroadcastError(errors.New("bad request"))
+	_, err = BroadcastEnvelope(reqCtx, sigEnvelope, orderers, WithOrdererSelector(&testOrdererSelector{}))
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "expected service unavailable status error, got %s", err)
+	assert.EqualValues(t, common.Status_SERVICE_UNAVAILABLE, s.Code)
+}
+
 func TestSendTransaction(t *testing.T) {
 	//Setup channel
 	user := mocks.NewMockUserWithMSPID("test", "1234")
@@ -310,6 +382,25 @@ func TestBuildChannelHeader(t *testing.T) {
 
 }
 
+func TestNewHeaderWithNonce(t *testing.T) {
+	user := mocks.NewMockUserWithMSPID("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	nonce := []byte("0123456789abcdef0123456789abcdef")
+
+	txh1, err := NewHeader(ctx, "test", WithNonce(nonce))
+	assert.Nil(t, err, "NewHeader failed")
+	assert.Equal(t, nonce, txh1.Nonce(), "expected supplied nonce")
+
+	txh2, err := NewHeader(ctx, "test", WithNonce(nonce))
+	assert.Nil(t, err, "NewHeader failed")
+	assert.Equal(t, txh1.TransactionID(), txh2.TransactionID(), "expected same transaction ID for same nonce and creator")
+
+	txh3, err := NewHeader(ctx, "test")
+	assert.Nil(t, err, "NewHeader failed")
+	assert.NotEqual(t, txh1.TransactionID(), txh3.TransactionID(), "expected random nonce to yield a different transaction ID")
+}
+
 func TestSignPayload(t *testing.T) {
 	user := mocks.NewMockUserWithMSPID("test", "1234")
 	ctx := mocks.NewMockContext(user)
@@ -363,3 +454,43 @@ func setupMassiveTestOrderer(numberOfOrderers int) []fab.Orderer {
 
 	return orderers
 }
+
+func TestNewHeaderSHA256(t *testing.T) {
+	user := mocks.NewMockUserWithMSPID("test", "1234")
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to get cryptosuite: %s", err)
+	}
+	// The transaction ID does not depend on the hash family and security level of the config
+	ctx := mocks.MockContext{
+		MockProviderContext: mocks.NewMockProviderContextCustom(sha3Config{mocks.NewMockConfig()}, cs, nil, nil, nil),
+		Identity:            user,
+	}
+
+	nonce := []byte("0123456789abcdef0123456789abcdef")
+	creator, err := ctx.SerializedIdentity()
+	if err != nil {
+		t.Fatalf("Failed to get serialized identity: %s", err)
+	}
+	digest, err := cs.Hash(append(append([]byte{}, nonce...), creator...), cryptosuite.GetSHA256Opts())
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+
+	txh, err := NewHeader(&ctx, "test", WithNonce(nonce))
+	assert.Nil(t, err, "NewHeader failed")
+	assert.Equal(t, fab.TransactionID(hex.EncodeToString(digest)), txh.TransactionID())
+}
+
+// sha3Config is a config whose hash family is SHA3
+type sha3Config struct {
+	core.Config
+}
+
+func (c sha3Config) SecurityAlgorithm() string {
+	return "SHA3"
+}
+
+func (c sha3Config) SecurityLevel() int {
+	return 384
+}
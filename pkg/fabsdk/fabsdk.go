@@ -8,6 +8,7 @@ SPDX-License-Identifier: Apache-2.0
 package fabsdk
 
 import (
+	"io"
 	"math/rand"
 	"time"
 
@@ -17,12 +18,17 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	sdkApi "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/chpvdr"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 )
 
+var logger = logging.NewLogger("fabsdk")
+
 // FabricSDK provides access (and context) to clients being managed by the SDK.
 type FabricSDK struct {
 	opts     options
@@ -34,6 +40,9 @@ type options struct {
 	MSP     sdkApi.MSPProviderFactory
 	Service sdkApi.ServiceProviderFactory
 	Logger  api.LoggerProvider
+
+	Interceptors comm.Interceptors
+	Revocation   *membership.RevocationSource
 }
 
 // Option configures the SDK.
@@ -131,12 +140,50 @@ func WithLoggerPkg(logger api.LoggerProvider) Option {
 	}
 }
 
+// WithUnaryClientInterceptors adds GRPC unary client interceptors to the connections of the SDK
+// to endorsers and orderers. The calls carry the SDK operation in their metadata (see comm.OperationMetadataKey).
+func WithUnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(opts *options) error {
+		opts.Interceptors.Unary = append(opts.Interceptors.Unary, interceptors...)
+		return nil
+	}
+}
+
+// WithStreamClientInterceptors adds GRPC stream client interceptors to the connections of the SDK to
+// orderers (broadcast and deliver) and event services (deliver and eventhub). The calls carry the SDK
+// operation in their metadata (see comm.OperationMetadataKey).
+func WithStreamClientInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(opts *options) error {
+		opts.Interceptors.Stream = append(opts.Interceptors.Stream, interceptors...)
+		return nil
+	}
+}
+
+// WithRevocationSource rejects the identities whose certificate is revoked by a current CRL of the source,
+// in addition to the CRLs of the channel configs. The source must be closed by the caller after the SDK is closed.
+func WithRevocationSource(source *membership.RevocationSource) Option {
+	return func(opts *options) error {
+		opts.Revocation = source
+		return nil
+	}
+}
+
 // providerInit interface allows for initializing providers
 // TODO: minimize interface
 type providerInit interface {
 	Initialize(providers contextApi.Providers) error
 }
 
+// revocationRegistry interface allows for setting the CRLs checked by the channel memberships of providers
+type revocationRegistry interface {
+	SetRevocationSource(source *membership.RevocationSource)
+}
+
+// interceptorRegistry interface allows for adding GRPC client interceptors to the connections of providers
+type interceptorRegistry interface {
+	AddInterceptors(interceptors comm.Interceptors)
+}
+
 func initSDK(sdk *FabricSDK, config core.Config, opts []Option) error {
 	for _, option := range opts {
 		err := option(&sdk.opts)
@@ -187,6 +234,22 @@ func initSDK(sdk *FabricSDK, config core.Config, opts []Option) error {
 		return errors.WithMessage(err, "failed to initialize infra provider")
 	}
 
+	if len(sdk.opts.Interceptors.Unary) > 0 || len(sdk.opts.Interceptors.Stream) > 0 {
+		registry, ok := infraProvider.(interceptorRegistry)
+		if !ok {
+			return errors.New("infra provider does not support GRPC client interceptors")
+		}
+		registry.AddInterceptors(sdk.opts.Interceptors)
+	}
+
+	if sdk.opts.Revocation != nil {
+		registry, ok := infraProvider.(revocationRegistry)
+		if !ok {
+			return errors.New("infra provider does not support revocation sources")
+		}
+		registry.SetRevocationSource(sdk.opts.Revocation)
+	}
+
 	// Initialize discovery provider
 	discoveryProvider, err := sdk.opts.Service.CreateDiscoveryProvider(config, infraProvider)
 	if err != nil {
@@ -231,9 +294,21 @@ func initSDK(sdk *FabricSDK, config core.Config, opts []Option) error {
 	return nil
 }
 
-// Close frees up caches and connections being maintained by the SDK
+// Close frees up caches and connections being maintained by the SDK,
+// including the connections of the crypto suite, such as to a remote signing service,
+// and the wallet of the crypto suite and user store
 func (sdk *FabricSDK) Close() {
 	sdk.provider.InfraProvider().Close()
+	if closer, ok := sdk.provider.CryptoSuite().(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Warnf("Closing crypto suite failed: %s", err)
+		}
+	}
+	if closer, ok := sdk.provider.UserStore().(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.Warnf("Closing user store failed: %s", err)
+		}
+	}
 }
 
 // Config returns the SDK's configuration.
@@ -7,14 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package fabsdk
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	configImpl "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defcore"
 	mockapisdk "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/mocks"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -63,6 +69,47 @@ func TestDoubleClose(t *testing.T) {
 	sdk.Close()
 }
 
+func TestCloseCryptoSuite(t *testing.T) {
+	c, err := configImpl.FromFile(sdkConfigFile)()
+	if err != nil {
+		t.Fatalf("Unexpected error from config: %v", err)
+	}
+
+	factory := &closableCryptoSuiteFactory{ProviderFactory: defcore.NewProviderFactory()}
+	sdk, err := New(WithConfig(c), WithCorePkg(factory))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	sdk.Close()
+	if factory.closed != 1 {
+		t.Fatalf("Expected the crypto suite to be closed once, closed %d times", factory.closed)
+	}
+}
+
+// closableCryptoSuiteFactory creates crypto suites that count their Close calls
+type closableCryptoSuiteFactory struct {
+	*defcore.ProviderFactory
+	closed int
+}
+
+func (f *closableCryptoSuiteFactory) CreateCryptoSuiteProvider(config core.Config) (core.CryptoSuite, error) {
+	cryptoSuite, err := f.ProviderFactory.CreateCryptoSuiteProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	return &closableCryptoSuite{CryptoSuite: cryptoSuite, closed: &f.closed}, nil
+}
+
+type closableCryptoSuite struct {
+	core.CryptoSuite
+	closed *int
+}
+
+func (c *closableCryptoSuite) Close() error {
+	*c.closed++
+	return nil
+}
+
 func TestWithCorePkg(t *testing.T) {
 	// Test New SDK with valid config file
 	c, err := configImpl.FromFile(sdkConfigFile)()
@@ -90,6 +137,71 @@ func TestWithCorePkg(t *testing.T) {
 	}
 }
 
+func TestWithClientInterceptors(t *testing.T) {
+	c, err := configImpl.FromFile(sdkConfigFile)()
+	if err != nil {
+		t.Fatalf("Unexpected error from config: %v", err)
+	}
+
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	sdk, err := New(WithConfig(c), WithUnaryClientInterceptors(unary), WithStreamClientInterceptors(stream))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	sdk.Close()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	factory := mockapisdk.NewMockCoreProviderFactory(mockCtrl)
+
+	factory.EXPECT().CreateCryptoSuiteProvider(c).Return(nil, nil)
+	factory.EXPECT().CreateSigningManager(nil, c).Return(nil, nil)
+	factory.EXPECT().CreateInfraProvider(gomock.Any()).Return(&fabmocks.MockInfraProvider{}, nil)
+
+	_, err = New(WithConfig(c), WithCorePkg(factory), WithUnaryClientInterceptors(unary))
+	if err == nil {
+		t.Fatal("Expected error for infra provider without support of interceptors")
+	}
+}
+
+func TestWithRevocationSource(t *testing.T) {
+	c, err := configImpl.FromFile(sdkConfigFile)()
+	if err != nil {
+		t.Fatalf("Unexpected error from config: %v", err)
+	}
+
+	source, err := membership.NewRevocationSource(0, func() ([][]byte, error) { return nil, nil })
+	if err != nil {
+		t.Fatalf("Unexpected error from revocation source: %v", err)
+	}
+	defer source.Close()
+
+	sdk, err := New(WithConfig(c), WithRevocationSource(source))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	sdk.Close()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	factory := mockapisdk.NewMockCoreProviderFactory(mockCtrl)
+
+	factory.EXPECT().CreateCryptoSuiteProvider(c).Return(nil, nil)
+	factory.EXPECT().CreateSigningManager(nil, c).Return(nil, nil)
+	factory.EXPECT().CreateInfraProvider(gomock.Any()).Return(&fabmocks.MockInfraProvider{}, nil)
+
+	_, err = New(WithConfig(c), WithCorePkg(factory), WithRevocationSource(source))
+	if err == nil {
+		t.Fatal("Expected error for infra provider without support of revocation sources")
+	}
+}
+
 func TestWithMSPPkg(t *testing.T) {
 	// Test New SDK with valid config file
 	c, err := configImpl.FromFile(sdkConfigFile)()
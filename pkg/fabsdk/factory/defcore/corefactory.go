@@ -11,7 +11,9 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/logging/api"
 
-	cryptosuiteimpl "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	// Register the REMOTE security provider
+	_ "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/remote"
 	signingMgr "github.com/hyperledger/fabric-sdk-go/pkg/fab/signingmgr"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/fabpvdr"
 
@@ -28,10 +30,10 @@ func NewProviderFactory() *ProviderFactory {
 	return &f
 }
 
-// CreateCryptoSuiteProvider returns a new default implementation of BCCSP
+// CreateCryptoSuiteProvider returns the cryptosuite of the registered security provider selected by the config.
+// The SW and REMOTE providers are always available; other providers are registered by importing their packages.
 func (f *ProviderFactory) CreateCryptoSuiteProvider(config core.Config) (core.CryptoSuite, error) {
-	cryptoSuiteProvider, err := cryptosuiteimpl.GetSuiteByConfig(config)
-	return cryptoSuiteProvider, err
+	return cryptosuite.GetSuiteByConfig(config)
 }
 
 // CreateSigningManager returns a new default implementation of signing manager
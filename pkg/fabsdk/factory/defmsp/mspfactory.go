@@ -9,6 +9,7 @@ package defmsp
 import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/wallet"
 	kvs "github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/msppvdr"
 	mspimpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
@@ -32,6 +33,14 @@ func (f *ProviderFactory) CreateUserStore(config core.Config) (msp.UserStore, er
 	if err != nil {
 		return nil, errors.WithMessage(err, "Unable to retrieve client config")
 	}
+	if clientCofig.CredentialStore.Type == wallet.CredentialStoreType {
+		w, err := wallet.FromConfig(config)
+		if err != nil {
+			return nil, errors.WithMessage(err, "opening wallet failed")
+		}
+		return w.UserStore(), nil
+	}
+
 	stateStorePath := clientCofig.CredentialStore.Path
 
 	stateStore, err := kvs.New(&kvs.FileKeyValueStoreOptions{Path: stateStorePath})
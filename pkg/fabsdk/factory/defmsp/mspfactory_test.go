@@ -8,6 +8,8 @@ package defmsp
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -15,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/wallet"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defcore"
 	mspimpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
@@ -65,6 +68,43 @@ func TestCreateUserStoreByConfig(t *testing.T) {
 	}
 }
 
+func TestCreateUserStoreWallet(t *testing.T) {
+	factory := NewProviderFactory()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockConfig := mock_core.NewMockConfig(mockCtrl)
+
+	walletPath, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatalf("Failed to create wallet directory: %v", err)
+	}
+	defer os.RemoveAll(walletPath)
+	os.Setenv(wallet.DefaultPassphraseEnv, "passphrase")
+	defer os.Unsetenv(wallet.DefaultPassphraseEnv)
+
+	mockClientConfig := core.ClientConfig{
+		CredentialStore: core.CredentialStoreType{
+			Path: walletPath,
+			Type: wallet.CredentialStoreType,
+		},
+	}
+	mockConfig.EXPECT().Client().Return(&mockClientConfig, nil).AnyTimes()
+	mockConfig.EXPECT().CredentialStorePath().Return(walletPath)
+
+	userStore, err := factory.CreateUserStore(mockConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error creating user store %v", err)
+	}
+	walletUserStore, ok := userStore.(*wallet.UserStore)
+	if !ok {
+		t.Fatalf("Unexpected user store created")
+	}
+	if err := walletUserStore.Close(); err != nil {
+		t.Fatalf("Unexpected error closing user store %v", err)
+	}
+}
+
 func TestCreateUserStoreEmptyConfig(t *testing.T) {
 	factory := NewProviderFactory()
 
@@ -8,13 +8,16 @@ package fabpvdr
 
 import (
 	reqContext "context"
+	"sync"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/circuitbreaker"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/lazycache"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	configComm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	channelImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/chconfig"
@@ -45,6 +48,10 @@ type InfraProvider struct {
 	providerContext   context.Providers
 	commManager       *comm.CachingConnector
 	eventServiceCache cache
+	ordererSelectors  sync.Map
+	membershipCaches  sync.Map
+	revocation        *membership.RevocationSource
+	unsubscribeTLS    func()
 }
 
 type fabContext struct {
@@ -60,8 +67,15 @@ func New(config core.Config, opts ...options.Opt) *InfraProvider {
 
 	cc := comm.NewCachingConnector(sweepTime, idleTime)
 
+	// Connections are dialed anew with the current credentials when the TLS credentials change
+	unsubscribeTLS := func() {}
+	if p, ok := config.(configComm.TLSCredentialSourceProvider); ok && p.TLSCredentialSource() != nil {
+		unsubscribeTLS = p.TLSCredentialSource().Subscribe(cc.Drain)
+	}
+
 	return &InfraProvider{
-		commManager: cc,
+		commManager:    cc,
+		unsubscribeTLS: unsubscribeTLS,
 		eventServiceCache: lazycache.New(
 			"Event_Service_Cache",
 			func(key lazycache.Key) (interface{}, error) {
@@ -85,6 +99,8 @@ func (f *InfraProvider) Initialize(providers context.Providers) error {
 
 // Close frees resources and caches.
 func (f *InfraProvider) Close() {
+	f.unsubscribeTLS()
+
 	logger.Debug("Closing event service cache...")
 	f.eventServiceCache.Close()
 
@@ -99,6 +115,11 @@ func (f *InfraProvider) CommManager() fab.CommManager {
 	return f.commManager
 }
 
+// AddInterceptors adds GRPC client interceptors to the connections of the comm manager
+func (f *InfraProvider) AddInterceptors(interceptors comm.Interceptors) {
+	f.commManager.AddInterceptors(interceptors)
+}
+
 // CreateEventService creates the event service.
 func (f *InfraProvider) CreateEventService(ctx fab.ClientContext, chConfig fab.ChannelCfg) (fab.EventService, error) {
 	key, err := NewCacheKey(ctx, chConfig)
@@ -118,14 +139,56 @@ func (f *InfraProvider) CreateChannelConfig(channelID string) (fab.ChannelConfig
 	return chconfig.New(channelID)
 }
 
+// SetRevocationSource sets the source of the CRLs checked by the channel memberships,
+// in addition to the CRLs of the channel configs. The source is not closed by the provider.
+func (f *InfraProvider) SetRevocationSource(source *membership.RevocationSource) {
+	f.revocation = source
+}
+
 // CreateChannelMembership returns a channel member identifier
+// The memberships of a channel share a cache of deserialized and validated identities.
 func (f *InfraProvider) CreateChannelMembership(cfg fab.ChannelCfg) (fab.ChannelMembership, error) {
-	return membership.New(membership.Context{Providers: f.providerContext}, cfg)
+	opts := []membership.Option{membership.WithCache(f.membershipCache(cfg.ID()))}
+	if f.revocation != nil {
+		opts = append(opts, membership.WithRevocationSource(f.revocation))
+	}
+	return membership.New(membership.Context{Providers: f.providerContext}, cfg, opts...)
+}
+
+// membershipCache returns the identity cache of the channel, which is invalidated when the channel's MSPs change
+func (f *InfraProvider) membershipCache(channelID string) *membership.Cache {
+	if cache, ok := f.membershipCaches.Load(channelID); ok {
+		return cache.(*membership.Cache)
+	}
+	cache, _ := f.membershipCaches.LoadOrStore(channelID, membership.NewCache(membership.DefaultCacheSize))
+	return cache.(*membership.Cache)
 }
 
 // CreateChannelTransactor initializes the transactor
 func (f *InfraProvider) CreateChannelTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg) (fab.Transactor, error) {
-	return channelImpl.NewTransactor(reqCtx, cfg)
+	selector, err := f.ordererSelector(cfg.ID())
+	if err != nil {
+		return nil, err
+	}
+	return channelImpl.NewTransactor(reqCtx, cfg, channelImpl.WithOrdererSelector(selector))
+}
+
+// ordererSelector returns the orderer selector of the channel, which is shared
+// by the transactors of the channel so that failed orderers are remembered
+func (f *InfraProvider) ordererSelector(channelID string) (fab.OrdererSelector, error) {
+	if selector, ok := f.ordererSelectors.Load(channelID); ok {
+		return selector.(fab.OrdererSelector), nil
+	}
+
+	config := f.providerContext.Config()
+	policies, err := channelImpl.ChannelPolicies(config, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker := circuitbreaker.NewFromPolicy(policies.CircuitBreaker, config.TimeoutOrDefault(core.DiscoveryGreylistExpiry))
+	selector, _ := f.ordererSelectors.LoadOrStore(channelID, orderer.NewSelector(policies.OrdererSelection, breaker))
+	return selector.(fab.OrdererSelector), nil
 }
 
 // CreatePeerFromConfig returns a new default implementation of Peer based configuration
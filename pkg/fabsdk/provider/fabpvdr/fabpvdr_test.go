@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	coreMocks "github.com/hyperledger/fabric-sdk-go/pkg/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel/membership"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	peerImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
@@ -65,6 +66,23 @@ func TestCreateMembership(t *testing.T) {
 	m, err := p.CreateChannelMembership(mocks.NewMockChannelCfg(""))
 	assert.Nil(t, err)
 	assert.NotNil(t, m)
+
+	assert.True(t, p.membershipCache("mychannel") == p.membershipCache("mychannel"), "expected the memberships of a channel to share the cache")
+	assert.False(t, p.membershipCache("mychannel") == p.membershipCache("otherchannel"), "expected a cache per channel")
+}
+
+func TestCreateMembershipWithRevocationSource(t *testing.T) {
+	p := newMockInfraProvider(t)
+	source, err := membership.NewRevocationSource(0, func() ([][]byte, error) { return nil, nil })
+	if err != nil {
+		t.Fatalf("NewRevocationSource failed: %v", err)
+	}
+	defer source.Close()
+	p.SetRevocationSource(source)
+
+	m, err := p.CreateChannelMembership(mocks.NewMockChannelCfg(""))
+	assert.Nil(t, err)
+	assert.NotNil(t, m)
 }
 
 func newMockInfraProvider(t *testing.T) *InfraProvider {
@@ -8,6 +8,7 @@ package api
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -17,10 +18,23 @@ var (
 
 // CAClient provides management of identities in a Fabric network
 type CAClient interface {
-	Enroll(enrollmentID string, enrollmentSecret string) error
-	Reenroll(enrollmentID string) error
+	Enroll(enrollmentID string, enrollmentSecret string, opts ...EnrollmentOption) error
+	Reenroll(enrollmentID string, opts ...EnrollmentOption) error
 	Register(request *RegistrationRequest) (string, error)
 	Revoke(request *RevocationRequest) (*RevocationResponse, error)
+	GetAllIdentities(caname string) ([]*IdentityResponse, error)
+	GetIdentity(id, caname string) (*IdentityResponse, error)
+	CreateIdentity(request *IdentityRequest) (*IdentityResponse, error)
+	ModifyIdentity(request *IdentityRequest) (*IdentityResponse, error)
+	RemoveIdentity(request *RemoveIdentityRequest) (*IdentityResponse, error)
+	GetAllAffiliations(caname string) (*AffiliationResponse, error)
+	GetAffiliation(affiliation, caname string) (*AffiliationResponse, error)
+	AddAffiliation(request *AffiliationRequest) (*AffiliationResponse, error)
+	ModifyAffiliation(request *ModifyAffiliationRequest) (*AffiliationResponse, error)
+	RemoveAffiliation(request *AffiliationRequest) (*AffiliationResponse, error)
+	GetCAInfo(caname string) (*GetCAInfoResponse, error)
+	GetCertificates(request *GetCertificatesRequest) (*GetCertificatesResponse, error)
+	GenCRL(request *GenCRLRequest) (*GenCRLResponse, error)
 }
 
 // AttributeRequest is a request for an attribute.
@@ -29,6 +43,66 @@ type AttributeRequest struct {
 	Optional bool
 }
 
+// EnrollmentOptions holds the optional parameters of an enrollment or re-enrollment request
+type EnrollmentOptions struct {
+	// CAName is the name of the CA to connect to.
+	// If omitted, Enroll uses the CA name of the organization's CA config and Reenroll
+	// uses the CA that issued the enrollment certificate of the user
+	CAName string
+	// Profile is the name of the signing profile to use in issuing the certificate
+	Profile string
+	// AttrReqs are requests for attributes to add to the certificate.
+	// Each attribute is added only if the identity owns the attribute.
+	AttrReqs []*AttributeRequest
+	// CSR is the Certificate Signing Request info
+	CSR *CSRInfo
+}
+
+// EnrollmentOption describes a functional parameter for Enroll and Reenroll
+type EnrollmentOption func(*EnrollmentOptions) error
+
+// CANameMetadataKey is the key of the user metadata holding the name of the CA
+// that issued the enrollment certificate of the user
+const CANameMetadataKey = "caName"
+
+// WithCAName enrollment option specifies the name of the CA to enroll with
+func WithCAName(caName string) EnrollmentOption {
+	return func(o *EnrollmentOptions) error {
+		o.CAName = caName
+		return nil
+	}
+}
+
+// CSRInfo is the Certificate Signing Request info
+type CSRInfo struct {
+	// CN is the common name of the certificate. Fabric CA requires it to be the enrollment ID,
+	// which is also the default
+	CN string
+	// Names are the subject names of the certificate
+	Names []CSRName
+	// Hosts are the host names and IP addresses of the certificate's subject alternative names.
+	// If omitted, the local host name is used
+	Hosts []string
+	// KeyRequest specifies the key algorithm and size. If omitted, an ECDSA P-256 key is generated
+	KeyRequest *KeyRequest
+}
+
+// CSRName contains the subject fields of a certificate
+type CSRName struct {
+	C            string
+	ST           string
+	L            string
+	O            string
+	OU           string
+	SerialNumber string
+}
+
+// KeyRequest specifies the algorithm ("ecdsa") and size (in bits) of the key to be generated
+type KeyRequest struct {
+	Algo string
+	Size int
+}
+
 // RegistrationRequest defines the attributes required to register a user with the CA
 type RegistrationRequest struct {
 	// Name is the unique name of the identity
@@ -55,6 +129,93 @@ type Attribute struct {
 	Name  string
 	Key   string
 	Value string
+	// ECert indicates that the attribute is added to enrollment certificates by default,
+	// even if it is not requested on enrollment
+	ECert bool
+}
+
+// IdentityRequest represents the request to add or modify an identity on the CA
+type IdentityRequest struct {
+	// ID is the unique identifier (enrollment ID) of the identity
+	ID string
+	// Affiliation of the identity, e.g. org1.department1
+	Affiliation string
+	// Attributes associated with this identity
+	Attributes []Attribute
+	// Type of identity (e.g. "peer, app, user").
+	// If omitted when creating an identity, the CA uses "user"
+	Type string
+	// MaxEnrollments is the number of times the secret can be reused to enroll.
+	// If omitted, this defaults to max_enrollments configured on the server
+	MaxEnrollments int
+	// Secret is the enrollment secret. If not specified when creating an identity,
+	// a random secret is generated and returned in the response
+	Secret string
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// RemoveIdentityRequest represents the request to remove an identity from the CA
+type RemoveIdentityRequest struct {
+	// ID is the unique identifier (enrollment ID) of the identity
+	ID string
+	// Force removal of the identity, even if it is the caller's own identity
+	Force bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// IdentityResponse is the response from the CA for identity requests
+type IdentityResponse struct {
+	ID             string
+	Affiliation    string
+	Type           string
+	Attributes     []Attribute
+	MaxEnrollments int
+	// Secret is only returned when an identity is created or its secret is modified
+	Secret string
+	CAName string
+}
+
+// AffiliationRequest represents the request to add or remove an affiliation on the CA
+type AffiliationRequest struct {
+	// Name of the affiliation, e.g. org1.department1
+	Name string
+	// Force creates the parent affiliations if they do not exist when adding an affiliation,
+	// and removes the child affiliations and identities when removing an affiliation
+	Force bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// ModifyAffiliationRequest represents the request to rename an affiliation on the CA
+type ModifyAffiliationRequest struct {
+	AffiliationRequest
+	// NewName is the new name of the affiliation
+	NewName string
+}
+
+// AffiliationResponse is the response from the CA for affiliation requests
+type AffiliationResponse struct {
+	AffiliationInfo
+	CAName string
+}
+
+// AffiliationInfo contains the affiliation name, its child affiliations and
+// the identities associated with the affiliation
+type AffiliationInfo struct {
+	Name         string
+	Affiliations []AffiliationInfo
+	Identities   []IdentityInfo
+}
+
+// IdentityInfo contains information about an identity
+type IdentityInfo struct {
+	ID             string
+	Type           string
+	Affiliation    string
+	Attributes     []Attribute
+	MaxEnrollments int
 }
 
 // RevocationRequest defines the attributes required to revoke credentials with the CA
@@ -89,3 +250,84 @@ type RevokedCert struct {
 	// AKI of the revoked certificate
 	AKI string
 }
+
+// GetCAInfoResponse contains the generic information of a CA
+type GetCAInfoResponse struct {
+	// CAName is the name of the CA
+	CAName string
+	// CAChain is the PEM-encoded certificate chain of the CA.
+	// The first certificate of the chain is the root CA certificate
+	CAChain []byte
+	// Version of the CA server
+	Version string
+}
+
+// GetCertificatesRequest selects the certificates issued by the CA.
+// Unset fields do not restrict the selection.
+type GetCertificatesRequest struct {
+	// ID is the enrollment ID of the identity the certificates were issued to
+	ID string
+	// Serial number of the certificate
+	Serial string
+	// AKI (Authority Key Identifier) of the certificate
+	AKI string
+	// RevokedStart and RevokedEnd select the certificates revoked within this window
+	RevokedStart time.Time
+	RevokedEnd   time.Time
+	// ExpiredStart and ExpiredEnd select the certificates expiring within this window
+	ExpiredStart time.Time
+	ExpiredEnd   time.Time
+	// NotRevoked excludes revoked certificates
+	NotRevoked bool
+	// NotExpired excludes expired certificates
+	NotExpired bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// GetCertificatesResponse contains the certificates selected by a GetCertificatesRequest
+type GetCertificatesResponse struct {
+	// CAName is the name of the CA that issued the certificates
+	CAName string
+	// Certs are the PEM-encoded certificates
+	Certs [][]byte
+}
+
+// GenCRLRequest selects the revoked certificates included in a CRL.
+// Unset fields do not restrict the selection.
+type GenCRLRequest struct {
+	// RevokedAfter and RevokedBefore select the certificates revoked within this window
+	RevokedAfter  time.Time
+	RevokedBefore time.Time
+	// ExpireAfter and ExpireBefore select the certificates expiring within this window
+	ExpireAfter  time.Time
+	ExpireBefore time.Time
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// GenCRLResponse contains the CRL generated by the CA
+type GenCRLResponse struct {
+	// CRL is the PEM-encoded certificate revocation list
+	CRL []byte
+}
+
+// IdentityBundleVersion is the version of the identity bundle format
+const IdentityBundleVersion = 1
+
+// IdentityBundle is a portable representation of an identity, used to move
+// identities between user stores. It is serialized as JSON.
+type IdentityBundle struct {
+	// Version is the version of the bundle format
+	Version int `json:"version"`
+	// MspID is the MSP ID of the identity's organization
+	MspID string `json:"mspId"`
+	// Name is the user name of the identity
+	Name string `json:"name"`
+	// Certificate is the PEM-encoded enrollment certificate
+	Certificate string `json:"certificate"`
+	// PrivateKey is the PEM-encoded (PKCS#8) private key
+	PrivateKey string `json:"privateKey"`
+	// Metadata is optional information about the identity, stored with the user
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
@@ -29,7 +29,7 @@ type CAClientImpl struct {
 	identityManager msp.IdentityManager
 	userStore       msp.UserStore
 	adapter         *fabricCAAdapter
-	registrar       core.EnrollCredentials
+	caConfigs       []core.CAConfig
 }
 
 // NewCAClient creates a new CA CAClient instance
@@ -62,24 +62,16 @@ func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore
 		return nil, errors.New("no CAs configured")
 	}
 
-	var caConfig *core.CAConfig
-	var adapter *fabricCAAdapter
-	var registrar core.EnrollCredentials
-
-	// Currently, an organization can be associated with only one CA
+	// The CAs of the organization are selected by CA name, requests to a CA are authenticated by the registrar of the CA
 	caName := orgConfig.CertificateAuthorities[0]
-	caConfig, err = config.CAConfig(orgName)
-	if err == nil {
-		adapter, err = newFabricCAAdapter(orgName, cryptoSuite, config)
-		if err == nil {
-			registrar = caConfig.Registrar
-		} else {
-			return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
-		}
-	} else {
+	caConfigs, err := config.CAConfigs(orgName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
+	}
+	adapter, err := newFabricCAAdapter(caConfigs, cryptoSuite, config)
+	if err != nil {
 		return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
 	}
-
 	mgr := &CAClientImpl{
 		orgName:         orgName,
 		orgMspID:        orgConfig.MspID,
@@ -88,7 +80,7 @@ func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore
 		identityManager: identityManager,
 		userStore:       userStore,
 		adapter:         adapter,
-		registrar:       registrar,
+		caConfigs:       caConfigs,
 	}
 	return mgr, nil
 }
@@ -97,10 +89,12 @@ func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore
 // A new key pair is generated for the user. The private key and the
 // enrollment certificate issued by the CA are stored in SDK stores.
 // They can be retrieved by calling IdentityManager.GetSigningIdentity().
+// The name of the CA is recorded in the metadata of the user, see api.CANameMetadataKey.
 //
 // enrollmentID The registered ID to use for enrollment
 // enrollmentSecret The secret associated with the enrollment ID
-func (c *CAClientImpl) Enroll(enrollmentID string, enrollmentSecret string) error {
+// opts Optional attribute requests, signing profile, CSR info and CA name
+func (c *CAClientImpl) Enroll(enrollmentID string, enrollmentSecret string, opts ...api.EnrollmentOption) error {
 
 	if c.adapter == nil {
 		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
@@ -111,25 +105,29 @@ func (c *CAClientImpl) Enroll(enrollmentID string, enrollmentSecret string) erro
 	if enrollmentSecret == "" {
 		return errors.New("enrollmentSecret is required")
 	}
-	// TODO add attributes
-	cert, err := c.adapter.Enroll(enrollmentID, enrollmentSecret)
+	options, err := enrollmentOptions(opts)
 	if err != nil {
-		return errors.Wrap(err, "enroll failed")
-	}
-	userData := &msp.UserData{
-		MspID: c.orgMspID,
-		Name:  enrollmentID,
-		EnrollmentCertificate: cert,
+		return errors.WithMessage(err, "enroll failed")
 	}
-	err = c.userStore.Store(userData)
+	return c.enroll(enrollmentID, enrollmentSecret, enrollmentID, options)
+}
+
+// enroll enrolls the registered user with the CA and stores the enrollment certificate under the given user name
+func (c *CAClientImpl) enroll(enrollmentID string, enrollmentSecret string, userName string, options *api.EnrollmentOptions) error {
+	cert, err := c.adapter.Enroll(enrollmentID, enrollmentSecret, options)
 	if err != nil {
 		return errors.Wrap(err, "enroll failed")
 	}
+	_, caName := c.adapter.ca(options.CAName)
+	if err := c.storeUser(userName, cert, caName); err != nil {
+		return errors.Wrap(err, "enroll failed")
+	}
 	return nil
 }
 
-// Reenroll an enrolled user in order to obtain a new signed X509 certificate
-func (c *CAClientImpl) Reenroll(enrollmentID string) error {
+// Reenroll an enrolled user in order to obtain a new signed X509 certificate.
+// Unless a CA name is given, the user is re-enrolled with the CA that issued its enrollment certificate.
+func (c *CAClientImpl) Reenroll(enrollmentID string, opts ...api.EnrollmentOption) error {
 
 	if c.adapter == nil {
 		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
@@ -139,28 +137,65 @@ func (c *CAClientImpl) Reenroll(enrollmentID string) error {
 		return errors.New("user name missing")
 	}
 
+	options, err := enrollmentOptions(opts)
+	if err != nil {
+		return errors.WithMessage(err, "reenroll failed")
+	}
+
 	user, err := c.identityManager.GetUser(enrollmentID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve user: %s", enrollmentID)
 	}
 
-	cert, err := c.adapter.Reenroll(user.PrivateKey(), user.EnrollmentCertificate())
+	if options.CAName == "" {
+		options.CAName = c.issuingCA(user.Name())
+	}
+
+	enrollmentCert, privateKey := enrollment(user)
+	cert, err := c.adapter.Reenroll(privateKey, enrollmentCert, options)
 	if err != nil {
 		return errors.Wrap(err, "reenroll failed")
 	}
-	userData := &msp.UserData{
-		MspID: c.orgMspID,
-		Name:  user.Name(),
-		EnrollmentCertificate: cert,
-	}
-	err = c.userStore.Store(userData)
-	if err != nil {
+	_, caName := c.adapter.ca(options.CAName)
+	if err := c.storeUser(user.Name(), cert, caName); err != nil {
 		return errors.Wrap(err, "reenroll failed")
 	}
 
 	return nil
 }
 
+// issuingCA returns the name of the CA recorded in the metadata of the user, if any
+func (c *CAClientImpl) issuingCA(userName string) string {
+	stored, err := c.userStore.Load(msp.UserIdentifier{MspID: c.orgMspID, Name: userName})
+	if err != nil {
+		return ""
+	}
+	return stored.Metadata[api.CANameMetadataKey]
+}
+
+// storeUser stores the enrollment certificate of the user, keeping the metadata of the user
+// and recording the name of the CA that issued the certificate
+func (c *CAClientImpl) storeUser(userName string, cert []byte, caName string) error {
+	userData := &msp.UserData{
+		MspID:                 c.orgMspID,
+		Name:                  userName,
+		EnrollmentCertificate: cert,
+	}
+	metadata := map[string]string{}
+	if stored, err := c.userStore.Load(userIdentifier(userData)); err == nil {
+		for key, value := range stored.Metadata {
+			metadata[key] = value
+		}
+	}
+	if caName != "" {
+		metadata[api.CANameMetadataKey] = caName
+	}
+	if len(metadata) > 0 {
+		userData.Metadata = metadata
+	}
+	return c.userStore.Store(userData)
+}
+
 // Register a User with the Fabric CA
 // request: Registration Request
 // Returns Enrolment Secret
@@ -168,7 +203,11 @@ func (c *CAClientImpl) Register(request *api.RegistrationRequest) (string, error
 	if c.adapter == nil {
 		return "", fmt.Errorf("no CAs configured for organization: %s", c.orgName)
 	}
-	if c.registrar.EnrollID == "" {
+	var caName string
+	if request != nil {
+		caName = request.CAName
+	}
+	if credentials, _ := c.registrar(caName); credentials.EnrollID == "" {
 		return "", api.ErrCARegistrarNotFound
 	}
 	// Validate registration request
@@ -179,7 +218,7 @@ func (c *CAClientImpl) Register(request *api.RegistrationRequest) (string, error
 		return "", errors.New("request.Name is required")
 	}
 
-	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	registrar, err := c.getRegistrar(caName)
 	if err != nil {
 		return "", err
 	}
@@ -199,7 +238,11 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 	if c.adapter == nil {
 		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
 	}
-	if c.registrar.EnrollID == "" {
+	var caName string
+	if request != nil {
+		caName = request.CAName
+	}
+	if credentials, _ := c.registrar(caName); credentials.EnrollID == "" {
 		return nil, api.ErrCARegistrarNotFound
 	}
 	// Validate revocation request
@@ -207,7 +250,7 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 		return nil, errors.New("revocation request is required")
 	}
 
-	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	registrar, err := c.getRegistrar(caName)
 	if err != nil {
 		return nil, err
 	}
@@ -219,27 +262,270 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 	return resp, nil
 }
 
-func (c *CAClientImpl) getRegistrar(enrollID string, enrollSecret string) (*msp.SigningIdentity, error) {
+// GetAllIdentities returns all identities that the registrar is authorized to see
+// caname: name of the CA (optional)
+func (c *CAClientImpl) GetAllIdentities(caname string) ([]*api.IdentityResponse, error) {
+	registrar, err := c.identityRegistrar(caname)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAllIdentities(registrar.PrivateKey, registrar.EnrollmentCert, caname)
+}
+
+// GetIdentity returns the identity with the given ID
+// id: enrollment ID of the identity
+// caname: name of the CA (optional)
+func (c *CAClientImpl) GetIdentity(id, caname string) (*api.IdentityResponse, error) {
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+
+	registrar, err := c.identityRegistrar(caname)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetIdentity(registrar.PrivateKey, registrar.EnrollmentCert, id, caname)
+}
+
+// CreateIdentity creates a new identity with the Fabric CA server. An enrollment secret
+// is returned in the response if no secret is given in the request.
+// request: Identity Request
+func (c *CAClientImpl) CreateIdentity(request *api.IdentityRequest) (*api.IdentityResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide identity request")
+	}
+	if request.ID == "" {
+		return nil, errors.New("ID is required")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.CreateIdentity(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// ModifyIdentity modifies an existing identity. Fields that are not set in the request are not modified.
+// request: Identity Request
+func (c *CAClientImpl) ModifyIdentity(request *api.IdentityRequest) (*api.IdentityResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide identity request")
+	}
+	if request.ID == "" {
+		return nil, errors.New("ID is required")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.ModifyIdentity(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// RemoveIdentity removes an existing identity. The CA server must allow identity removal.
+// request: Remove Identity Request
+func (c *CAClientImpl) RemoveIdentity(request *api.RemoveIdentityRequest) (*api.IdentityResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide remove identity request")
+	}
+	if request.ID == "" {
+		return nil, errors.New("ID is required")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.RemoveIdentity(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// GetAllAffiliations returns the tree of all affiliations that the registrar is authorized to see
+// caname: name of the CA (optional)
+func (c *CAClientImpl) GetAllAffiliations(caname string) (*api.AffiliationResponse, error) {
+	registrar, err := c.identityRegistrar(caname)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAllAffiliations(registrar.PrivateKey, registrar.EnrollmentCert, caname)
+}
+
+// GetAffiliation returns the affiliation with the given name, including its child affiliations
+// affiliation: name of the affiliation, e.g. org1.department1
+// caname: name of the CA (optional)
+func (c *CAClientImpl) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	if affiliation == "" {
+		return nil, errors.New("affiliation is required")
+	}
+
+	registrar, err := c.identityRegistrar(caname)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAffiliation(registrar.PrivateKey, registrar.EnrollmentCert, affiliation, caname)
+}
+
+// AddAffiliation adds a new affiliation. Parent affiliations are created if Force is set.
+// request: Affiliation Request
+func (c *CAClientImpl) AddAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide affiliation request")
+	}
+	if request.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.AddAffiliation(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// ModifyAffiliation renames an existing affiliation. If Force is set, the affiliations
+// of the identities with the affiliation are renamed as well.
+// request: Modify Affiliation Request
+func (c *CAClientImpl) ModifyAffiliation(request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide modify affiliation request")
+	}
+	if request.Name == "" || request.NewName == "" {
+		return nil, errors.New("Name and NewName are required")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.ModifyAffiliation(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// RemoveAffiliation removes an existing affiliation. If Force is set, its child affiliations
+// and the identities with the affiliation are removed as well. The CA server must allow affiliation removal.
+// request: Affiliation Request
+func (c *CAClientImpl) RemoveAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide remove affiliation request")
+	}
+	if request.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.RemoveAffiliation(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// GetCAInfo returns the generic information of the CA, including its certificate chain
+// caname: name of the CA (optional)
+func (c *CAClientImpl) GetCAInfo(caname string) (*api.GetCAInfoResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	return c.adapter.GetCAInfo(caname)
+}
+
+// GetCertificates returns the certificates selected by the request that the registrar is authorized to see
+// request: Get Certificates Request
+func (c *CAClientImpl) GetCertificates(request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide get certificates request")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetCertificates(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// GenCRL generates a CRL of the certificates revoked by the CA. The registrar must be allowed to generate CRLs.
+// request: Generate CRL Request
+func (c *CAClientImpl) GenCRL(request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	if request == nil {
+		return nil, errors.New("must provide generate CRL request")
+	}
+
+	registrar, err := c.identityRegistrar(request.CAName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GenCRL(registrar.PrivateKey, registrar.EnrollmentCert, request)
+}
+
+// identityRegistrar returns the signing identity of the registrar of the CA with the given name
+// that authenticates identity and affiliation management requests
+func (c *CAClientImpl) identityRegistrar(caName string) (*msp.SigningIdentity, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	return c.getRegistrar(caName)
+}
+
+// registrar returns the registrar credentials of the CA with the given name and the name under which
+// the registrar is stored in the user store. The CA is selected the way the adapter selects it: the default
+// CA is used if the name is empty or not configured. The registrars of the other CAs are stored under
+// their enrollment ID qualified by the CA name, so that the registrars of CAs sharing an enrollment ID
+// do not overwrite each other's enrollment certificate.
+func (c *CAClientImpl) registrar(caName string) (core.EnrollCredentials, string) {
+	if caName != "" && caName != c.caConfigs[0].CAName {
+		for _, caConfig := range c.caConfigs[1:] {
+			if caConfig.CAName == caName {
+				return caConfig.Registrar, caConfig.Registrar.EnrollID + "@" + caName
+			}
+		}
+	}
+	return c.caConfigs[0].Registrar, c.caConfigs[0].Registrar.EnrollID
+}
+
+func enrollmentOptions(opts []api.EnrollmentOption) (*api.EnrollmentOptions, error) {
+	options := &api.EnrollmentOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+// getRegistrar returns the signing identity of the registrar of the CA with the given name,
+// enrolling the registrar with the CA if it is not enrolled yet
+func (c *CAClientImpl) getRegistrar(caName string) (*msp.SigningIdentity, error) {
 
-	if enrollID == "" {
+	credentials, userName := c.registrar(caName)
+	if credentials.EnrollID == "" {
 		return nil, api.ErrCARegistrarNotFound
 	}
 
-	registrar, err := c.identityManager.GetSigningIdentity(enrollID)
+	registrar, err := c.identityManager.GetSigningIdentity(userName)
 	if err != nil {
 		if err != msp.ErrUserNotFound {
 			return nil, err
 		}
-		if enrollSecret == "" {
+		if credentials.EnrollSecret == "" {
 			return nil, api.ErrCARegistrarNotFound
 		}
 
 		// Attempt to enroll the registrar
-		err = c.Enroll(enrollID, enrollSecret)
+		err = c.enroll(credentials.EnrollID, credentials.EnrollSecret, userName, &api.EnrollmentOptions{CAName: caName})
 		if err != nil {
 			return nil, err
 		}
-		registrar, err = c.identityManager.GetSigningIdentity(enrollID)
+		registrar, err = c.identityManager.GetSigningIdentity(userName)
 		if err != nil {
 			return nil, err
 		}
@@ -7,6 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"testing"
 	"time"
 
@@ -230,7 +234,7 @@ func TestRevoke(t *testing.T) {
 	}
 }
 
-// TestCAConfigError will test CAClient creation with bad CAConfig
+// TestCAConfigError will test CAClient creation with bad CAConfigs
 func TestCAConfigError(t *testing.T) {
 
 	f := textFixture{}
@@ -243,18 +247,18 @@ func TestCAConfigError(t *testing.T) {
 
 	mockConfig.EXPECT().NetworkConfig().Return(f.config.NetworkConfig()).AnyTimes()
 	mockConfig.EXPECT().CryptoConfigPath().Return(f.config.CryptoConfigPath()).AnyTimes()
-	mockConfig.EXPECT().CAConfig(org1).Return(nil, errors.New("CAConfig error"))
+	mockConfig.EXPECT().CAConfigs(org1).Return(nil, errors.New("CAConfigs error"))
 	mockConfig.EXPECT().CredentialStorePath().Return(dummyUserStorePath).AnyTimes()
 
 	userStore := &mocks.MockUserStore{}
 	_, err := NewCAClient(org1, f.identityManager, userStore, f.cryptoSuite, mockConfig)
-	if err == nil || !strings.Contains(err.Error(), "CAConfig error") {
-		t.Fatalf("Expected error from CAConfig. Got: %v", err)
+	if err == nil || !strings.Contains(err.Error(), "CAConfigs error") {
+		t.Fatalf("Expected error from CAConfigs. Got: %v", err)
 	}
 }
 
-// TestCAServerCertPathsError will test CAClient creation with missing CAServerCertPaths
-func TestCAServerCertPathsError(t *testing.T) {
+// TestCATLSConfigError will test CAClient creation with a missing TLS client certificate of a CA
+func TestCATLSConfigError(t *testing.T) {
 
 	f := textFixture{}
 	f.setup("")
@@ -265,63 +269,139 @@ func TestCAServerCertPathsError(t *testing.T) {
 	mockConfig := mock_core.NewMockConfig(mockCtrl)
 	mockConfig.EXPECT().NetworkConfig().Return(f.config.NetworkConfig()).AnyTimes()
 	mockConfig.EXPECT().CryptoConfigPath().Return(f.config.CryptoConfigPath()).AnyTimes()
-	mockConfig.EXPECT().CAConfig(org1).Return(&core.CAConfig{}, nil).AnyTimes()
 	mockConfig.EXPECT().CredentialStorePath().Return(dummyUserStorePath).AnyTimes()
-	mockConfig.EXPECT().CAServerCertPaths(org1).Return(nil, errors.New("CAServerCertPaths error"))
+	mockConfig.EXPECT().CAKeyStorePath().Return(f.config.CAKeyStorePath()).AnyTimes()
+	mockConfig.EXPECT().Client().Return(f.config.Client()).AnyTimes()
+
+	caConfig := core.CAConfig{URL: "https://localhost:8090", CAName: "tlsca"}
+	caConfig.TLSCACerts.Client.Cert.Path = "testdata/missing-cert.pem"
+	caConfig.TLSCACerts.Client.Key.Path = "testdata/missing-key.pem"
+	mockConfig.EXPECT().CAConfigs(org1).Return([]core.CAConfig{caConfig}, nil)
 
 	userStore := &mocks.MockUserStore{}
 	_, err := NewCAClient(org1, f.identityManager, userStore, f.cryptoSuite, mockConfig)
-	if err == nil || !strings.Contains(err.Error(), "CAServerCertPaths error") {
-		t.Fatalf("Expected error from CAServerCertPaths. Got: %v", err)
+	if err == nil || !strings.Contains(err.Error(), "init failed") {
+		t.Fatalf("Expected error from TLS configuration. Got: %v", err)
 	}
 }
 
-// TestCAClientCertPathError will test CAClient creation with missing CAClientCertPath
-func TestCAClientCertPathError(t *testing.T) {
+// TestMultipleCAs will test selecting a CA of the organization by name and failing over between CA endpoints
+func TestMultipleCAs(t *testing.T) {
 
 	f := textFixture{}
-	f.setup("")
+	f.setup(multiCAConfigPath)
 	defer f.close()
 
-	mockCtrl := gomock.NewController(t)
-	defer mockCtrl.Finish()
-	mockConfig := mock_core.NewMockConfig(mockCtrl)
-	mockConfig.EXPECT().NetworkConfig().Return(f.config.NetworkConfig()).AnyTimes()
-	mockConfig.EXPECT().CryptoConfigPath().Return(f.config.CryptoConfigPath()).AnyTimes()
-	mockConfig.EXPECT().CAConfig(org1).Return(&core.CAConfig{}, nil).AnyTimes()
-	mockConfig.EXPECT().CredentialStorePath().Return(dummyUserStorePath).AnyTimes()
-	mockConfig.EXPECT().CAServerCertPaths(org1).Return([]string{"test"}, nil)
-	mockConfig.EXPECT().CAClientCertPath(org1).Return("", errors.New("CAClientCertPath error"))
+	// The first endpoint of the default CA is unreachable
+	enrollUserName := createRandomName()
+	if err := f.caClient.Enroll(enrollUserName, "enrollmentSecret"); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	if caName := caServer.LastEnrollmentRequest().CAName; caName != "ca.org1.example.com" {
+		t.Fatalf("Expected enrollment with the default CA, got %s", caName)
+	}
+	if err := f.caClient.Enroll(enrollUserName, "enrollmentSecret", func(o *api.EnrollmentOptions) error {
+		o.CAName = "ica.org1.example.com"
+		return nil
+	}); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	if caName := caServer.LastEnrollmentRequest().CAName; caName != "ica.org1.example.com" {
+		t.Fatalf("Expected enrollment with the intermediate CA, got %s", caName)
+	}
+	checkIssuingCA(t, &f, enrollUserName, "ica.org1.example.com")
 
-	userStore := &mocks.MockUserStore{}
-	_, err := NewCAClient(org1, f.identityManager, userStore, f.cryptoSuite, mockConfig)
-	if err == nil || !strings.Contains(err.Error(), "CAClientCertPath error") {
-		t.Fatalf("Expected error from CAClientCertPath. Got: %v", err)
+	// Users are re-enrolled with the CA that issued their enrollment certificate by default
+	if err := f.caClient.Reenroll(enrollUserName); err != nil {
+		t.Fatalf("Reenroll return error %v", err)
+	}
+	if caName := caServer.LastEnrollmentRequest().CAName; caName != "ica.org1.example.com" {
+		t.Fatalf("Expected re-enrollment with the intermediate CA, got %s", caName)
+	}
+	if err := f.caClient.Reenroll(enrollUserName, api.WithCAName("ca.org1.example.com")); err != nil {
+		t.Fatalf("Reenroll return error %v", err)
+	}
+	if caName := caServer.LastEnrollmentRequest().CAName; caName != "ca.org1.example.com" {
+		t.Fatalf("Expected re-enrollment with the default CA, got %s", caName)
+	}
+	checkIssuingCA(t, &f, enrollUserName, "ca.org1.example.com")
+
+	for caName, expected := range map[string]string{
+		"":                     "ca.org1.example.com",
+		"ica.org1.example.com": "ica.org1.example.com",
+		// Names of CAs that are not configured are sent to the default CA
+		"other": "other",
+	} {
+		resp, err := f.caClient.GetAllAffiliations(caName)
+		if err != nil {
+			t.Fatalf("GetAllAffiliations return error %v", err)
+		}
+		if resp.CAName != expected {
+			t.Fatalf("Expected CA %s for CA name '%s', got %s", expected, caName, resp.CAName)
+		}
 	}
 }
 
-// TestCAClientKeyPathError will test CAClient creation with missing CAClientKeyPath
-func TestCAClientKeyPathError(t *testing.T) {
+// TestMultipleCARegistrars will test that requests to a CA are authenticated by the registrar of the CA
+func TestMultipleCARegistrars(t *testing.T) {
 
 	f := textFixture{}
-	f.setup("")
+	f.setup(multiCAConfigPath)
 	defer f.close()
 
-	mockCtrl := gomock.NewController(t)
-	defer mockCtrl.Finish()
-	mockConfig := mock_core.NewMockConfig(mockCtrl)
-	mockConfig.EXPECT().NetworkConfig().Return(f.config.NetworkConfig()).AnyTimes()
-	mockConfig.EXPECT().CryptoConfigPath().Return(f.config.CryptoConfigPath()).AnyTimes()
-	mockConfig.EXPECT().CAConfig(org1).Return(&core.CAConfig{}, nil).AnyTimes()
-	mockConfig.EXPECT().CredentialStorePath().Return(dummyUserStorePath).AnyTimes()
-	mockConfig.EXPECT().CAServerCertPaths(org1).Return([]string{"test"}, nil)
-	mockConfig.EXPECT().CAClientCertPath(org1).Return("", nil)
-	mockConfig.EXPECT().CAClientKeyPath(org1).Return("", errors.New("CAClientKeyPath error"))
+	orgMspID := mspIDByOrgName(t, f.config, org1)
 
-	userStore := &mocks.MockUserStore{}
-	_, err := NewCAClient(org1, f.identityManager, userStore, f.cryptoSuite, mockConfig)
-	if err == nil || !strings.Contains(err.Error(), "CAClientKeyPath error") {
-		t.Fatalf("Expected error from CAClientKeyPath. Got: %v", err)
+	for _, tc := range []struct {
+		caName     string
+		registrar  string
+		enrolledBy string
+	}{
+		{caName: "ica.org1.example.com", registrar: "org1IntermediateAdmin@ica.org1.example.com", enrolledBy: "ica.org1.example.com"},
+		{caName: "", registrar: "org1Admin", enrolledBy: "ca.org1.example.com"},
+	} {
+		if _, err := f.caClient.GetAllIdentities(tc.caName); err != nil {
+			t.Fatalf("GetAllIdentities return error %v", err)
+		}
+		if _, err := f.userStore.Load(msp.UserIdentifier{MspID: orgMspID, Name: tc.registrar}); err != nil {
+			t.Fatalf("Expected registrar %s of CA '%s' to be enrolled, got %v", tc.registrar, tc.caName, err)
+		}
+		if caName := caServer.LastEnrollmentRequest().CAName; caName != tc.enrolledBy {
+			t.Fatalf("Expected enrollment of registrar %s with CA %s, got %s", tc.registrar, tc.enrolledBy, caName)
+		}
+	}
+}
+
+// TestMultipleCARegistrarsSameEnrollID will test that the registrars of CAs sharing an enrollment ID
+// are stored separately
+func TestMultipleCARegistrarsSameEnrollID(t *testing.T) {
+
+	f := textFixture{}
+	f.setup(multiCAConfigPath)
+	defer f.close()
+
+	caClient := f.caClient.(*CAClientImpl)
+	for i, caConfig := range caClient.caConfigs {
+		if caConfig.CAName == "ica.org1.example.com" {
+			caClient.caConfigs[i].Registrar = caClient.caConfigs[0].Registrar
+		}
+	}
+	for _, caName := range []string{"ica.org1.example.com", ""} {
+		if _, err := f.caClient.GetAllIdentities(caName); err != nil {
+			t.Fatalf("GetAllIdentities return error %v", err)
+		}
+	}
+	checkIssuingCA(t, &f, "org1Admin", "ca.org1.example.com")
+	checkIssuingCA(t, &f, "org1Admin@ica.org1.example.com", "ica.org1.example.com")
+}
+
+// checkIssuingCA checks the name of the CA recorded in the metadata of the stored user
+func checkIssuingCA(t *testing.T, f *textFixture, userName string, expected string) {
+	userData, err := f.userStore.Load(msp.UserIdentifier{MspID: mspIDByOrgName(t, f.config, org1), Name: userName})
+	if err != nil {
+		t.Fatalf("Expected to load user %s from user store, got %v", userName, err)
+	}
+	if caName := userData.Metadata[api.CANameMetadataKey]; caName != expected {
+		t.Fatalf("Expected CA %s in the metadata of user %s, got %s", expected, userName, caName)
 	}
 }
 
@@ -335,3 +415,262 @@ func TestInterfaces(t *testing.T) {
 		t.Fatalf("this shouldn't happen.")
 	}
 }
+
+// TestIdentityManagement tests creating, retrieving, modifying and removing identities
+func TestIdentityManagement(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Invalid requests
+	if _, err := f.caClient.CreateIdentity(nil); err == nil {
+		t.Fatalf("Expected error with nil request")
+	}
+	if _, err := f.caClient.CreateIdentity(&api.IdentityRequest{}); err == nil {
+		t.Fatalf("Expected error without ID")
+	}
+	if _, err := f.caClient.GetIdentity("", ""); err == nil {
+		t.Fatalf("Expected error without ID")
+	}
+	if _, err := f.caClient.RemoveIdentity(&api.RemoveIdentityRequest{}); err == nil {
+		t.Fatalf("Expected error without ID")
+	}
+
+	id := createRandomName()
+	resp, err := f.caClient.CreateIdentity(&api.IdentityRequest{
+		ID:             id,
+		Affiliation:    "org1",
+		Attributes:     []api.Attribute{{Name: "app.role", Value: "admin", ECert: true}},
+		MaxEnrollments: 2,
+	})
+	if err != nil {
+		t.Fatalf("CreateIdentity return error %v", err)
+	}
+	if resp.ID != id || resp.Type != "user" || resp.Secret != "mockSecretValue" {
+		t.Fatalf("Unexpected create identity response: %+v", resp)
+	}
+
+	if _, err = f.caClient.CreateIdentity(&api.IdentityRequest{ID: id}); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("Expected error for duplicate identity, got %v", err)
+	}
+
+	resp, err = f.caClient.GetIdentity(id, "ca.org1.example.com")
+	if err != nil {
+		t.Fatalf("GetIdentity return error %v", err)
+	}
+	if resp.Affiliation != "org1" || resp.MaxEnrollments != 2 || resp.CAName != "ca.org1.example.com" {
+		t.Fatalf("Unexpected identity: %+v", resp)
+	}
+	if len(resp.Attributes) != 1 || resp.Attributes[0].Name != "app.role" || resp.Attributes[0].Value != "admin" || !resp.Attributes[0].ECert {
+		t.Fatalf("Unexpected identity attributes: %+v", resp.Attributes)
+	}
+
+	resp, err = f.caClient.ModifyIdentity(&api.IdentityRequest{ID: id, Affiliation: "org1.department1", Secret: "newSecret"})
+	if err != nil {
+		t.Fatalf("ModifyIdentity return error %v", err)
+	}
+	if resp.Affiliation != "org1.department1" || resp.Secret != "newSecret" {
+		t.Fatalf("Unexpected modify identity response: %+v", resp)
+	}
+
+	identities, err := f.caClient.GetAllIdentities("")
+	if err != nil {
+		t.Fatalf("GetAllIdentities return error %v", err)
+	}
+	found := false
+	for _, identity := range identities {
+		if identity.ID == id {
+			found = identity.Affiliation == "org1.department1"
+		}
+	}
+	if !found {
+		t.Fatalf("Expected modified identity in %+v", identities)
+	}
+
+	resp, err = f.caClient.RemoveIdentity(&api.RemoveIdentityRequest{ID: id, Force: true})
+	if err != nil {
+		t.Fatalf("RemoveIdentity return error %v", err)
+	}
+	if resp.ID != id {
+		t.Fatalf("Unexpected remove identity response: %+v", resp)
+	}
+
+	if _, err = f.caClient.GetIdentity(id, ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Expected error for removed identity, got %v", err)
+	}
+}
+
+// TestIdentityManagementNoRegistrar tests identity management with no configured registrar identity
+func TestIdentityManagementNoRegistrar(t *testing.T) {
+
+	f := textFixture{}
+	f.setup(noRegistrarConfigPath)
+	defer f.close()
+
+	if _, err := f.caClient.GetAllIdentities(""); err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
+	}
+	if _, err := f.caClient.CreateIdentity(&api.IdentityRequest{ID: "test"}); err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
+	}
+}
+
+// TestAffiliationManagement tests adding, retrieving, renaming and removing affiliations
+func TestAffiliationManagement(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Invalid requests
+	if _, err := f.caClient.AddAffiliation(nil); err == nil {
+		t.Fatalf("Expected error with nil request")
+	}
+	if _, err := f.caClient.ModifyAffiliation(&api.ModifyAffiliationRequest{AffiliationRequest: api.AffiliationRequest{Name: "org1"}}); err == nil {
+		t.Fatalf("Expected error without new name")
+	}
+	if _, err := f.caClient.GetAffiliation("", ""); err == nil {
+		t.Fatalf("Expected error without affiliation")
+	}
+
+	dept := "org1." + createRandomName()
+
+	// Parent affiliations are only created with force
+	if _, err := f.caClient.AddAffiliation(&api.AffiliationRequest{Name: dept + ".team1"}); err == nil {
+		t.Fatalf("Expected error for missing parent affiliation")
+	}
+	resp, err := f.caClient.AddAffiliation(&api.AffiliationRequest{Name: dept + ".team1", Force: true})
+	if err != nil {
+		t.Fatalf("AddAffiliation return error %v", err)
+	}
+	if resp.Name != dept+".team1" {
+		t.Fatalf("Unexpected add affiliation response: %+v", resp)
+	}
+
+	resp, err = f.caClient.GetAllAffiliations("")
+	if err != nil {
+		t.Fatalf("GetAllAffiliations return error %v", err)
+	}
+	org1 := findAffiliation(resp.Affiliations, "org1")
+	if org1 == nil || findAffiliation(org1.Affiliations, dept) == nil {
+		t.Fatalf("Expected affiliation %s in tree %+v", dept, resp.AffiliationInfo)
+	}
+
+	resp, err = f.caClient.ModifyAffiliation(&api.ModifyAffiliationRequest{
+		AffiliationRequest: api.AffiliationRequest{Name: dept},
+		NewName:            dept + "renamed",
+	})
+	if err != nil {
+		t.Fatalf("ModifyAffiliation return error %v", err)
+	}
+	if resp.Name != dept+"renamed" || len(resp.Affiliations) != 1 || resp.Affiliations[0].Name != dept+"renamed.team1" {
+		t.Fatalf("Unexpected modify affiliation response: %+v", resp)
+	}
+	dept = dept + "renamed"
+
+	// Affiliations with child affiliations are only removed with force
+	if _, err = f.caClient.RemoveAffiliation(&api.AffiliationRequest{Name: dept}); err == nil {
+		t.Fatalf("Expected error removing affiliation with child affiliations")
+	}
+	if _, err = f.caClient.RemoveAffiliation(&api.AffiliationRequest{Name: dept, Force: true}); err != nil {
+		t.Fatalf("RemoveAffiliation return error %v", err)
+	}
+	if _, err = f.caClient.GetAffiliation(dept+".team1", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Expected error for removed affiliation, got %v", err)
+	}
+}
+
+func findAffiliation(affiliations []api.AffiliationInfo, name string) *api.AffiliationInfo {
+	for i := range affiliations {
+		if affiliations[i].Name == name {
+			return &affiliations[i]
+		}
+	}
+	return nil
+}
+
+// TestGetCAInfo tests retrieving the generic CA information
+func TestGetCAInfo(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	resp, err := f.caClient.GetCAInfo("")
+	if err != nil {
+		t.Fatalf("GetCAInfo return error %v", err)
+	}
+	if resp.CAName == "" || resp.Version == "" {
+		t.Fatalf("Unexpected CA info: %+v", resp)
+	}
+	block, _ := pem.Decode(resp.CAChain)
+	if block == nil {
+		t.Fatalf("Expected PEM-encoded CA chain")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		t.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+}
+
+// TestGetCertificatesAndGenCRL tests listing certificates and generating a CRL
+func TestGetCertificatesAndGenCRL(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	if _, err := f.caClient.GetCertificates(nil); err == nil {
+		t.Fatalf("Expected error with nil request")
+	}
+	if _, err := f.caClient.GenCRL(nil); err == nil {
+		t.Fatalf("Expected error with nil request")
+	}
+
+	enrollUserName := createRandomName()
+	if err := f.caClient.Enroll(enrollUserName, "enrollmentSecret"); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	user, err := f.identityManager.GetUser(enrollUserName)
+	if err != nil {
+		t.Fatalf("GetUser return error %v", err)
+	}
+	block, _ := pem.Decode(user.EnrollmentCertificate())
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse enrollment certificate: %v", err)
+	}
+
+	resp, err := f.caClient.GetCertificates(&api.GetCertificatesRequest{ID: enrollUserName, NotExpired: true})
+	if err != nil {
+		t.Fatalf("GetCertificates return error %v", err)
+	}
+	if len(resp.Certs) != 1 || !bytes.Equal(resp.Certs[0], user.EnrollmentCertificate()) {
+		t.Fatalf("Expected enrollment certificate of %s, got %+v", enrollUserName, resp)
+	}
+
+	resp, err = f.caClient.GetCertificates(&api.GetCertificatesRequest{
+		Serial: hex.EncodeToString(cert.SerialNumber.Bytes()),
+		AKI:    hex.EncodeToString(cert.AuthorityKeyId),
+	})
+	if err != nil || len(resp.Certs) != 1 {
+		t.Fatalf("Expected certificate by serial and AKI, got %+v, %v", resp, err)
+	}
+
+	resp, err = f.caClient.GetCertificates(&api.GetCertificatesRequest{ExpiredStart: cert.NotAfter.Add(time.Hour)})
+	if err != nil || len(resp.Certs) != 0 {
+		t.Fatalf("Expected no certificates expiring after %s, got %+v, %v", cert.NotAfter, resp, err)
+	}
+
+	crlResp, err := f.caClient.GenCRL(&api.GenCRLRequest{RevokedAfter: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("GenCRL return error %v", err)
+	}
+	crl, err := x509.ParseCRL(crlResp.CRL)
+	if err != nil {
+		t.Fatalf("Failed to parse CRL: %v", err)
+	}
+	if len(crl.TBSCertList.RevokedCertificates) != 0 {
+		t.Fatalf("Expected empty CRL")
+	}
+}
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
@@ -16,7 +19,8 @@ import (
 
 // CertFileUserStore stores each user in a separate file.
 // Only user's enrollment cert is stored, in pem format.
-// File naming is <user>@<org>-cert.pem
+// File naming is <user>@<org>-cert.pem. The metadata of the user,
+// if any, is stored in JSON format in <user>@<org>-metadata.json
 type CertFileUserStore struct {
 	store core.KVStore
 }
@@ -28,8 +32,35 @@ func userIdentifierFromUser(user msp.UserData) msp.UserIdentifier {
 	}
 }
 
+const (
+	certFileSuffix     = "-cert.pem"
+	metadataFileSuffix = "-metadata.json"
+)
+
 func storeKeyFromUserIdentifier(key msp.UserIdentifier) string {
-	return key.Name + "@" + key.MspID + "-cert.pem"
+	return key.Name + "@" + key.MspID + certFileSuffix
+}
+
+func metadataStoreKeyFromUserIdentifier(key msp.UserIdentifier) string {
+	return key.Name + "@" + key.MspID + metadataFileSuffix
+}
+
+// userIdentifierFromStoreKey parses a store key; user names may contain '@' but MSP IDs may not
+func userIdentifierFromStoreKey(key string) (msp.UserIdentifier, bool) {
+	if !strings.HasSuffix(key, certFileSuffix) {
+		return msp.UserIdentifier{}, false
+	}
+	key = strings.TrimSuffix(key, certFileSuffix)
+	i := strings.LastIndex(key, "@")
+	if i <= 0 || i == len(key)-1 {
+		return msp.UserIdentifier{}, false
+	}
+	return msp.UserIdentifier{Name: key[:i], MspID: key[i+1:]}, true
+}
+
+// keyLister is implemented by key-value stores that can enumerate their keys
+type keyLister interface {
+	Keys() ([]string, error)
 }
 
 // NewCertFileUserStore1 creates a new instance of CertFileUserStore
@@ -66,21 +97,84 @@ func (s *CertFileUserStore) Load(key msp.UserIdentifier) (*msp.UserData, error)
 	if !ok {
 		return nil, errors.New("user is not of proper type")
 	}
+	metadata, err := s.loadMetadata(key)
+	if err != nil {
+		return nil, err
+	}
 	userData := &msp.UserData{
 		MspID: key.MspID,
 		Name:  key.Name,
 		EnrollmentCertificate: certBytes,
+		Metadata:              metadata,
 	}
 	return userData, nil
 }
 
+func (s *CertFileUserStore) loadMetadata(key msp.UserIdentifier) (map[string]string, error) {
+	value, err := s.store.Load(metadataStoreKeyFromUserIdentifier(key))
+	if err != nil {
+		if err == core.ErrKeyValueNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, errors.New("user metadata is not of proper type")
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(valueBytes, &metadata); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling user metadata failed")
+	}
+	return metadata, nil
+}
+
 // Store stores a User into store
 func (s *CertFileUserStore) Store(user *msp.UserData) error {
-	key := storeKeyFromUserIdentifier(msp.UserIdentifier{MspID: user.MspID, Name: user.Name})
-	return s.store.Store(key, user.EnrollmentCertificate)
+	id := msp.UserIdentifier{MspID: user.MspID, Name: user.Name}
+	if err := s.store.Store(storeKeyFromUserIdentifier(id), user.EnrollmentCertificate); err != nil {
+		return err
+	}
+	if len(user.Metadata) == 0 {
+		return s.store.Delete(metadataStoreKeyFromUserIdentifier(id))
+	}
+	metadata, err := json.Marshal(user.Metadata)
+	if err != nil {
+		return errors.Wrap(err, "marshalling user metadata failed")
+	}
+	return s.store.Store(metadataStoreKeyFromUserIdentifier(id), metadata)
+}
+
+// List returns all users in the store. The underlying key-value store must be able to enumerate its keys.
+func (s *CertFileUserStore) List() ([]*msp.UserData, error) {
+	lister, ok := s.store.(keyLister)
+	if !ok {
+		return nil, errors.New("listing users is not supported by the key-value store")
+	}
+	keys, err := lister.Keys()
+	if err != nil {
+		return nil, errors.WithMessage(err, "listing users failed")
+	}
+
+	var users []*msp.UserData
+	for _, key := range keys {
+		id, ok := userIdentifierFromStoreKey(key)
+		if !ok {
+			continue
+		}
+		user, err := s.Load(id)
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading user failed")
+		}
+		users = append(users, user)
+	}
+	return users, nil
 }
 
 // Delete deletes a User from store
 func (s *CertFileUserStore) Delete(key msp.UserIdentifier) error {
-	return s.store.Delete(storeKeyFromUserIdentifier(key))
+	if err := s.store.Delete(storeKeyFromUserIdentifier(key)); err != nil {
+		return err
+	}
+	return s.store.Delete(metadataStoreKeyFromUserIdentifier(key))
 }
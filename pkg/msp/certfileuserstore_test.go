@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 	"testing"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
@@ -71,6 +72,7 @@ func TestStore(t *testing.T) {
 		MspID: "Org2",
 		Name:  "user2",
 		EnrollmentCertificate: []byte(testCert2),
+		Metadata:              map[string]string{"origin": "test"},
 	}
 
 	if err := store.Store(user1); err != nil {
@@ -80,6 +82,24 @@ func TestStore(t *testing.T) {
 		t.Fatalf("Store %s failed [%s]", user2.Name, err)
 	}
 
+	users, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed [%s]", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 users, got %d", len(users))
+	}
+	for _, user := range users {
+		expected := user1
+		if user.Name == user2.Name {
+			expected = user2
+		}
+		if user.MspID != expected.MspID || user.Name != expected.Name || !bytes.Equal(user.EnrollmentCertificate, expected.EnrollmentCertificate) ||
+			!reflect.DeepEqual(user.Metadata, expected.Metadata) {
+			t.Fatalf("Unexpected user listed: %s@%s", user.Name, user.MspID)
+		}
+	}
+
 	// Check key1, value1
 	if err := checkStoreValue(store, user1, user1.EnrollmentCertificate); err != nil {
 		t.Fatalf("checkStoreValue %s failed [%s]", user1.Name, err)
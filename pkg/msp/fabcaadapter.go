@@ -7,11 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	cfsslcsr "github.com/cloudflare/cfssl/csr"
 	"github.com/pkg/errors"
 
 	caapi "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
 	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 )
@@ -20,36 +27,91 @@ import (
 type fabricCAAdapter struct {
 	config      core.Config
 	cryptoSuite core.CryptoSuite
-	caClient    *calib.Client
+	// cas are the CAs of the organization, in the order they are configured; the first CA is the default CA
+	cas []*caEndpoints
 }
 
-func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config core.Config) (*fabricCAAdapter, error) {
-
-	caClient, err := createFabricCAClient(orgName, cryptoSuite, config)
-	if err != nil {
-		return nil, err
+func newFabricCAAdapter(caConfigs []core.CAConfig, cryptoSuite core.CryptoSuite, config core.Config) (*fabricCAAdapter, error) {
+	if len(caConfigs) == 0 {
+		return nil, errors.New("no CAs configured")
 	}
 
 	a := &fabricCAAdapter{
 		config:      config,
 		cryptoSuite: cryptoSuite,
-		caClient:    caClient,
+	}
+	for i := range caConfigs {
+		caClient, err := createFabricCAClient(&caConfigs[i], cryptoSuite, config)
+		if err != nil {
+			return nil, err
+		}
+		a.addCAClient(caClient)
 	}
 	return a, nil
 }
 
+// addCAClient adds the client to the endpoints of its CA. Endpoints serve the same CA if they have the same CA name.
+func (c *fabricCAAdapter) addCAClient(caClient *calib.Client) {
+	for _, ca := range c.cas {
+		if ca.caName == caClient.Config.CAName {
+			ca.clients = append(ca.clients, caClient)
+			return
+		}
+	}
+	c.cas = append(c.cas, &caEndpoints{caName: caClient.Config.CAName, clients: []*calib.Client{caClient}})
+}
+
+// ca returns the endpoints of the CA with the given name and the CA name to send in requests.
+// The default CA is returned if the name is empty, or if no CA with the name is configured,
+// in which case the name is sent to the default CA's server, which may host several CAs.
+func (c *fabricCAAdapter) ca(caName string) (*caEndpoints, string) {
+	if caName == "" {
+		return c.cas[0], c.cas[0].caName
+	}
+	for _, ca := range c.cas {
+		if ca.caName == caName {
+			return ca, caName
+		}
+	}
+	return c.cas[0], caName
+}
+
+// withRegistrar calls f with the registrar identity at the endpoints of the CA with the given name,
+// failing over to the next endpoint on connection errors
+func (c *fabricCAAdapter) withRegistrar(caName string, key core.Key, cert []byte, f func(registrar *calib.Identity, caName string) error) error {
+	ca, caName := c.ca(caName)
+	return ca.do(func(caClient *calib.Client) error {
+		registrar, err := caClient.NewIdentity(key, cert)
+		if err != nil {
+			return errors.Wrap(err, "failed to create CA signing identity")
+		}
+		return f(registrar, caName)
+	})
+}
+
 // Enroll handles enrollment.
-func (c *fabricCAAdapter) Enroll(enrollmentID string, enrollmentSecret string) ([]byte, error) {
+func (c *fabricCAAdapter) Enroll(enrollmentID string, enrollmentSecret string, opts *api.EnrollmentOptions) ([]byte, error) {
 
 	logger.Debugf("Enrolling user [%s]", enrollmentID)
 
-	// TODO add attributes
+	csr, err := newCSRInfo(enrollmentID, opts.CSR)
+	if err != nil {
+		return nil, err
+	}
+	ca, caName := c.ca(opts.CAName)
 	careq := &caapi.EnrollmentRequest{
-		CAName: c.caClient.Config.CAName,
-		Name:   enrollmentID,
-		Secret: enrollmentSecret,
+		CAName:   caName,
+		Name:     enrollmentID,
+		Secret:   enrollmentSecret,
+		Profile:  opts.Profile,
+		CSR:      csr,
+		AttrReqs: attributeRequests(opts.AttrReqs),
 	}
-	caresp, err := c.caClient.Enroll(careq)
+	var caresp *calib.EnrollmentResponse
+	err = ca.do(func(caClient *calib.Client) error {
+		caresp, err = caClient.Enroll(careq)
+		return err
+	})
 	if err != nil {
 		return nil, errors.WithMessage(err, "enroll failed")
 	}
@@ -57,21 +119,27 @@ func (c *fabricCAAdapter) Enroll(enrollmentID string, enrollmentSecret string) (
 }
 
 // Reenroll handles re-enrollment
-func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte) ([]byte, error) {
-
-	logger.Debugf("Enrolling user [%s]")
-
-	careq := &caapi.ReenrollmentRequest{
-		CAName: c.caClient.Config.CAName,
-	}
-	caidentity, err := c.caClient.NewIdentity(key, cert)
+func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte, opts *api.EnrollmentOptions) ([]byte, error) {
+
+	var caresp *calib.EnrollmentResponse
+	err := c.withRegistrar(opts.CAName, key, cert, func(caidentity *calib.Identity, caName string) error {
+		logger.Debugf("Reenrolling user [%s]", caidentity.GetName())
+
+		csr, err := newCSRInfo(caidentity.GetName(), opts.CSR)
+		if err != nil {
+			return err
+		}
+		careq := &caapi.ReenrollmentRequest{
+			CAName:   caName,
+			Profile:  opts.Profile,
+			CSR:      csr,
+			AttrReqs: attributeRequests(opts.AttrReqs),
+		}
+		caresp, err = caidentity.Reenroll(careq)
+		return errors.WithMessage(err, "reenroll failed")
+	})
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to create CA signing identity")
-	}
-
-	caresp, err := caidentity.Reenroll(careq)
-	if err != nil {
-		return nil, errors.WithMessage(err, "reenroll failed")
+		return nil, err
 	}
 
 	return caresp.Identity.GetECert().Cert(), nil
@@ -87,10 +155,9 @@ func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.Regis
 	var attributes []caapi.Attribute
 	for i := range request.Attributes {
 		attributes = append(attributes, caapi.Attribute{Name: request.
-			Attributes[i].Key, Value: request.Attributes[i].Value})
+			Attributes[i].Key, Value: request.Attributes[i].Value, ECert: request.Attributes[i].ECert})
 	}
 	var req = caapi.RegistrationRequest{
-		CAName:         request.CAName,
 		Name:           request.Name,
 		Type:           request.Type,
 		MaxEnrollments: request.MaxEnrollments,
@@ -98,14 +165,15 @@ func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.Regis
 		Secret:         request.Secret,
 		Attributes:     attributes}
 
-	registrar, err := c.caClient.NewIdentity(key, cert)
+	var response *caapi.RegistrationResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		response, err = registrar.Register(&req)
+		return errors.Wrap(err, "failed to register user")
+	})
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	response, err := registrar.Register(&req)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to register user")
+		return "", err
 	}
 
 	return response.Secret, nil
@@ -118,21 +186,21 @@ func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.Regis
 func (c *fabricCAAdapter) Revoke(key core.Key, cert []byte, request *api.RevocationRequest) (*api.RevocationResponse, error) {
 	// Create revocation request
 	var req = caapi.RevocationRequest{
-		CAName: request.CAName,
 		Name:   request.Name,
 		Serial: request.Serial,
 		AKI:    request.AKI,
 		Reason: request.Reason,
 	}
 
-	registrar, err := c.caClient.NewIdentity(key, cert)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	resp, err := registrar.Revoke(&req)
+	var resp *caapi.RevocationResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.Revoke(&req)
+		return errors.Wrap(err, "failed to revoke")
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to revoke")
+		return nil, err
 	}
 	var revokedCerts []api.RevokedCert
 	for i := range resp.RevokedCerts {
@@ -150,49 +218,502 @@ func (c *fabricCAAdapter) Revoke(key core.Key, cert []byte, request *api.Revocat
 	}, nil
 }
 
-func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config core.Config) (*calib.Client, error) {
+// GetAllIdentities returns all identities that the registrar is authorized to see
+// key: registrar private key
+// cert: registrar enrollment certificate
+// caname: name of the CA
+func (c *fabricCAAdapter) GetAllIdentities(key core.Key, cert []byte, caname string) ([]*api.IdentityResponse, error) {
+	var resp *caapi.GetAllIDsResponse
+	err := c.withRegistrar(caname, key, cert, func(registrar *calib.Identity, caName string) error {
+		var err error
+		resp, err = registrar.GetAllIdentities(caName)
+		return errors.WithMessage(err, "failed to get identities")
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Create new Fabric-ca client without configs
-	c := &calib.Client{
-		Config: &calib.ClientConfig{},
+	var identities []*api.IdentityResponse
+	for _, id := range resp.Identities {
+		identities = append(identities, &api.IdentityResponse{
+			ID:             id.ID,
+			Affiliation:    id.Affiliation,
+			Type:           id.Type,
+			Attributes:     attributes(id.Attributes),
+			MaxEnrollments: id.MaxEnrollments,
+			CAName:         resp.CAName,
+		})
+	}
+	return identities, nil
+}
+
+// GetIdentity returns the identity with the given ID
+// key: registrar private key
+// cert: registrar enrollment certificate
+// id: enrollment ID of the identity
+// caname: name of the CA
+func (c *fabricCAAdapter) GetIdentity(key core.Key, cert []byte, id, caname string) (*api.IdentityResponse, error) {
+	var resp *caapi.GetIDResponse
+	err := c.withRegistrar(caname, key, cert, func(registrar *calib.Identity, caName string) error {
+		var err error
+		resp, err = registrar.GetIdentity(id, caName)
+		return errors.WithMessage(err, "failed to get identity")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.IdentityResponse{
+		ID:             resp.ID,
+		Affiliation:    resp.Affiliation,
+		Type:           resp.Type,
+		Attributes:     attributes(resp.Attributes),
+		MaxEnrollments: resp.MaxEnrollments,
+		CAName:         resp.CAName,
+	}, nil
+}
+
+// CreateIdentity adds a new identity
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Identity Request
+func (c *fabricCAAdapter) CreateIdentity(key core.Key, cert []byte, request *api.IdentityRequest) (*api.IdentityResponse, error) {
+	req := caapi.AddIdentityRequest{
+		ID:             request.ID,
+		Type:           request.Type,
+		Affiliation:    request.Affiliation,
+		Attributes:     caAttributes(request.Attributes),
+		MaxEnrollments: request.MaxEnrollments,
+		Secret:         request.Secret,
 	}
 
-	conf, err := config.CAConfig(org)
+	var resp *caapi.IdentityResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.AddIdentity(&req)
+		return errors.WithMessage(err, "failed to create identity")
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if conf == nil {
-		return nil, errors.Errorf("Orgnization %s have no corresponding CA in the configs", org)
+	return identityResponse(resp), nil
+}
+
+// ModifyIdentity modifies an existing identity
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Identity Request
+func (c *fabricCAAdapter) ModifyIdentity(key core.Key, cert []byte, request *api.IdentityRequest) (*api.IdentityResponse, error) {
+	req := caapi.ModifyIdentityRequest{
+		ID:             request.ID,
+		Type:           request.Type,
+		Affiliation:    request.Affiliation,
+		Attributes:     caAttributes(request.Attributes),
+		MaxEnrollments: request.MaxEnrollments,
+		Secret:         request.Secret,
 	}
 
-	//set server CAName
-	c.Config.CAName = conf.CAName
-	//set server URL
-	c.Config.URL = endpoint.ToAddress(conf.URL)
-	//certs file list
-	c.Config.TLS.CertFiles, err = config.CAServerCertPaths(org)
+	var resp *caapi.IdentityResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.ModifyIdentity(&req)
+		return errors.WithMessage(err, "failed to modify identity")
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// set key file and cert file
-	c.Config.TLS.Client.CertFile, err = config.CAClientCertPath(org)
+	return identityResponse(resp), nil
+}
+
+// RemoveIdentity removes an existing identity
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Remove Identity Request
+func (c *fabricCAAdapter) RemoveIdentity(key core.Key, cert []byte, request *api.RemoveIdentityRequest) (*api.IdentityResponse, error) {
+	req := caapi.RemoveIdentityRequest{
+		ID:    request.ID,
+		Force: request.Force,
+	}
+
+	var resp *caapi.IdentityResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.RemoveIdentity(&req)
+		return errors.WithMessage(err, "failed to remove identity")
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	c.Config.TLS.Client.KeyFile, err = config.CAClientKeyPath(org)
+	return identityResponse(resp), nil
+}
+
+// GetAllAffiliations returns all affiliations that the registrar is authorized to see
+// key: registrar private key
+// cert: registrar enrollment certificate
+// caname: name of the CA
+func (c *fabricCAAdapter) GetAllAffiliations(key core.Key, cert []byte, caname string) (*api.AffiliationResponse, error) {
+	var resp *caapi.AffiliationResponse
+	err := c.withRegistrar(caname, key, cert, func(registrar *calib.Identity, caName string) error {
+		var err error
+		resp, err = registrar.GetAllAffiliations(caName)
+		return errors.WithMessage(err, "failed to get affiliations")
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	return affiliationResponse(resp), nil
+}
+
+// GetAffiliation returns the affiliation with the given name
+// key: registrar private key
+// cert: registrar enrollment certificate
+// affiliation: name of the affiliation
+// caname: name of the CA
+func (c *fabricCAAdapter) GetAffiliation(key core.Key, cert []byte, affiliation, caname string) (*api.AffiliationResponse, error) {
+	var resp *caapi.AffiliationResponse
+	err := c.withRegistrar(caname, key, cert, func(registrar *calib.Identity, caName string) error {
+		var err error
+		resp, err = registrar.GetAffiliation(affiliation, caName)
+		return errors.WithMessage(err, "failed to get affiliation")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return affiliationResponse(resp), nil
+}
+
+// AddAffiliation adds a new affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Affiliation Request
+func (c *fabricCAAdapter) AddAffiliation(key core.Key, cert []byte, request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	req := caapi.AddAffiliationRequest{
+		Name:  request.Name,
+		Force: request.Force,
+	}
+
+	var resp *caapi.AffiliationResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.AddAffiliation(&req)
+		return errors.WithMessage(err, "failed to add affiliation")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return affiliationResponse(resp), nil
+}
+
+// ModifyAffiliation renames an existing affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Modify Affiliation Request
+func (c *fabricCAAdapter) ModifyAffiliation(key core.Key, cert []byte, request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	req := caapi.ModifyAffiliationRequest{
+		Name:    request.Name,
+		NewName: request.NewName,
+		Force:   request.Force,
+	}
+
+	var resp *caapi.AffiliationResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.ModifyAffiliation(&req)
+		return errors.WithMessage(err, "failed to modify affiliation")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return affiliationResponse(resp), nil
+}
+
+// RemoveAffiliation removes an existing affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Affiliation Request
+func (c *fabricCAAdapter) RemoveAffiliation(key core.Key, cert []byte, request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	req := caapi.RemoveAffiliationRequest{
+		Name:  request.Name,
+		Force: request.Force,
+	}
+
+	var resp *caapi.AffiliationResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.RemoveAffiliation(&req)
+		return errors.WithMessage(err, "failed to remove affiliation")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return affiliationResponse(resp), nil
+}
+
+// GetCAInfo returns the generic information of the CA. This request is not authenticated.
+// caname: name of the CA; the default CA of the organization if empty
+func (c *fabricCAAdapter) GetCAInfo(caname string) (*api.GetCAInfoResponse, error) {
+	ca, caName := c.ca(caname)
+	var resp *calib.GetServerInfoResponse
+	err := ca.do(func(caClient *calib.Client) error {
+		var err error
+		resp, err = caClient.GetCAInfo(&caapi.GetCAInfoRequest{CAName: caName})
+		return err
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get CA info")
+	}
+
+	return &api.GetCAInfoResponse{
+		CAName:  resp.CAName,
+		CAChain: resp.CAChain,
+		Version: resp.Version,
+	}, nil
+}
+
+// GetCertificates returns the certificates selected by the request that the registrar is authorized to see
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Get Certificates Request
+func (c *fabricCAAdapter) GetCertificates(key core.Key, cert []byte, request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	req := caapi.GetCertificatesRequest{
+		ID:     request.ID,
+		Serial: request.Serial,
+		AKI:    request.AKI,
+		Revoked: caapi.TimeRange{
+			StartTime: formatTime(request.RevokedStart),
+			EndTime:   formatTime(request.RevokedEnd),
+		},
+		Expired: caapi.TimeRange{
+			StartTime: formatTime(request.ExpiredStart),
+			EndTime:   formatTime(request.ExpiredEnd),
+		},
+		NotRevoked: request.NotRevoked,
+		NotExpired: request.NotExpired,
+	}
+
+	var resp *caapi.GetCertificatesResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.GetCertificates(&req)
+		return errors.WithMessage(err, "failed to get certificates")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetCertificatesResponse{
+		CAName: resp.CAName,
+		Certs:  resp.Certs,
+	}, nil
+}
+
+// GenCRL generates a CRL of the certificates revoked by the CA
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: Generate CRL Request
+func (c *fabricCAAdapter) GenCRL(key core.Key, cert []byte, request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	req := caapi.GenCRLRequest{
+		RevokedAfter:  request.RevokedAfter,
+		RevokedBefore: request.RevokedBefore,
+		ExpireAfter:   request.ExpireAfter,
+		ExpireBefore:  request.ExpireBefore,
+	}
+
+	var resp *caapi.GenCRLResponse
+	err := c.withRegistrar(request.CAName, key, cert, func(registrar *calib.Identity, caName string) error {
+		req.CAName = caName
+		var err error
+		resp, err = registrar.GenCRL(&req)
+		return errors.WithMessage(err, "failed to generate CRL")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GenCRLResponse{CRL: resp.CRL}, nil
+}
+
+// newCSRInfo translates the CSR info of an enrollment request for the given enrollment ID
+func newCSRInfo(enrollmentID string, info *api.CSRInfo) (*caapi.CSRInfo, error) {
+	if info == nil {
+		return nil, nil
+	}
+	// Fabric CA client always uses the enrollment ID as the common name
+	if info.CN != "" && info.CN != enrollmentID {
+		return nil, errors.Errorf("CSR common name [%s] must match the enrollment ID [%s]", info.CN, enrollmentID)
+	}
+
+	csr := &caapi.CSRInfo{
+		CN:    enrollmentID,
+		Hosts: info.Hosts,
+	}
+	for _, name := range info.Names {
+		csr.Names = append(csr.Names, cfsslcsr.Name{
+			C:            name.C,
+			ST:           name.ST,
+			L:            name.L,
+			O:            name.O,
+			OU:           name.OU,
+			SerialNumber: name.SerialNumber,
+		})
+	}
+	if info.KeyRequest != nil {
+		csr.KeyRequest = &caapi.BasicKeyRequest{Algo: info.KeyRequest.Algo, Size: info.KeyRequest.Size}
+	}
+	return csr, nil
+}
+
+func identityResponse(resp *caapi.IdentityResponse) *api.IdentityResponse {
+	return &api.IdentityResponse{
+		ID:             resp.ID,
+		Affiliation:    resp.Affiliation,
+		Type:           resp.Type,
+		Attributes:     attributes(resp.Attributes),
+		MaxEnrollments: resp.MaxEnrollments,
+		Secret:         resp.Secret,
+		CAName:         resp.CAName,
+	}
+}
+
+func affiliationResponse(resp *caapi.AffiliationResponse) *api.AffiliationResponse {
+	return &api.AffiliationResponse{
+		AffiliationInfo: affiliationInfo(resp.AffiliationInfo),
+		CAName:          resp.CAName,
+	}
+}
+
+func affiliationInfo(info caapi.AffiliationInfo) api.AffiliationInfo {
+	result := api.AffiliationInfo{Name: info.Name}
+	for _, child := range info.Affiliations {
+		result.Affiliations = append(result.Affiliations, affiliationInfo(child))
+	}
+	for _, id := range info.Identities {
+		result.Identities = append(result.Identities, api.IdentityInfo{
+			ID:             id.ID,
+			Type:           id.Type,
+			Affiliation:    id.Affiliation,
+			Attributes:     attributes(id.Attributes),
+			MaxEnrollments: id.MaxEnrollments,
+		})
+	}
+	return result
+}
+
+// caAttributes translates attributes; the attribute name is taken from Key if Name is not set
+func caAttributes(attrs []api.Attribute) []caapi.Attribute {
+	var caAttrs []caapi.Attribute
+	for _, attr := range attrs {
+		name := attr.Name
+		if name == "" {
+			name = attr.Key
+		}
+		caAttrs = append(caAttrs, caapi.Attribute{Name: name, Value: attr.Value, ECert: attr.ECert})
+	}
+	return caAttrs
+}
+
+func attributes(caAttrs []caapi.Attribute) []api.Attribute {
+	var attrs []api.Attribute
+	for _, caAttr := range caAttrs {
+		attrs = append(attrs, api.Attribute{Name: caAttr.Name, Key: caAttr.Name, Value: caAttr.Value, ECert: caAttr.ECert})
+	}
+	return attrs
+}
+
+// formatTime formats the time for a CA request; the zero time is not sent
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func attributeRequests(attrReqs []*api.AttributeRequest) []*caapi.AttributeRequest {
+	var caAttrReqs []*caapi.AttributeRequest
+	for _, attrReq := range attrReqs {
+		caAttrReqs = append(caAttrReqs, &caapi.AttributeRequest{Name: attrReq.Name, Optional: attrReq.Optional})
+	}
+	return caAttrReqs
+}
+
+// caEndpoints are the endpoints of a CA, e.g. several hostnames of the same Fabric CA
+type caEndpoints struct {
+	caName  string
+	clients []*calib.Client
+	mutex   sync.Mutex
+	current int
+}
+
+// do calls f with the client of the endpoint that responded last. On connection errors
+// the next endpoint is tried, until all endpoints have been tried.
+func (e *caEndpoints) do(f func(caClient *calib.Client) error) error {
+	e.mutex.Lock()
+	start := e.current
+	e.mutex.Unlock()
+
+	var err error
+	for i := 0; i < len(e.clients); i++ {
+		current := (start + i) % len(e.clients)
+		caClient := e.clients[current]
+		err = f(caClient)
+		if _, ok := errors.Cause(err).(net.Error); !ok {
+			e.mutex.Lock()
+			e.current = current
+			e.mutex.Unlock()
+			return err
+		}
+		logger.Warnf("Connection to CA endpoint [%s] failed: %s", caClient.Config.URL, errors.Cause(err))
+	}
+	return err
+}
+
+func createFabricCAClient(conf *core.CAConfig, cryptoSuite core.CryptoSuite, config core.Config) (*calib.Client, error) {
+
+	// Create new Fabric-ca client without configs
+	c := &calib.Client{
+		Config: &calib.ClientConfig{},
+	}
+
+	//set server CAName
+	c.Config.CAName = conf.CAName
+	//set server URL
+	c.Config.URL = endpoint.ToAddress(conf.URL)
+	//certs file list
+	if conf.TLSCACerts.Path != "" {
+		c.Config.TLS.CertFiles = strings.Split(conf.TLSCACerts.Path, ",")
+	}
+
+	// set key file and cert file
+	c.Config.TLS.Client.CertFile = conf.TLSCACerts.Client.Cert.Path
+	c.Config.TLS.Client.KeyFile = conf.TLSCACerts.Client.Key.Path
+
 	// get CAClient configs
-	_, err = config.Client()
+	_, err := config.Client()
 	if err != nil {
 		return nil, err
 	}
 
+	//proxy of the connections to the CA
+	c.Config.Proxy, err = comm.ProxyURL(config, conf.Proxy)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid proxy config")
+	}
+
 	//TLS flag enabled/disabled
 	c.Config.TLS.Enabled = endpoint.IsTLSEnabled(conf.URL)
 	c.Config.MSPDir = config.CAKeyStorePath()
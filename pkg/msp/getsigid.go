@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -41,23 +43,56 @@ func newUser(userData *msp.UserData, cryptoSuite core.CryptoSuite) (*User, error
 
 // NewUser creates a User instance
 func (mgr *IdentityManager) NewUser(userData *msp.UserData) (*User, error) {
-	return newUser(userData, mgr.cryptoSuite)
+	privateKey, err := mgr.getUserKey(userData.Name, userData.EnrollmentCertificate)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey == nil {
+		return newUser(userData, mgr.cryptoSuite)
+	}
+	u := &User{
+		mspID: userData.MspID,
+		name:  userData.Name,
+		enrollmentCertificate: userData.EnrollmentCertificate,
+		privateKey:            privateKey,
+	}
+	return u, nil
 }
 
 func (mgr *IdentityManager) loadUserFromStore(userName string) (msp.User, error) {
 	if mgr.userStore == nil {
 		return nil, msp.ErrUserNotFound
 	}
-	var user msp.User
 	userData, err := mgr.userStore.Load(msp.UserIdentifier{MspID: mgr.orgMspID, Name: userName})
 	if err != nil {
+		if err == msp.ErrUserNotFound {
+			// The user was removed from the store
+			mgr.users.Delete(userName)
+		}
 		return nil, err
 	}
-	user, err = mgr.NewUser(userData)
+
+	cached, ok := mgr.users.Load(userName)
+	if ok {
+		cachedUser := cached.(*User)
+		if bytes.Equal(cachedUser.EnrollmentCertificate(), userData.EnrollmentCertificate) {
+			return cachedUser, nil
+		}
+	}
+
+	user, err := mgr.NewUser(userData)
 	if err != nil {
 		return nil, err
 	}
-	return user, nil
+	if ok {
+		// The user was re-enrolled; renew the user instance shared by existing contexts
+		logger.Debugf("Renewing enrollment certificate of user [%s]", userName)
+		cachedUser := cached.(*User)
+		cachedUser.renew(user.Enrollment())
+		return cachedUser, nil
+	}
+	cached, _ = mgr.users.LoadOrStore(userName, user)
+	return cached.(*User), nil
 }
 
 // GetSigningIdentity returns a signing identity for the given user name
@@ -66,7 +101,8 @@ func (mgr *IdentityManager) GetSigningIdentity(userName string) (*msp.SigningIde
 	if err != nil {
 		return nil, err
 	}
-	signingIdentity := &msp.SigningIdentity{MspID: user.MspID(), PrivateKey: user.PrivateKey(), EnrollmentCert: user.EnrollmentCertificate()}
+	cert, privateKey := enrollment(user)
+	signingIdentity := &msp.SigningIdentity{MspID: user.MspID(), PrivateKey: privateKey, EnrollmentCert: cert}
 	return signingIdentity, nil
 }
 
@@ -95,9 +131,15 @@ func (mgr *IdentityManager) GetUser(userName string) (msp.User, error) {
 		if certBytes == nil {
 			return nil, msp.ErrUserNotFound
 		}
-		privateKey, err := mgr.getEmbeddedPrivateKey(userName)
+		privateKey, err := mgr.getUserKey(userName, certBytes)
 		if err != nil {
-			return nil, errors.WithMessage(err, "fetching embedded private key failed")
+			return nil, err
+		}
+		if privateKey == nil {
+			privateKey, err = mgr.getEmbeddedPrivateKey(userName)
+			if err != nil {
+				return nil, errors.WithMessage(err, "fetching embedded private key failed")
+			}
 		}
 		if privateKey == nil {
 			privateKey, err = mgr.getPrivateKeyFromCert(userName, certBytes)
@@ -257,3 +299,58 @@ func (mgr *IdentityManager) getPrivateKeyFromKeyStore(userName string, ski []byt
 	}
 	return nil, core.ErrKeyValueNotFound
 }
+
+// keyLabelLookup is implemented by cryptosuites whose keys can be looked up by label, like the PKCS11 suite
+type keyLabelLookup interface {
+	GetKeyByLabel(label string) (core.Key, error)
+}
+
+// keyIDLookup is implemented by cryptosuites whose keys can be looked up by identifier, like the PKCS11 suite
+type keyIDLookup interface {
+	GetKeyByID(id []byte) (core.Key, error)
+}
+
+// getUserKey returns the private key referenced by the user keys of the organization config,
+// or nil if the user's key is not referenced there
+func (mgr *IdentityManager) getUserKey(userName string, cert []byte) (core.Key, error) {
+	keyConfig, ok := mgr.userKeys[strings.ToLower(userName)]
+	if !ok {
+		return nil, nil
+	}
+
+	var privateKey core.Key
+	var err error
+	switch {
+	case keyConfig.Label != "":
+		lookup, ok := mgr.cryptoSuite.(keyLabelLookup)
+		if !ok {
+			return nil, errors.Errorf("the cryptosuite does not support key lookup by label, required for user [%s]", userName)
+		}
+		privateKey, err = lookup.GetKeyByLabel(keyConfig.Label)
+	case keyConfig.ID != "":
+		lookup, ok := mgr.cryptoSuite.(keyIDLookup)
+		if !ok {
+			return nil, errors.Errorf("the cryptosuite does not support key lookup by identifier, required for user [%s]", userName)
+		}
+		id, decodeErr := hex.DecodeString(keyConfig.ID)
+		if decodeErr != nil {
+			return nil, errors.Wrapf(decodeErr, "decoding key identifier of user [%s] failed", userName)
+		}
+		privateKey, err = lookup.GetKeyByID(id)
+	default:
+		return nil, errors.Errorf("key label or identifier is required for user [%s]", userName)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("getting private key of user [%s] failed", userName))
+	}
+
+	// The key must belong to the user's certificate
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(cert, mgr.cryptoSuite)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	if !bytes.Equal(pubKey.SKI(), privateKey.SKI()) {
+		return nil, errors.Errorf("private key of user [%s] does not match the certificate", userName)
+	}
+	return privateKey, nil
+}
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
@@ -205,3 +206,81 @@ func TestGetSigningIdentityFromEmbeddedCryptoConfig(t *testing.T) {
 func createRandomName() string {
 	return "user" + strconv.Itoa(rand.Intn(500000))
 }
+
+// keyLookupSuite is a cryptosuite whose keys can be looked up by label and identifier
+type keyLookupSuite struct {
+	core.CryptoSuite
+	labels map[string]core.Key
+	ids    map[string]core.Key
+}
+
+func (s *keyLookupSuite) GetKeyByLabel(label string) (core.Key, error) {
+	if k, ok := s.labels[label]; ok {
+		return k, nil
+	}
+	return nil, errors.New("key not found")
+}
+
+func (s *keyLookupSuite) GetKeyByID(id []byte) (core.Key, error) {
+	if k, ok := s.ids[string(id)]; ok {
+		return k, nil
+	}
+	return nil, errors.New("key not found")
+}
+
+func TestGetSigningIdentityWithUserKeys(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	key, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes([]byte(testPrivKey), f.cryptoSuite, true)
+	if err != nil {
+		t.Fatalf("Importing key failed: %v", err)
+	}
+	otherKey, err := f.cryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("Generating key failed: %v", err)
+	}
+	suite := &keyLookupSuite{
+		CryptoSuite: f.cryptoSuite,
+		labels:      map[string]core.Key{"user-key": key, "other-key": otherKey},
+		ids:         map[string]core.Key{"\x01\x02": key},
+	}
+	mgr := f.identityManager
+	mgr.cryptoSuite = suite
+
+	mspID := mspIDByOrgName(t, f.config, org1)
+	for _, keyConfig := range []core.UserKeyConfig{{Label: "user-key"}, {ID: "0102"}} {
+		userName := createRandomName()
+		mgr.userKeys = map[string]core.UserKeyConfig{strings.ToLower(userName): keyConfig}
+		if err := f.userStore.Store(&msp.UserData{MspID: mspID, Name: userName, EnrollmentCertificate: []byte(testCert)}); err != nil {
+			t.Fatalf("Storing user failed: %v", err)
+		}
+
+		signingIdentity, err := mgr.GetSigningIdentity(userName)
+		if err != nil {
+			t.Fatalf("GetSigningIdentity failed: %v", err)
+		}
+		if signingIdentity.PrivateKey != key {
+			t.Fatalf("Expected the private key referenced by %+v", keyConfig)
+		}
+	}
+
+	userName := createRandomName()
+	if err := f.userStore.Store(&msp.UserData{MspID: mspID, Name: userName, EnrollmentCertificate: []byte(testCert)}); err != nil {
+		t.Fatalf("Storing user failed: %v", err)
+	}
+	for _, keyConfig := range []core.UserKeyConfig{{Label: "other-key"}, {Label: "unknown"}, {ID: "zz"}, {}} {
+		mgr.userKeys = map[string]core.UserKeyConfig{strings.ToLower(userName): keyConfig}
+		if _, err := mgr.GetSigningIdentity(userName); err == nil {
+			t.Fatalf("Expected error for user key %+v", keyConfig)
+		}
+	}
+
+	// The cryptosuite must support key lookup
+	mgr.cryptoSuite = f.cryptoSuite
+	mgr.userKeys = map[string]core.UserKeyConfig{strings.ToLower(userName): {Label: "user-key"}}
+	if _, err := mgr.GetSigningIdentity(userName); err == nil {
+		t.Fatal("Expected error for cryptosuite without key lookup")
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/attrmgr"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	pb_msp "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
+)
+
+// Roles of Fabric identities, carried as OUs of their certificates when node OUs are enabled
+const (
+	RoleClient  = "client"
+	RolePeer    = "peer"
+	RoleOrderer = "orderer"
+	RoleAdmin   = "admin"
+)
+
+// IdentityInfo is the information of an X.509 identity, parsed from its certificate
+type IdentityInfo struct {
+	// MspID is the MSP ID of the identity
+	MspID string
+	// Certificate is the certificate of the identity
+	Certificate *x509.Certificate
+	// Chain are the certificates that follow the identity's certificate in its PEM encoding, if any
+	Chain []*x509.Certificate
+	// Subject and Issuer are the distinguished names of the subject and issuer of the certificate
+	Subject pkix.Name
+	Issuer  pkix.Name
+	// SerialNumber is the serial number of the certificate
+	SerialNumber *big.Int
+	// SubjectKeyID and AuthorityKeyID are the subject and authority key identifiers of the certificate
+	SubjectKeyID   []byte
+	AuthorityKeyID []byte
+	// NotBefore and NotAfter delimit the validity period of the certificate
+	NotBefore time.Time
+	NotAfter  time.Time
+	// OUs are the organizational units of the subject
+	OUs []string
+	// Roles are the OUs that are Fabric roles (client, peer, orderer or admin)
+	Roles []string
+	// Attributes are the attributes added to the certificate by Fabric CA
+	Attributes map[string]string
+}
+
+// NewIdentityInfo parses the PEM-encoded certificate of an identity of the given MSP
+func NewIdentityInfo(mspID string, certPEM []byte) (*IdentityInfo, error) {
+	var certs []*x509.Certificate
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing identity certificate failed")
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("decoding identity certificate failed")
+	}
+
+	cert := certs[0]
+	attrs, err := certificateAttributes(cert)
+	if err != nil {
+		return nil, err
+	}
+	info := &IdentityInfo{
+		MspID:          mspID,
+		Certificate:    cert,
+		Chain:          certs[1:],
+		Subject:        cert.Subject,
+		Issuer:         cert.Issuer,
+		SerialNumber:   cert.SerialNumber,
+		SubjectKeyID:   cert.SubjectKeyId,
+		AuthorityKeyID: cert.AuthorityKeyId,
+		NotBefore:      cert.NotBefore,
+		NotAfter:       cert.NotAfter,
+		OUs:            cert.Subject.OrganizationalUnit,
+		Attributes:     attrs,
+	}
+	for _, ou := range info.OUs {
+		switch ou {
+		case RoleClient, RolePeer, RoleOrderer, RoleAdmin:
+			info.Roles = append(info.Roles, ou)
+		}
+	}
+	return info, nil
+}
+
+// IdentityInfoFromSerializedIdentity parses a serialized identity, such as the creator
+// of a transaction or an endorser
+func IdentityInfoFromSerializedIdentity(serializedIdentity []byte) (*IdentityInfo, error) {
+	sID := &pb_msp.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedIdentity, sID); err != nil {
+		return nil, errors.Wrap(err, "unmarshal serialized identity failed")
+	}
+	return NewIdentityInfo(sID.Mspid, sID.IdBytes)
+}
+
+// IdentityInfoFromUser parses the enrollment certificate of the user
+func IdentityInfoFromUser(user msp.User) (*IdentityInfo, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+	return NewIdentityInfo(user.MspID(), user.EnrollmentCertificate())
+}
+
+// Attribute returns the value of the Fabric CA attribute with the given name
+func (info *IdentityInfo) Attribute(name string) (string, bool) {
+	value, ok := info.Attributes[name]
+	return value, ok
+}
+
+// HasRole returns true if the identity has the given role
+func (info *IdentityInfo) HasRole(role string) bool {
+	for _, r := range info.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAt returns true if the certificate is valid at the given time
+func (info *IdentityInfo) ValidAt(t time.Time) bool {
+	return !t.Before(info.NotBefore) && !t.After(info.NotAfter)
+}
+
+// VerifyChain verifies the certificate against the given root and intermediate certificates,
+// as well as the certificates of the chain of the identity, at the current time, and returns
+// the issuer chain of the certificate, from its issuer to the root
+func (info *IdentityInfo) VerifyChain(roots, intermediates []*x509.Certificate) ([]*x509.Certificate, error) {
+	return info.VerifyChainAt(time.Now(), roots, intermediates)
+}
+
+// VerifyChainAt verifies the certificate chain like VerifyChain, at the given time: the
+// certificate and all certificates of its chain must be valid at that time
+func (info *IdentityInfo) VerifyChainAt(t time.Time, roots, intermediates []*x509.Certificate) ([]*x509.Certificate, error) {
+	opts := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   t,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, cert := range roots {
+		opts.Roots.AddCert(cert)
+	}
+	for _, cert := range intermediates {
+		opts.Intermediates.AddCert(cert)
+	}
+	for _, cert := range info.Chain {
+		opts.Intermediates.AddCert(cert)
+	}
+	chains, err := info.Certificate.Verify(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying certificate chain failed")
+	}
+	return chains[0][1:], nil
+}
+
+// certificateAttributes returns the attributes of the Fabric CA attribute extension of the certificate
+func certificateAttributes(cert *x509.Certificate) (map[string]string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(attrmgr.AttrOID) {
+			continue
+		}
+		attrs := &attrmgr.Attributes{}
+		if err := json.Unmarshal(ext.Value, attrs); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling certificate attributes failed")
+		}
+		return attrs.Attrs, nil
+	}
+	return map[string]string{}, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/attrmgr"
+	pb_msp "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityInfo(t *testing.T) {
+	root, rootKey := newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1.example.com"},
+		SubjectKeyId:          []byte{1, 2, 3},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	leaf, _ := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "user1", OrganizationalUnit: []string{"client", "org1"}},
+		ExtraExtensions: []pkix.Extension{
+			{Id: attrmgr.AttrOID, Value: []byte(`{"attrs":{"hf.EnrollmentID":"user1","app.admin":"true"}}`)},
+		},
+	}, root, rootKey)
+
+	info, err := NewIdentityInfo("Org1MSP", certPEM(leaf))
+	if err != nil {
+		t.Fatalf("NewIdentityInfo failed: %v", err)
+	}
+	assert.Equal(t, "Org1MSP", info.MspID)
+	assert.Equal(t, big.NewInt(42), info.SerialNumber)
+	assert.Equal(t, "ca.org1.example.com", info.Issuer.CommonName)
+	assert.Equal(t, []byte{1, 2, 3}, info.AuthorityKeyID)
+	assert.ElementsMatch(t, []string{"client", "org1"}, info.OUs)
+	assert.Equal(t, []string{RoleClient}, info.Roles)
+	assert.True(t, info.HasRole(RoleClient))
+	assert.False(t, info.HasRole(RoleAdmin))
+	assert.True(t, info.ValidAt(time.Now()))
+	assert.False(t, info.ValidAt(info.NotAfter.Add(time.Second)))
+	assert.Empty(t, info.Chain)
+
+	value, ok := info.Attribute("app.admin")
+	assert.True(t, ok)
+	assert.Equal(t, "true", value)
+	_, ok = info.Attribute("app.other")
+	assert.False(t, ok)
+
+	chain, err := info.VerifyChain([]*x509.Certificate{root}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*x509.Certificate{root}, chain)
+	_, err = info.VerifyChain(nil, nil)
+	assert.Error(t, err, "expected error without roots")
+
+	// The chain is verified at the current time or at the given time
+	chain, err = info.VerifyChainAt(info.NotBefore, []*x509.Certificate{root}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*x509.Certificate{root}, chain)
+	_, err = info.VerifyChainAt(info.NotAfter.Add(time.Second), []*x509.Certificate{root}, nil)
+	assert.Error(t, err, "expected error for expired certificate")
+
+	// The intermediates of the caller are not modified
+	intermediates := make([]*x509.Certificate, 1, 2)
+	intermediates[0] = root
+	info.Chain = []*x509.Certificate{leaf}
+	_, err = info.VerifyChain([]*x509.Certificate{root}, intermediates[:1])
+	assert.NoError(t, err)
+	assert.Nil(t, intermediates[:2][1], "expected the intermediates not to be appended to")
+	info.Chain = nil
+
+	// The chain of the identity is used to verify the certificate
+	info, err = NewIdentityInfo("Org1MSP", append(certPEM(leaf), certPEM(root)...))
+	if err != nil {
+		t.Fatalf("NewIdentityInfo failed: %v", err)
+	}
+	assert.Equal(t, []*x509.Certificate{root}, info.Chain)
+
+	// Serialized identities are supported
+	serializedIdentity, err := proto.Marshal(&pb_msp.SerializedIdentity{Mspid: "Org1MSP", IdBytes: certPEM(leaf)})
+	if err != nil {
+		t.Fatalf("marshalling serialized identity failed: %v", err)
+	}
+	info, err = IdentityInfoFromSerializedIdentity(serializedIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, leaf, info.Certificate)
+
+	// Certificates without attributes
+	info, err = IdentityInfoFromUser(&User{mspID: "Org1MSP", name: "user1", enrollmentCertificate: certPEM(root)})
+	assert.NoError(t, err)
+	assert.Empty(t, info.Attributes)
+	assert.Empty(t, info.Roles)
+}
+
+func TestIdentityInfoErrors(t *testing.T) {
+	_, err := NewIdentityInfo("Org1MSP", []byte("invalid"))
+	assert.Error(t, err, "expected error for invalid certificate")
+	_, err = IdentityInfoFromSerializedIdentity([]byte("invalid"))
+	assert.Error(t, err, "expected error for invalid serialized identity")
+	_, err = IdentityInfoFromUser(nil)
+	assert.Error(t, err, "expected error for nil user")
+
+	cert, _ := newTestCert(t, &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		ExtraExtensions: []pkix.Extension{{Id: attrmgr.AttrOID, Value: []byte("invalid")}},
+	}, nil, nil)
+	_, err = NewIdentityInfo("Org1MSP", certPEM(cert))
+	assert.Error(t, err, "expected error for invalid attributes")
+}
+
+// newTestCert creates a certificate from the template, signed by the parent or self-signed
+func newTestCert(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key failed: %v", err)
+	}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("creating certificate failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate failed: %v", err)
+	}
+	return cert, key
+}
+
+func certPEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
@@ -9,6 +9,7 @@ package msp
 import (
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -25,9 +26,13 @@ type IdentityManager struct {
 	config          core.Config
 	cryptoSuite     core.CryptoSuite
 	embeddedUsers   map[string]core.TLSKeyPair
+	userKeys        map[string]core.UserKeyConfig
 	mspPrivKeyStore core.KVStore
 	mspCertStore    core.KVStore
 	userStore       msp.UserStore
+	// users caches the users loaded from the user store, so that all contexts
+	// of a user share the same instance, which is renewed on re-enrollment
+	users sync.Map
 }
 
 // NewIdentityManager creates a new instance of IdentityManager
@@ -76,6 +81,7 @@ func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite cor
 		mspPrivKeyStore: mspPrivKeyStore,
 		mspCertStore:    mspCertStore,
 		embeddedUsers:   orgConfig.Users,
+		userKeys:        orgConfig.UserKeys,
 		userStore:       userStore,
 		// CA Client state is created lazily, when (if) needed
 	}
@@ -0,0 +1,261 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/wallet"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+// ListUsers returns the users of the organization in the user store, sorted by name.
+// The user store must be able to list its users.
+func (mgr *IdentityManager) ListUsers() ([]*msp.UserData, error) {
+	lister, ok := mgr.userStore.(msp.UserLister)
+	if !ok {
+		return nil, errors.New("user store does not support listing users")
+	}
+	all, err := lister.List()
+	if err != nil {
+		return nil, errors.WithMessage(err, "listing users failed")
+	}
+
+	var users []*msp.UserData
+	for _, user := range all {
+		if user.MspID == mgr.orgMspID {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, nil
+}
+
+// DeleteUser deletes the user from the user store and the private key of its enrollment
+// certificate from the key store of the SDK.
+func (mgr *IdentityManager) DeleteUser(userName string) error {
+	if mgr.userStore == nil {
+		return errors.New("user store is not configured")
+	}
+	id := msp.UserIdentifier{MspID: mgr.orgMspID, Name: userName}
+	userData, err := mgr.userStore.Load(id)
+	if err != nil {
+		return errors.WithMessage(err, "loading user failed")
+	}
+	if err := mgr.userStore.Delete(id); err != nil {
+		return errors.WithMessage(err, "deleting user failed")
+	}
+	mgr.users.Delete(userName)
+
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(userData.EnrollmentCertificate, mgr.cryptoSuite)
+	if err != nil {
+		return errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	if err := mgr.deletePrivateKey(pubKey.SKI()); err != nil {
+		return errors.WithMessage(err, "deleting private key failed")
+	}
+	return nil
+}
+
+// deletePrivateKey removes the private key with the given SKI from the key store of the SDK:
+// the key store of the wallet if the credential store is a wallet, the key store directory otherwise
+func (mgr *IdentityManager) deletePrivateKey(ski []byte) error {
+	clientConfig, err := mgr.config.Client()
+	if err != nil {
+		return errors.WithMessage(err, "unable to retrieve client config")
+	}
+	if clientConfig.CredentialStore.Type == wallet.CredentialStoreType {
+		w, err := wallet.FromConfig(mgr.config)
+		if err != nil {
+			return errors.WithMessage(err, "opening wallet failed")
+		}
+		defer w.Close()
+		return w.KeyStore().DeleteKey(ski)
+	}
+	if mgr.config.KeyStorePath() == "" {
+		return nil
+	}
+	file := filepath.Join(mgr.config.KeyStorePath(), hex.EncodeToString(ski)+"_sk")
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing private key file failed")
+	}
+	return nil
+}
+
+// ExportUser returns an identity bundle containing the enrollment certificate,
+// the private key and the metadata of the user. Only software keys can be exported.
+func (mgr *IdentityManager) ExportUser(userName string) (*api.IdentityBundle, error) {
+	user, err := mgr.GetUser(userName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading user failed")
+	}
+	cert, privateKey := enrollment(user)
+	keyPem, err := privateKeyToPEM(privateKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "exporting private key failed")
+	}
+	metadata, err := mgr.userMetadata(userName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading user metadata failed")
+	}
+	return &api.IdentityBundle{
+		Version:     api.IdentityBundleVersion,
+		MspID:       user.MspID(),
+		Name:        user.Name(),
+		Certificate: string(cert),
+		PrivateKey:  string(keyPem),
+		Metadata:    metadata,
+	}, nil
+}
+
+// userMetadata returns the metadata of the user in the user store; users
+// of the crypto config and embedded users have no metadata
+func (mgr *IdentityManager) userMetadata(userName string) (map[string]string, error) {
+	if mgr.userStore == nil {
+		return nil, nil
+	}
+	userData, err := mgr.userStore.Load(msp.UserIdentifier{MspID: mgr.orgMspID, Name: userName})
+	if err != nil {
+		if err == msp.ErrUserNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return userData.Metadata, nil
+}
+
+// ImportUser stores the user of the identity bundle, with its metadata, in the user store and its
+// private key in the key store of the crypto suite. The bundle must belong to the organization.
+func (mgr *IdentityManager) ImportUser(bundle *api.IdentityBundle) error {
+	if mgr.userStore == nil {
+		return errors.New("user store is not configured")
+	}
+	if bundle == nil {
+		return errors.New("identity bundle is nil")
+	}
+	if bundle.Version != api.IdentityBundleVersion {
+		return errors.Errorf("unsupported identity bundle version %d", bundle.Version)
+	}
+	if bundle.Name == "" {
+		return errors.New("identity bundle has no user name")
+	}
+	if bundle.MspID != mgr.orgMspID {
+		return errors.Errorf("identity bundle belongs to MSP [%s], expected [%s]", bundle.MspID, mgr.orgMspID)
+	}
+
+	cert := []byte(bundle.Certificate)
+	key := []byte(bundle.PrivateKey)
+	if err := mgr.checkKeyPair(cert, key); err != nil {
+		return err
+	}
+	if _, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(key, mgr.cryptoSuite, false); err != nil {
+		return errors.WithMessage(err, "importing private key failed")
+	}
+	userData := &msp.UserData{
+		MspID: bundle.MspID,
+		Name:  bundle.Name,
+		EnrollmentCertificate: cert,
+		Metadata:              bundle.Metadata,
+	}
+	if err := mgr.userStore.Store(userData); err != nil {
+		return errors.WithMessage(err, "storing user failed")
+	}
+	return nil
+}
+
+// ImportMSPDir imports the identity of an MSP directory, as generated by cryptogen or
+// fabric-ca-client, as the given user of the organization. The certificate is read from
+// the signcerts sub directory and the matching private key from the keystore sub directory.
+func (mgr *IdentityManager) ImportMSPDir(userName, dir string) error {
+	certFiles, err := filepath.Glob(filepath.Join(dir, "signcerts", "*.pem"))
+	if err != nil {
+		return errors.Wrap(err, "listing signcerts failed")
+	}
+	if len(certFiles) != 1 {
+		return errors.Errorf("expected one certificate in %s, found %d", filepath.Join(dir, "signcerts"), len(certFiles))
+	}
+	cert, err := ioutil.ReadFile(certFiles[0])
+	if err != nil {
+		return errors.Wrap(err, "reading certificate failed")
+	}
+
+	keyFiles, err := ioutil.ReadDir(filepath.Join(dir, "keystore"))
+	if err != nil {
+		return errors.Wrap(err, "reading keystore failed")
+	}
+	for _, keyFile := range keyFiles {
+		if keyFile.IsDir() {
+			continue
+		}
+		key, err := ioutil.ReadFile(filepath.Join(dir, "keystore", keyFile.Name()))
+		if err != nil {
+			return errors.Wrap(err, "reading private key failed")
+		}
+		if mgr.checkKeyPair(cert, key) != nil {
+			logger.Debugf("Private key [%s] does not match the certificate", keyFile.Name())
+			continue
+		}
+		return mgr.ImportUser(&api.IdentityBundle{
+			Version:     api.IdentityBundleVersion,
+			MspID:       mgr.orgMspID,
+			Name:        userName,
+			Certificate: string(cert),
+			PrivateKey:  string(key),
+		})
+	}
+	return errors.Errorf("private key of the certificate not found in %s", filepath.Join(dir, "keystore"))
+}
+
+// checkKeyPair checks that the PEM-encoded private key belongs to the certificate
+func (mgr *IdentityManager) checkKeyPair(cert, key []byte) error {
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(cert, mgr.cryptoSuite)
+	if err != nil {
+		return errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	privKey, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(key, mgr.cryptoSuite, true)
+	if err != nil {
+		return errors.WithMessage(err, "parsing private key failed")
+	}
+	if !bytes.Equal(pubKey.SKI(), privKey.SKI()) {
+		return errors.New("private key does not match the certificate")
+	}
+	return nil
+}
+
+// privateKeyToPEM encodes a software private key in PKCS#8 form
+func privateKeyToPEM(key core.Key) ([]byte, error) {
+	if key == nil || !key.Private() {
+		return nil, errors.New("a private key is required")
+	}
+	bccspKey, ok := wrapper.GetBCCSPKey(key)
+	if !ok {
+		return nil, errors.Errorf("key of type %T is not exportable", key)
+	}
+	raw, err := sw.ExportKey(bccspKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "key is not exportable")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling private key failed")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
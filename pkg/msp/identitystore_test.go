@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportExportUser(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	mspID := mspIDByOrgName(t, f.config, org1)
+	userName := createRandomName()
+	bundle := &api.IdentityBundle{
+		Version:     api.IdentityBundleVersion,
+		MspID:       mspID,
+		Name:        userName,
+		Certificate: testCert,
+		PrivateKey:  testPrivKey,
+		Metadata:    map[string]string{"origin": "test"},
+	}
+	if err := f.identityManager.ImportUser(bundle); err != nil {
+		t.Fatalf("ImportUser failed: %v", err)
+	}
+
+	users, err := f.identityManager.ListUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, []*msp.UserData{{MspID: mspID, Name: userName, EnrollmentCertificate: []byte(testCert), Metadata: bundle.Metadata}}, users)
+
+	exported, err := f.identityManager.ExportUser(userName)
+	if err != nil {
+		t.Fatalf("ExportUser failed: %v", err)
+	}
+	assert.Equal(t, mspID, exported.MspID)
+	assert.Equal(t, userName, exported.Name)
+	assert.Equal(t, testCert, exported.Certificate)
+	assert.Equal(t, bundle.Metadata, exported.Metadata)
+
+	// The private key is deleted with the user
+	keyFile := filepath.Join(f.config.KeyStorePath(), testPrivKeySKI(t, f)+"_sk")
+	if _, err := os.Stat(keyFile); err != nil {
+		t.Fatalf("Expected private key in key store: %v", err)
+	}
+
+	// The bundle can be moved to another store
+	data, err := json.Marshal(exported)
+	if err != nil {
+		t.Fatalf("Marshalling bundle failed: %v", err)
+	}
+	assert.NoError(t, f.identityManager.DeleteUser(userName))
+	_, err = f.identityManager.GetUser(userName)
+	assert.Equal(t, msp.ErrUserNotFound, err)
+	_, err = os.Stat(keyFile)
+	assert.True(t, os.IsNotExist(err), "expected private key to be deleted")
+
+	imported := &api.IdentityBundle{}
+	if err := json.Unmarshal(data, imported); err != nil {
+		t.Fatalf("Unmarshalling bundle failed: %v", err)
+	}
+	assert.NoError(t, f.identityManager.ImportUser(imported))
+	user, err := f.identityManager.GetUser(userName)
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	assert.Equal(t, []byte(testCert), user.EnrollmentCertificate())
+	reexported, err := f.identityManager.ExportUser(userName)
+	if err != nil {
+		t.Fatalf("ExportUser failed: %v", err)
+	}
+	assert.Equal(t, exported, reexported)
+}
+
+// testPrivKeySKI returns the hex-encoded SKI of the test private key
+func testPrivKeySKI(t *testing.T, f textFixture) string {
+	pubKey, err := cryptoutil.GetPublicKeyFromCert([]byte(testCert), f.cryptoSuite)
+	if err != nil {
+		t.Fatalf("Fetching public key failed: %v", err)
+	}
+	return hex.EncodeToString(pubKey.SKI())
+}
+
+func TestUserRemovedFromStore(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	userName := createRandomName()
+	bundle := &api.IdentityBundle{
+		Version:     api.IdentityBundleVersion,
+		MspID:       mspIDByOrgName(t, f.config, org1),
+		Name:        userName,
+		Certificate: testCert,
+		PrivateKey:  testPrivKey,
+	}
+	if err := f.identityManager.ImportUser(bundle); err != nil {
+		t.Fatalf("ImportUser failed: %v", err)
+	}
+	if _, err := f.identityManager.GetUser(userName); err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	_, cached := f.identityManager.users.Load(userName)
+	assert.True(t, cached, "expected user to be cached")
+
+	// The user is removed from the store by another process
+	assert.NoError(t, f.identityManager.userStore.Delete(msp.UserIdentifier{MspID: bundle.MspID, Name: userName}))
+	_, err := f.identityManager.GetUser(userName)
+	assert.Equal(t, msp.ErrUserNotFound, err)
+	_, cached = f.identityManager.users.Load(userName)
+	assert.False(t, cached, "expected user to be removed from the cache")
+}
+
+func TestImportUserErrors(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	valid := api.IdentityBundle{
+		Version:     api.IdentityBundleVersion,
+		MspID:       mspIDByOrgName(t, f.config, org1),
+		Name:        createRandomName(),
+		Certificate: testCert,
+		PrivateKey:  testPrivKey,
+	}
+
+	assert.Error(t, f.identityManager.ImportUser(nil), "expected error for nil bundle")
+
+	bundle := valid
+	bundle.Version = 0
+	assert.Error(t, f.identityManager.ImportUser(&bundle), "expected error for unsupported version")
+
+	bundle = valid
+	bundle.Name = ""
+	assert.Error(t, f.identityManager.ImportUser(&bundle), "expected error without user name")
+
+	bundle = valid
+	bundle.MspID = "Org2MSP"
+	assert.Error(t, f.identityManager.ImportUser(&bundle), "expected error for another organization")
+
+	bundle = valid
+	bundle.PrivateKey = "invalid"
+	assert.Error(t, f.identityManager.ImportUser(&bundle), "expected error for invalid private key")
+
+	bundle = valid
+	bundle.Certificate = string(readCert(t))
+	assert.Error(t, f.identityManager.ImportUser(&bundle), "expected error for mismatching private key")
+
+	users, err := f.identityManager.ListUsers()
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestImportMSPDir(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	dir, err := ioutil.TempDir("", "msp")
+	if err != nil {
+		t.Fatalf("Creating MSP directory failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	userName := createRandomName()
+	assert.Error(t, f.identityManager.ImportMSPDir(userName, dir), "expected error without signcerts")
+
+	writeFile(t, filepath.Join(dir, "signcerts", "cert.pem"), testCert)
+	writeFile(t, filepath.Join(dir, "keystore", "0a_sk"), "not a key")
+	assert.Error(t, f.identityManager.ImportMSPDir(userName, dir), "expected error without matching private key")
+
+	writeFile(t, filepath.Join(dir, "keystore", "1b_sk"), testPrivKey)
+	if err := f.identityManager.ImportMSPDir(userName, dir); err != nil {
+		t.Fatalf("ImportMSPDir failed: %v", err)
+	}
+	user, err := f.identityManager.GetUser(userName)
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	assert.Equal(t, []byte(testCert), user.EnrollmentCertificate())
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("Creating directory failed: %v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Writing file failed: %v", err)
+	}
+}
@@ -31,6 +31,7 @@ const (
 	noCAConfigPath              = "testdata/config_no_ca.yaml"
 	embeddedRegistrarConfigPath = "testdata/config_embedded_registrar.yaml"
 	noRegistrarConfigPath       = "testdata/config_no_registrar.yaml"
+	multiCAConfigPath           = "testdata/config_multi_ca.yaml"
 )
 
 type textFixture struct {
@@ -7,36 +7,62 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"sync"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
 )
 
 // MemoryUserStore is in-memory implementation of UserStore
 type MemoryUserStore struct {
-	store map[string][]byte
+	mutex sync.RWMutex
+	store map[msp.UserIdentifier]msp.UserData
 }
 
 // NewMemoryUserStore creates a new MemoryUserStore instance
 func NewMemoryUserStore() *MemoryUserStore {
-	store := make(map[string][]byte)
+	store := make(map[msp.UserIdentifier]msp.UserData)
 	return &MemoryUserStore{store: store}
 }
 
 // Store stores a user into store
 func (s *MemoryUserStore) Store(user *msp.UserData) error {
-	s.store[user.Name+"@"+user.Name] = user.EnrollmentCertificate
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.store[userIdentifier(user)] = *user
 	return nil
 }
 
 // Load loads a user from store
 func (s *MemoryUserStore) Load(id msp.UserIdentifier) (*msp.UserData, error) {
-	cert, ok := s.store[id.Name+"@"+id.Name]
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	userData, ok := s.store[id]
 	if !ok {
 		return nil, msp.ErrUserNotFound
 	}
-	userData := msp.UserData{
-		Name:  id.Name,
-		MspID: id.MspID,
-		EnrollmentCertificate: cert,
-	}
 	return &userData, nil
 }
+
+// Delete deletes a user from store
+func (s *MemoryUserStore) Delete(id msp.UserIdentifier) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.store, id)
+	return nil
+}
+
+// List returns all users in the store
+func (s *MemoryUserStore) List() ([]*msp.UserData, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var users []*msp.UserData
+	for _, userData := range s.store {
+		userData := userData
+		users = append(users, &userData)
+	}
+	return users, nil
+}
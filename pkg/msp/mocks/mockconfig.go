@@ -42,6 +42,15 @@ func (c *MockConfig) CAConfig(org string) (*core.CAConfig, error) {
 	}, nil
 }
 
+// CAConfigs return the configurations of the CAs of the organization
+func (c *MockConfig) CAConfigs(org string) ([]core.CAConfig, error) {
+	caConfig, err := c.CAConfig(org)
+	if err != nil {
+		return nil, err
+	}
+	return []core.CAConfig{*caConfig}, nil
+}
+
 //CAServerCertPems Read configuration option for the server certificate embedded pems
 func (c *MockConfig) CAServerCertPems(org string) ([]string, error) {
 	return nil, nil
@@ -7,7 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package mocks
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 
 	"time"
 
@@ -60,13 +73,45 @@ type serverInfoResponseNet struct {
 	CAName string
 	// Base64 encoding of PEM-encoded certificate chain
 	CAChain string
+	// Version of the server
+	Version string
+}
+
+// The response to the GET /certificates request
+type certificatesResponseNet struct {
+	CAName string    `json:"caname"`
+	Certs  []certPEM `json:"certs"`
+}
+
+type certPEM struct {
+	PEM string `json:"PEM"`
+}
+
+// The response to the POST /gencrl request
+type genCRLResponseNet struct {
+	// Base64 encoding of PEM-encoded CRL
+	CRL string
 }
 
 // MockFabricCAServer is a mock for FabricCAServer
 type MockFabricCAServer struct {
-	address     string
-	cryptoSuite core.CryptoSuite
-	running     bool
+	address      string
+	cryptoSuite  core.CryptoSuite
+	running      bool
+	mutex        sync.RWMutex
+	lastEnroll   *api.EnrollmentRequestNet
+	identities   map[string]*api.IdentityInfo
+	affiliations map[string]bool
+	enrolled     map[string]bool
+	crlKey       *ecdsa.PrivateKey
+	crlCert      *x509.Certificate
+}
+
+// LastEnrollmentRequest returns the last enrollment or re-enrollment request received by the server
+func (s *MockFabricCAServer) LastEnrollmentRequest() *api.EnrollmentRequestNet {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastEnroll
 }
 
 // Start fabric CA mock server
@@ -78,11 +123,24 @@ func (s *MockFabricCAServer) Start(address string, cryptoSuite core.CryptoSuite)
 
 	s.address = address
 	s.cryptoSuite = cryptoSuite
+	s.identities = make(map[string]*api.IdentityInfo)
+	s.affiliations = map[string]bool{"org1": true, "org1.department1": true, "org2": true}
+	s.enrolled = make(map[string]bool)
+	if err := s.initCRLSigner(); err != nil {
+		return err
+	}
 
 	// Register request handlers
 	http.HandleFunc("/register", s.register)
 	http.HandleFunc("/enroll", s.enroll)
 	http.HandleFunc("/reenroll", s.enroll)
+	http.HandleFunc("/identities", s.listOrAddIdentities)
+	http.HandleFunc("/identities/", s.identity)
+	http.HandleFunc("/affiliations", s.listOrAddAffiliations)
+	http.HandleFunc("/affiliations/", s.affiliation)
+	http.HandleFunc("/cainfo", s.cainfo)
+	http.HandleFunc("/certificates", s.certificates)
+	http.HandleFunc("/gencrl", s.gencrl)
 
 	server := &http.Server{
 		Addr:      s.address,
@@ -121,14 +179,385 @@ func (s *MockFabricCAServer) register(w http.ResponseWriter, req *http.Request)
 
 // Enroll user
 func (s *MockFabricCAServer) enroll(w http.ResponseWriter, req *http.Request) {
+	enrollReq := &api.EnrollmentRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(enrollReq); err != nil {
+		logger.Warnf("Failed to decode enrollment request: %s", err)
+	}
+	s.mutex.Lock()
+	s.lastEnroll = enrollReq
+	if id, _, ok := req.BasicAuth(); ok {
+		s.enrolled[id] = true
+	}
+	s.mutex.Unlock()
+
 	s.addKeyToKeyStore([]byte(privateKey))
 	resp := &enrollmentResponseNet{Cert: util.B64Encode([]byte(ecert))}
-	fillCAInfo(&resp.ServerInfo)
+	s.fillCAInfo(&resp.ServerInfo)
+	cfapi.SendResponse(w, resp)
+}
+
+// CA info
+func (s *MockFabricCAServer) cainfo(w http.ResponseWriter, req *http.Request) {
+	resp := &serverInfoResponseNet{}
+	s.fillCAInfo(resp)
 	cfapi.SendResponse(w, resp)
 }
 
-// Fill the CA info structure appropriately
-func fillCAInfo(info *serverInfoResponseNet) {
+// List the certificates issued by the CA. Every enrollment is issued the same certificate, which is never revoked.
+func (s *MockFabricCAServer) certificates(w http.ResponseWriter, req *http.Request) {
+	if !authorized(w, req) {
+		return
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	query := req.URL.Query()
+	resp := &certificatesResponseNet{CAName: query.Get("ca")}
+	if s.certificateSelected(query) {
+		resp.Certs = append(resp.Certs, certPEM{PEM: ecert})
+	}
+	cfapi.SendResponse(w, resp)
+}
+
+func (s *MockFabricCAServer) certificateSelected(query url.Values) bool {
+	block, _ := pem.Decode([]byte(ecert))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+
+	if id := query.Get("id"); id != "" && !s.enrolled[id] {
+		return false
+	}
+	if serial := query.Get("serial"); serial != "" && !strings.EqualFold(serial, hex.EncodeToString(cert.SerialNumber.Bytes())) {
+		return false
+	}
+	if aki := query.Get("aki"); aki != "" && !strings.EqualFold(aki, hex.EncodeToString(cert.AuthorityKeyId)) {
+		return false
+	}
+	if query.Get("revoked_start") != "" || query.Get("revoked_end") != "" {
+		return false
+	}
+	if query.Get("notexpired") == "true" && time.Now().After(cert.NotAfter) {
+		return false
+	}
+	if !inTimeRange(cert.NotAfter, query.Get("expired_start"), query.Get("expired_end")) {
+		return false
+	}
+	return true
+}
+
+func inTimeRange(t time.Time, start, end string) bool {
+	if start != "" {
+		startTime, err := time.Parse(time.RFC3339, start)
+		if err != nil || t.Before(startTime) {
+			return false
+		}
+	}
+	if end != "" {
+		endTime, err := time.Parse(time.RFC3339, end)
+		if err != nil || t.After(endTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Generate an empty CRL
+func (s *MockFabricCAServer) gencrl(w http.ResponseWriter, req *http.Request) {
+	if !authorized(w, req) {
+		return
+	}
+
+	now := time.Now()
+	crl, err := s.crlCert.CreateCRL(rand.Reader, s.crlKey, nil, now, now.Add(24*time.Hour))
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	crlPEM := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+	cfapi.SendResponse(w, &genCRLResponseNet{CRL: util.B64Encode(crlPEM)})
+}
+
+// initCRLSigner creates the CA certificate and key that sign the CRLs of the server
+func (s *MockFabricCAServer) initCRLSigner() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "MockCAName"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	s.crlKey = key
+	s.crlCert = cert
+	return nil
+}
+
+// List or add identities
+func (s *MockFabricCAServer) listOrAddIdentities(w http.ResponseWriter, req *http.Request) {
+	if !authorized(w, req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch req.Method {
+	case http.MethodGet:
+		resp := &api.GetAllIDsResponse{CAName: req.URL.Query().Get("ca")}
+		for _, id := range s.identities {
+			resp.Identities = append(resp.Identities, *id)
+		}
+		cfsslapi.SendResponse(w, resp)
+	case http.MethodPost:
+		addReq := &api.AddIdentityRequestNet{}
+		if err := json.NewDecoder(req.Body).Decode(addReq); err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, ok := s.identities[addReq.ID]; ok {
+			sendError(w, http.StatusBadRequest, "Identity '"+addReq.ID+"' is already registered")
+			return
+		}
+		secret := addReq.Secret
+		if secret == "" {
+			secret = "mockSecretValue"
+		}
+		id := &api.IdentityInfo{ID: addReq.ID, Type: addReq.Type, Affiliation: addReq.Affiliation, Attributes: addReq.Attributes, MaxEnrollments: addReq.MaxEnrollments}
+		if id.Type == "" {
+			id.Type = "user"
+		}
+		s.identities[id.ID] = id
+		cfsslapi.SendResponse(w, identityResponse(id, secret, addReq.CAName))
+	default:
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Get, modify or remove an identity
+func (s *MockFabricCAServer) identity(w http.ResponseWriter, req *http.Request) {
+	if !authorized(w, req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name := strings.TrimPrefix(req.URL.Path, "/identities/")
+	id, ok := s.identities[name]
+	if !ok {
+		sendError(w, http.StatusNotFound, "Identity '"+name+"' not found")
+		return
+	}
+	caName := req.URL.Query().Get("ca")
+
+	switch req.Method {
+	case http.MethodGet:
+		cfsslapi.SendResponse(w, &api.GetIDResponse{ID: id.ID, Type: id.Type, Affiliation: id.Affiliation, Attributes: id.Attributes, MaxEnrollments: id.MaxEnrollments, CAName: caName})
+	case http.MethodPut:
+		modifyReq := &api.ModifyIdentityRequestNet{}
+		if err := json.NewDecoder(req.Body).Decode(modifyReq); err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if modifyReq.Type != "" {
+			id.Type = modifyReq.Type
+		}
+		if modifyReq.Affiliation != "" {
+			id.Affiliation = modifyReq.Affiliation
+		}
+		if modifyReq.Attributes != nil {
+			id.Attributes = modifyReq.Attributes
+		}
+		if modifyReq.MaxEnrollments != 0 {
+			id.MaxEnrollments = modifyReq.MaxEnrollments
+		}
+		cfsslapi.SendResponse(w, identityResponse(id, modifyReq.Secret, caName))
+	case http.MethodDelete:
+		delete(s.identities, name)
+		cfsslapi.SendResponse(w, identityResponse(id, "", caName))
+	default:
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// List or add affiliations
+func (s *MockFabricCAServer) listOrAddAffiliations(w http.ResponseWriter, req *http.Request) {
+	if !authorized(w, req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	caName := req.URL.Query().Get("ca")
+
+	switch req.Method {
+	case http.MethodGet:
+		cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: s.affiliationInfo(""), CAName: caName})
+	case http.MethodPost:
+		addReq := &api.AddAffiliationRequestNet{}
+		if err := json.NewDecoder(req.Body).Decode(addReq); err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if s.affiliations[addReq.Name] {
+			sendError(w, http.StatusBadRequest, "Affiliation '"+addReq.Name+"' already exists")
+			return
+		}
+		force := req.URL.Query().Get("force") == "true"
+		for parent := parentAffiliation(addReq.Name); parent != ""; parent = parentAffiliation(parent) {
+			if !s.affiliations[parent] && !force {
+				sendError(w, http.StatusBadRequest, "Parent affiliation '"+parent+"' does not exist")
+				return
+			}
+			s.affiliations[parent] = true
+		}
+		s.affiliations[addReq.Name] = true
+		cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{Name: addReq.Name}, CAName: caName})
+	default:
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Get, rename or remove an affiliation
+func (s *MockFabricCAServer) affiliation(w http.ResponseWriter, req *http.Request) {
+	if !authorized(w, req) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name := strings.TrimPrefix(req.URL.Path, "/affiliations/")
+	if !s.affiliations[name] {
+		sendError(w, http.StatusNotFound, "Affiliation '"+name+"' not found")
+		return
+	}
+	caName := req.URL.Query().Get("ca")
+	force := req.URL.Query().Get("force") == "true"
+
+	switch req.Method {
+	case http.MethodGet:
+		cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: s.affiliationInfo(name), CAName: caName})
+	case http.MethodPut:
+		modifyReq := &api.ModifyAffiliationRequestNet{}
+		if err := json.NewDecoder(req.Body).Decode(modifyReq); err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		newName := modifyReq.NewName
+		info := s.affiliationInfo(name)
+		if len(info.Identities) > 0 && !force {
+			sendError(w, http.StatusBadRequest, "Affiliation '"+name+"' has identities, force is required to rename it")
+			return
+		}
+		var renamed []string
+		for aff := range s.affiliations {
+			if aff == name || strings.HasPrefix(aff, name+".") {
+				renamed = append(renamed, aff)
+			}
+		}
+		for _, aff := range renamed {
+			delete(s.affiliations, aff)
+			s.affiliations[newName+strings.TrimPrefix(aff, name)] = true
+		}
+		for _, id := range s.identities {
+			if id.Affiliation == name || strings.HasPrefix(id.Affiliation, name+".") {
+				id.Affiliation = newName + strings.TrimPrefix(id.Affiliation, name)
+			}
+		}
+		cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: s.affiliationInfo(newName), CAName: caName})
+	case http.MethodDelete:
+		info := s.affiliationInfo(name)
+		if (len(info.Affiliations) > 0 || len(info.Identities) > 0) && !force {
+			sendError(w, http.StatusBadRequest, "Affiliation '"+name+"' has child affiliations or identities, force is required to remove it")
+			return
+		}
+		for aff := range s.affiliations {
+			if aff == name || strings.HasPrefix(aff, name+".") {
+				delete(s.affiliations, aff)
+			}
+		}
+		for id, identity := range s.identities {
+			if identity.Affiliation == name || strings.HasPrefix(identity.Affiliation, name+".") {
+				delete(s.identities, id)
+			}
+		}
+		cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: info, CAName: caName})
+	default:
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// affiliationInfo returns the affiliation tree rooted at the given affiliation
+func (s *MockFabricCAServer) affiliationInfo(name string) api.AffiliationInfo {
+	info := api.AffiliationInfo{Name: name}
+
+	var children []string
+	for aff := range s.affiliations {
+		if parentAffiliation(aff) == name && aff != name {
+			children = append(children, aff)
+		}
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		info.Affiliations = append(info.Affiliations, s.affiliationInfo(child))
+	}
+
+	for _, id := range s.identities {
+		if id.Affiliation == name {
+			info.Identities = append(info.Identities, *id)
+		}
+	}
+	return info
+}
+
+func parentAffiliation(name string) string {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return ""
+	}
+	return name[:i]
+}
+
+func identityResponse(id *api.IdentityInfo, secret, caName string) *api.IdentityResponse {
+	return &api.IdentityResponse{ID: id.ID, Type: id.Type, Affiliation: id.Affiliation, Attributes: id.Attributes, MaxEnrollments: id.MaxEnrollments, Secret: secret, CAName: caName}
+}
+
+// authorized checks that the request carries a token authorization header
+func authorized(w http.ResponseWriter, req *http.Request) bool {
+	if req.Header.Get("authorization") == "" {
+		sendError(w, http.StatusUnauthorized, "Authorization failure")
+		return false
+	}
+	return true
+}
+
+func sendError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(cfsslapi.NewErrorResponse(message, status))
+}
+
+// Fill the CA info structure appropriately; the CA chain is the certificate that signs the CRLs
+func (s *MockFabricCAServer) fillCAInfo(info *serverInfoResponseNet) {
 	info.CAName = "MockCAName"
-	info.CAChain = util.B64Encode([]byte("MockCAChain"))
+	info.CAChain = util.B64Encode(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.crlCert.Raw}))
+	info.Version = "MockVersion"
 }
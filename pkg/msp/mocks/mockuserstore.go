@@ -23,3 +23,8 @@ func (m *MockUserStore) Store(*msp.UserData) error {
 func (m *MockUserStore) Load(msp.UserIdentifier) (*msp.UserData, error) {
 	return &msp.UserData{}, nil
 }
+
+// Delete ...
+func (m *MockUserStore) Delete(msp.UserIdentifier) error {
+	return nil
+}
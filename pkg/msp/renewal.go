@@ -0,0 +1,314 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRenewalWindow is the default time before expiry of an enrollment certificate at which the user is re-enrolled
+	DefaultRenewalWindow = 7 * 24 * time.Hour
+	// DefaultRenewalCheckInterval is the default interval at which the enrollment certificates are checked
+	DefaultRenewalCheckInterval = time.Hour
+)
+
+// NotificationType is the type of a renewal notification
+type NotificationType int
+
+const (
+	// Renewed the user was re-enrolled and has a new enrollment certificate
+	Renewed NotificationType = iota
+	// RenewalFailed the re-enrollment of the user failed; it is retried at the next check
+	RenewalFailed
+	// Expired the enrollment certificate of the user has expired; the user cannot be re-enrolled
+	// and has to be enrolled again
+	Expired
+)
+
+func (t NotificationType) String() string {
+	switch t {
+	case Renewed:
+		return "RENEWED"
+	case RenewalFailed:
+		return "RENEWAL_FAILED"
+	case Expired:
+		return "EXPIRED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Notification is emitted by the RenewalService for each user whose enrollment certificate
+// is renewed, fails to be renewed or has expired
+type Notification struct {
+	Type     NotificationType
+	MspID    string
+	UserName string
+	// NotAfter is the expiry time of the enrollment certificate; after a renewal, of the new certificate
+	NotAfter time.Time
+	// Err is the reason of a failed renewal
+	Err error
+}
+
+// NotificationHandler handles renewal notifications
+type NotificationHandler func(Notification)
+
+// Clock provides the current time to the RenewalService
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Reenroller re-enrolls users, see api.CAClient
+type Reenroller interface {
+	Reenroll(enrollmentID string, opts ...api.EnrollmentOption) error
+}
+
+// RenewalService re-enrolls the users of an organization whose enrollment certificates are about to expire.
+// The enrollment certificates of all users of the organization in the user store are checked periodically,
+// and users are re-enrolled once their certificate expires within the renewal window. The new certificate
+// and private key are swapped into the users returned by the identity manager, so that existing contexts
+// of a user sign with the new identity. Users are re-enrolled with the CA that issued their enrollment
+// certificate, as recorded in their metadata (see api.CANameMetadataKey).
+type RenewalService struct {
+	identityManager *IdentityManager
+	reenroller      Reenroller
+	userStore       msp.UserLister
+	window          time.Duration
+	interval        time.Duration
+	clock           Clock
+	handler         NotificationHandler
+
+	checkMutex sync.Mutex
+	expired    map[msp.UserIdentifier]time.Time
+
+	mutex sync.Mutex
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// RenewalOption configures the RenewalService
+type RenewalOption func(*RenewalService) error
+
+// WithRenewalWindow sets the time before expiry of an enrollment certificate at which the user is re-enrolled
+func WithRenewalWindow(window time.Duration) RenewalOption {
+	return func(s *RenewalService) error {
+		if window <= 0 {
+			return errors.New("renewal window must be positive")
+		}
+		s.window = window
+		return nil
+	}
+}
+
+// WithCheckInterval sets the interval at which the enrollment certificates are checked
+func WithCheckInterval(interval time.Duration) RenewalOption {
+	return func(s *RenewalService) error {
+		if interval <= 0 {
+			return errors.New("check interval must be positive")
+		}
+		s.interval = interval
+		return nil
+	}
+}
+
+// WithClock sets the clock used to decide whether an enrollment certificate is about to expire
+func WithClock(clock Clock) RenewalOption {
+	return func(s *RenewalService) error {
+		if clock == nil {
+			return errors.New("clock is nil")
+		}
+		s.clock = clock
+		return nil
+	}
+}
+
+// WithNotificationHandler sets the handler that receives the renewal notifications
+func WithNotificationHandler(handler NotificationHandler) RenewalOption {
+	return func(s *RenewalService) error {
+		s.handler = handler
+		return nil
+	}
+}
+
+// NewRenewalService creates a service that re-enrolls the users of the organization of the identity manager
+// using the given re-enroller, usually the CA client of the organization. The user store must be able to list its users.
+func NewRenewalService(identityManager *IdentityManager, reenroller Reenroller, opts ...RenewalOption) (*RenewalService, error) {
+	if identityManager == nil {
+		return nil, errors.New("identity manager is required")
+	}
+	if reenroller == nil {
+		return nil, errors.New("re-enroller is required")
+	}
+	userStore, ok := identityManager.userStore.(msp.UserLister)
+	if !ok {
+		return nil, errors.New("user store does not support listing users")
+	}
+
+	s := &RenewalService{
+		identityManager: identityManager,
+		reenroller:      reenroller,
+		userStore:       userStore,
+		window:          DefaultRenewalWindow,
+		interval:        DefaultRenewalCheckInterval,
+		clock:           systemClock{},
+		expired:         make(map[msp.UserIdentifier]time.Time),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, errors.WithMessage(err, "failed to create renewal service")
+		}
+	}
+	return s, nil
+}
+
+// Start checks the enrollment certificates at the configured interval until Stop is called
+func (s *RenewalService) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+}
+
+// Stop stops checking the enrollment certificates and waits for a running check to complete
+func (s *RenewalService) Stop() {
+	s.mutex.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (s *RenewalService) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Check(); err != nil {
+			logger.Warnf("Checking enrollment certificates failed: %s", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Check checks the enrollment certificates of all users of the organization once and
+// re-enrolls the users whose certificate expires within the renewal window
+func (s *RenewalService) Check() error {
+	s.checkMutex.Lock()
+	defer s.checkMutex.Unlock()
+
+	users, err := s.userStore.List()
+	if err != nil {
+		return errors.WithMessage(err, "failed to list users")
+	}
+
+	for _, user := range users {
+		if user.MspID != s.identityManager.orgMspID {
+			continue
+		}
+		notAfter, err := certificateNotAfter(user.EnrollmentCertificate)
+		if err != nil {
+			logger.Warnf("Skipping enrollment certificate of user [%s]: %s", user.Name, err)
+			continue
+		}
+		s.checkUser(user, notAfter)
+	}
+	return nil
+}
+
+func (s *RenewalService) checkUser(user *msp.UserData, notAfter time.Time) {
+	now := s.clock.Now()
+	if now.Add(s.window).Before(notAfter) {
+		return
+	}
+
+	id := msp.UserIdentifier{MspID: user.MspID, Name: user.Name}
+	if !now.Before(notAfter) {
+		// Notify only once for each expired certificate
+		if s.expired[id].Equal(notAfter) {
+			return
+		}
+		s.expired[id] = notAfter
+		logger.Warnf("Enrollment certificate of user [%s] expired at %s", user.Name, notAfter)
+		s.notify(Notification{Type: Expired, MspID: user.MspID, UserName: user.Name, NotAfter: notAfter})
+		return
+	}
+	delete(s.expired, id)
+
+	logger.Infof("Re-enrolling user [%s], enrollment certificate expires at %s", user.Name, notAfter)
+	newNotAfter, err := s.renew(user)
+	if err != nil {
+		logger.Warnf("Re-enrolling user [%s] failed: %s", user.Name, err)
+		s.notify(Notification{Type: RenewalFailed, MspID: user.MspID, UserName: user.Name, NotAfter: notAfter, Err: err})
+		return
+	}
+	s.notify(Notification{Type: Renewed, MspID: user.MspID, UserName: user.Name, NotAfter: newNotAfter})
+}
+
+// renew re-enrolls the user with the CA that issued its enrollment certificate, if recorded in its metadata
+func (s *RenewalService) renew(user *msp.UserData) (time.Time, error) {
+	var opts []api.EnrollmentOption
+	if caName := user.Metadata[api.CANameMetadataKey]; caName != "" {
+		opts = append(opts, api.WithCAName(caName))
+	}
+	if err := s.reenroller.Reenroll(user.Name, opts...); err != nil {
+		return time.Time{}, err
+	}
+	// Loading the user swaps the new certificate and key into the user shared by existing contexts
+	renewed, err := s.identityManager.GetUser(user.Name)
+	if err != nil {
+		return time.Time{}, errors.WithMessage(err, "failed to load re-enrolled user")
+	}
+	return certificateNotAfter(renewed.EnrollmentCertificate())
+}
+
+func (s *RenewalService) notify(n Notification) {
+	if s.handler != nil {
+		s.handler(n)
+	}
+}
+
+func certificateNotAfter(certPEM []byte) (time.Time, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return time.Time{}, errors.New("failed to decode enrollment certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse enrollment certificate")
+	}
+	return cert.NotAfter, nil
+}
@@ -0,0 +1,265 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// testReenroller issues a new certificate, valid for a year, to re-enrolled users
+type testReenroller struct {
+	t          *testing.T
+	f          *textFixture
+	userStore  msp.UserStore
+	mspID      string
+	notBefore  time.Time
+	err        error
+	reenrolled []string
+	caNames    []string
+}
+
+func (r *testReenroller) Reenroll(enrollmentID string, opts ...api.EnrollmentOption) error {
+	if r.err != nil {
+		return r.err
+	}
+	options, err := enrollmentOptions(opts)
+	if err != nil {
+		return err
+	}
+	r.reenrolled = append(r.reenrolled, enrollmentID)
+	r.caNames = append(r.caNames, options.CAName)
+	return r.userStore.Store(&msp.UserData{
+		MspID: r.mspID,
+		Name:  enrollmentID,
+		EnrollmentCertificate: newTestEnrollmentCert(r.t, r.f, enrollmentID, r.notBefore.Add(365*24*time.Hour)),
+	})
+}
+
+func TestRenewalService(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	orgMspID := mspIDByOrgName(t, f.config, org1)
+	userStore := NewMemoryUserStore()
+	identityManager, err := NewIdentityManager("org1", userStore, f.cryptoSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewIdentityManager failed: %v", err)
+	}
+
+	clock := &testClock{now: time.Now()}
+	storeTestUser(t, &f, userStore, orgMspID, "expiring", clock.now.Add(time.Hour))
+	expiringUser, err := userStore.Load(msp.UserIdentifier{MspID: orgMspID, Name: "expiring"})
+	if err != nil {
+		t.Fatalf("Loading user failed: %v", err)
+	}
+	expiringUser.Metadata = map[string]string{api.CANameMetadataKey: "ica.org1.example.com"}
+	if err := userStore.Store(expiringUser); err != nil {
+		t.Fatalf("Storing user failed: %v", err)
+	}
+	storeTestUser(t, &f, userStore, orgMspID, "valid", clock.now.Add(48*time.Hour))
+	storeTestUser(t, &f, userStore, orgMspID, "expired", clock.now.Add(-time.Hour))
+	storeTestUser(t, &f, userStore, "OtherMSP", "other", clock.now.Add(time.Hour))
+
+	liveUser, err := identityManager.GetUser("expiring")
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	oldCert := liveUser.EnrollmentCertificate()
+	oldSKI := liveUser.PrivateKey().SKI()
+
+	reenroller := &testReenroller{t: t, f: &f, userStore: userStore, mspID: orgMspID, notBefore: clock.now}
+	var notifications []Notification
+	service, err := NewRenewalService(identityManager, reenroller,
+		WithRenewalWindow(24*time.Hour),
+		WithClock(clock),
+		WithNotificationHandler(func(n Notification) { notifications = append(notifications, n) }))
+	if err != nil {
+		t.Fatalf("NewRenewalService failed: %v", err)
+	}
+
+	if err := service.Check(); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(reenroller.reenrolled) != 1 || reenroller.reenrolled[0] != "expiring" {
+		t.Fatalf("Expected only the expiring user to be re-enrolled, got %v", reenroller.reenrolled)
+	}
+	if reenroller.caNames[0] != "ica.org1.example.com" {
+		t.Fatalf("Expected re-enrollment with the CA that issued the certificate, got '%s'", reenroller.caNames[0])
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("Expected 2 notifications, got %v", notifications)
+	}
+	for _, n := range notifications {
+		switch n.UserName {
+		case "expiring":
+			if n.Type != Renewed || !n.NotAfter.After(clock.now.Add(24*time.Hour)) {
+				t.Fatalf("Unexpected notification for re-enrolled user: %+v", n)
+			}
+		case "expired":
+			if n.Type != Expired {
+				t.Fatalf("Unexpected notification for expired user: %+v", n)
+			}
+		default:
+			t.Fatalf("Unexpected notification: %+v", n)
+		}
+	}
+
+	// The new certificate and key are swapped into the existing user
+	if bytes.Equal(liveUser.EnrollmentCertificate(), oldCert) {
+		t.Fatal("Expected the enrollment certificate of the user to be renewed")
+	}
+	if bytes.Equal(liveUser.PrivateKey().SKI(), oldSKI) {
+		t.Fatal("Expected the private key of the user to be renewed")
+	}
+	user, err := identityManager.GetUser("expiring")
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	if user != liveUser {
+		t.Fatal("Expected the identity manager to return the renewed user")
+	}
+
+	// Expired users are notified once; the renewed user is not re-enrolled again
+	notifications = nil
+	if err := service.Check(); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(notifications) != 0 || len(reenroller.reenrolled) != 1 {
+		t.Fatalf("Expected no notifications and re-enrollments, got %v", notifications)
+	}
+
+	// The valid user is re-enrolled once it expires within the window
+	clock.now = clock.now.Add(25 * time.Hour)
+	reenroller.err = errors.New("CA unavailable")
+	if err := service.Check(); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Type != RenewalFailed || notifications[0].UserName != "valid" || notifications[0].Err != reenroller.err {
+		t.Fatalf("Expected renewal failure of valid user, got %v", notifications)
+	}
+}
+
+func TestRenewalServiceStart(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	orgMspID := mspIDByOrgName(t, f.config, org1)
+	userStore := NewMemoryUserStore()
+	identityManager, err := NewIdentityManager("org1", userStore, f.cryptoSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewIdentityManager failed: %v", err)
+	}
+	storeTestUser(t, &f, userStore, orgMspID, "expiring", time.Now().Add(time.Hour))
+
+	reenroller := &testReenroller{t: t, f: &f, userStore: userStore, mspID: orgMspID, notBefore: time.Now()}
+	notifications := make(chan Notification, 10)
+	service, err := NewRenewalService(identityManager, reenroller,
+		WithCheckInterval(10*time.Millisecond),
+		WithNotificationHandler(func(n Notification) { notifications <- n }))
+	if err != nil {
+		t.Fatalf("NewRenewalService failed: %v", err)
+	}
+
+	service.Start()
+	defer service.Stop()
+
+	select {
+	case n := <-notifications:
+		if n.Type != Renewed || n.UserName != "expiring" {
+			t.Fatalf("Unexpected notification: %+v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for renewal")
+	}
+}
+
+func TestNewRenewalServiceErrors(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	if _, err := NewRenewalService(f.identityManager, nil); err == nil {
+		t.Fatal("Expected error without re-enroller")
+	}
+	if _, err := NewRenewalService(f.identityManager, &testReenroller{}, WithRenewalWindow(0)); err == nil {
+		t.Fatal("Expected error with invalid renewal window")
+	}
+	if _, err := NewRenewalService(f.identityManager, &testReenroller{}, WithClock(nil)); err == nil {
+		t.Fatal("Expected error without clock")
+	}
+}
+
+func storeTestUser(t *testing.T, f *textFixture, userStore msp.UserStore, mspID string, name string, notAfter time.Time) {
+	err := userStore.Store(&msp.UserData{
+		MspID: mspID,
+		Name:  name,
+		EnrollmentCertificate: newTestEnrollmentCert(t, f, name, notAfter),
+	})
+	if err != nil {
+		t.Fatalf("Storing user %s failed: %v", name, err)
+	}
+}
+
+// newTestEnrollmentCert returns a PEM certificate expiring at the given time, for a new key in the crypto suite
+func newTestEnrollmentCert(t *testing.T, f *textFixture, name string, notAfter time.Time) []byte {
+	key, err := f.cryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(false))
+	if err != nil {
+		t.Fatalf("KeyGen failed: %v", err)
+	}
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey failed: %v", err)
+	}
+	pubKeyBytes, err := pubKey.Bytes()
+	if err != nil {
+		t.Fatalf("PublicKey Bytes failed: %v", err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(pubKeyBytes)
+	if err != nil {
+		t.Fatalf("ParsePKIXPublicKey failed: %v", err)
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
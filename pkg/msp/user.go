@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"sync"
+
 	"github.com/golang/protobuf/proto"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
@@ -15,10 +17,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-// User is a representation of a Fabric user
+// User is a representation of a Fabric user.
+// The enrollment certificate and private key of a user are replaced when the user is re-enrolled.
 type User struct {
 	mspID                 string
 	name                  string
+	mutex                 sync.RWMutex
 	enrollmentCertificate []byte
 	privateKey            core.Key
 }
@@ -35,19 +39,47 @@ func (u *User) Name() string {
 
 // EnrollmentCertificate Returns the underlying ECert representing this user’s identity.
 func (u *User) EnrollmentCertificate() []byte {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
 	return u.enrollmentCertificate
 }
 
 // PrivateKey returns the crypto suite representation of the private key
 func (u *User) PrivateKey() core.Key {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
 	return u.privateKey
 }
 
+// Enrollment returns the enrollment certificate and the private key of the user.
+// The certificate and key are read together so that they match if the user is re-enrolled concurrently.
+func (u *User) Enrollment() ([]byte, core.Key) {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return u.enrollmentCertificate, u.privateKey
+}
+
+// renew replaces the enrollment certificate and private key of the user
+func (u *User) renew(enrollmentCertificate []byte, privateKey core.Key) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.enrollmentCertificate = enrollmentCertificate
+	u.privateKey = privateKey
+}
+
 // MspID returns the MSP for this user
 func (u *User) MspID() string {
 	return u.mspID
 }
 
+// enrollment returns the matching enrollment certificate and private key of the user
+func enrollment(user msp.User) ([]byte, core.Key) {
+	if u, ok := user.(*User); ok {
+		return u.Enrollment()
+	}
+	return user.EnrollmentCertificate(), user.PrivateKey()
+}
+
 // SerializedIdentity returns client's serialized identity
 func (u *User) SerializedIdentity() ([]byte, error) {
 	serializedIdentity := &pb_msp.SerializedIdentity{Mspid: u.MspID(),
@@ -79,6 +79,10 @@ func TestUserMethods(t *testing.T) {
 	// Check PrivateKey
 	verifyBytes(t, user.PrivateKey().SKI(), generatedKey.SKI())
 
+	// Check Enrollment
+	cert, key := user.Enrollment()
+	verifyBytes(t, cert, generatedCertBytes)
+	verifyBytes(t, key.SKI(), generatedKey.SKI())
 }
 
 func verifyBytes(t *testing.T, v interface{}, expected []byte) error {
@@ -28,6 +28,7 @@ declare -a PKGS=(
 declare -a FILES=(
     "api/client.go"
     "api/net.go"
+    "api/sdkpatch_certificates.go"
 
     "lib/client.go"
     "lib/identity.go"
@@ -36,6 +37,7 @@ declare -a FILES=(
     "lib/util.go"
     "lib/serverrevoke.go"
     "lib/sdkpatch_serverstruct.go"
+    "lib/sdkpatch_certificates.go"
 
     "lib/tls/tls.go"
 
@@ -73,6 +75,7 @@ FILTERS_ENABLED="fn"
 FILTER_FILENAME="lib/client.go"
 FILTER_FN="Enroll,GenCSR,SendReq,Init,newPost,newEnrollmentResponse,newCertificateRequest"
 FILTER_FN+=",getURL,NormalizeURL,initHTTPClient,net2LocalServerInfo,NewIdentity,newCfsslBasicKeyRequest"
+FILTER_FN+=",newGet,newPut,newDelete,GetCAInfo"
 gofilter
 sed -i'' -e 's/util.GetServerPort()/\"\"/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 sed -i'' -e '/log "github.com\// a\
@@ -86,9 +89,16 @@ for i in {1..4}
 do
     sed -i'' -e ${START_LINE}'d' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 done
+sed -i'' -e '/c.httpClient = &http.Client{Transport: tr}/ i\
+if c.Config.Proxy != nil {\
+tr.Proxy = http.ProxyURL(c.Config.Proxy)\
+}\
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 
 FILTER_FILENAME="lib/identity.go"
 FILTER_FN="newIdentity,Revoke,Post,addTokenAuthHdr,GetECert,Reenroll,Register,GetName"
+FILTER_FN+=",GetIdentity,GetAllIdentities,AddIdentity,ModifyIdentity,RemoveIdentity,Get,Put,Delete"
+FILTER_FN+=",GetAffiliation,GetAllAffiliations,AddAffiliation,ModifyAffiliation,RemoveAffiliation,GenCRL"
 gofilter
 sed -i'' -e 's/util.GetDefaultBCCSP()/nil/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 sed -i'' -e '/log "github.com\// a\
@@ -109,6 +119,13 @@ FILTER_FILENAME="lib/clientconfig.go"
 FILTER_FN=
 gofilter
 sed -i'' -e 's/*factory.FactoryOpts/core.CryptoSuite/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+grep -q '"net/url"' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}" || sed -i'' -e '/^import (/ a\
+"net\/url"\
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/CSP .*core.CryptoSuite/ a\
+\/\/ Proxy is the URL of the proxy of the connections to the server, nil for direct connections\
+Proxy *url.URL\
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 
 
 FILTER_FILENAME="lib/util.go"
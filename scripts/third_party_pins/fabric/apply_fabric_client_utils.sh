@@ -84,6 +84,7 @@ declare -a FILES=(
     "bccsp/sw/keyimport.go"
     "bccsp/sw/rsa.go"
     "bccsp/sw/rsakey.go"
+    "bccsp/sw/sdkpatch_keyexport.go"
 
     "bccsp/utils/errs.go"
     "bccsp/utils/io.go"
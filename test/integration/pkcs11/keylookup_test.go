@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/pkcs11"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context/api/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	cryptosuite "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/pkcs11"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+)
+
+const concurrentSignatures = 50
+
+var oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+func TestKeyLookupAndConcurrentSigning(t *testing.T) {
+	cfg, err := config.FromFile("../" + ConfigTestFile)()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cs, err := cryptosuite.GetSuiteByConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to get PKCS11 cryptosuite: %v", err)
+	}
+	suite := cs.(*cryptosuite.CryptoSuite)
+	defer suite.Close()
+
+	lib, pin, label := pkcs11.FindPKCS11Lib()
+	pool, err := cryptosuite.NewSessionPool(lib, label, pin, &core.PKCS11Config{SessionPoolSize: 1})
+	if err != nil {
+		t.Fatalf("Failed to create session pool: %v", err)
+	}
+	defer pool.Close()
+
+	keyID := make([]byte, 16)
+	if _, err := rand.Read(keyID); err != nil {
+		t.Fatalf("Failed to generate key identifier: %v", err)
+	}
+	keyLabel := "integration-key-" + hex.EncodeToString(keyID)
+	destroy := generateKeyPair(t, pool, keyLabel, keyID)
+	defer destroy()
+
+	byLabel, err := suite.GetKeyByLabel(keyLabel)
+	if err != nil {
+		t.Fatalf("Failed to get key by label: %v", err)
+	}
+	byID, err := suite.GetKeyByID(keyID)
+	if err != nil {
+		t.Fatalf("Failed to get key by identifier: %v", err)
+	}
+	assert.Equal(t, byLabel.SKI(), byID.SKI(), "expected the same key by label and by identifier")
+	assert.True(t, byLabel.Private())
+	_, err = suite.GetKeyByLabel("unknown-" + keyLabel)
+	assert.Error(t, err, "expected error for unknown label")
+
+	// Sign with more goroutines than sessions in the pool
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentSignatures)
+	for i := 0; i < concurrentSignatures; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			digest := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+			signature, err := suite.Sign(byLabel, digest[:], nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			valid, err := suite.Verify(byLabel, signature, digest[:], nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !valid {
+				errs <- fmt.Errorf("invalid signature of message %d", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent signing failed: %v", err)
+	}
+
+	assert.NoError(t, suite.HealthCheck())
+}
+
+// generateKeyPair generates an ECDSA P-256 key pair on the token and returns a function destroying it
+func generateKeyPair(t *testing.T, pool *cryptosuite.SessionPool, label string, id []byte) func() {
+	params, err := asn1.Marshal(oidNamedCurveP256)
+	if err != nil {
+		t.Fatalf("Failed to marshal curve: %v", err)
+	}
+	var pubHandle, privHandle p11.ObjectHandle
+	err = pool.Do(func(ctx *p11.Ctx, session p11.SessionHandle) error {
+		var err error
+		pubHandle, privHandle, err = ctx.GenerateKeyPair(session,
+			[]*p11.Mechanism{p11.NewMechanism(p11.CKM_EC_KEY_PAIR_GEN, nil)},
+			[]*p11.Attribute{
+				p11.NewAttribute(p11.CKA_TOKEN, true),
+				p11.NewAttribute(p11.CKA_VERIFY, true),
+				p11.NewAttribute(p11.CKA_EC_PARAMS, params),
+				p11.NewAttribute(p11.CKA_LABEL, label),
+				p11.NewAttribute(p11.CKA_ID, id),
+			},
+			[]*p11.Attribute{
+				p11.NewAttribute(p11.CKA_TOKEN, true),
+				p11.NewAttribute(p11.CKA_PRIVATE, true),
+				p11.NewAttribute(p11.CKA_SENSITIVE, true),
+				p11.NewAttribute(p11.CKA_SIGN, true),
+				p11.NewAttribute(p11.CKA_LABEL, label),
+				p11.NewAttribute(p11.CKA_ID, id),
+			})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+
+	return func() {
+		err := pool.Do(func(ctx *p11.Ctx, session p11.SessionHandle) error {
+			if err := ctx.DestroyObject(session, privHandle); err != nil {
+				return err
+			}
+			return ctx.DestroyObject(session, pubHandle)
+		})
+		if err != nil {
+			t.Logf("Failed to destroy key pair: %v", err)
+		}
+	}
+}